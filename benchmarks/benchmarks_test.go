@@ -6,6 +6,7 @@ import (
 	"spn-benchmark-ds/internal/pkg/augmentation"
 	"spn-benchmark-ds/internal/pkg/generation"
 	"spn-benchmark-ds/internal/pkg/petrinet"
+	"spn-benchmark-ds/internal/pkg/rates"
 	"testing"
 )
 
@@ -65,44 +66,64 @@ func BenchmarkAnalysis_Medium(b *testing.B) {
 	}
 }
 
+func BenchmarkAnalysis_GaussSeidel_Medium(b *testing.B) {
+	pn := generateTestPetriNet(20, 20)
+	rg := generateTestReachabilityGraph(pn)
+	lambdas := generateRandomLambdaValues(20)
+	opts := analysis.SolverOptions{Method: analysis.MethodGaussSeidel}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		steadyStateProbs, _, err := analysis.SolveSteadyState(rg, lambdas, opts)
+		if err != nil {
+			continue
+		}
+		_, _ = analysis.ComputeAverageMarkings(rg, steadyStateProbs)
+	}
+}
+
 func BenchmarkAugmentation_PetriNet_Small(b *testing.B) {
 	pn := generateTestPetriNet(5, 5)
+	rng := rand.New(rand.NewSource(42))
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = augmentation.GeneratePetriNetVariations(pn, 10, 1, 1000, 5, 1, 10)
+		_ = augmentation.GeneratePetriNetVariations(rng, pn, 10, 1, 1000, 5, rates.Sampler{Min: 1, Max: 10}, analysis.Options{})
 	}
 }
 
 func BenchmarkAugmentation_PetriNet_Medium(b *testing.B) {
 	pn := generateTestPetriNet(15, 15) // Slightly smaller than medium to keep tests fast
+	rng := rand.New(rand.NewSource(42))
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = augmentation.GeneratePetriNetVariations(pn, 10, 1, 1000, 5, 1, 10)
+		_ = augmentation.GeneratePetriNetVariations(rng, pn, 10, 1, 1000, 5, rates.Sampler{Min: 1, Max: 10}, analysis.Options{})
 	}
 }
 
 func BenchmarkAugmentation_Lambda_Medium(b *testing.B) {
 	pn := generateTestPetriNet(20, 20)
 	rg := generateTestReachabilityGraph(pn)
+	rng := rand.New(rand.NewSource(42))
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = augmentation.GenerateLambdaVariations(pn, rg, 5, 1, 10)
+		_, _ = augmentation.GenerateLambdaVariations(rng, pn, rg, 5, rates.Sampler{Min: 1, Max: 10}, analysis.Options{})
 	}
 }
 
 func BenchmarkWholeProgram_Pipeline(b *testing.B) {
 	// Re-implements the core inner loop of runRandomGeneration
 	// to avoid I/O bottlenecks and benchmark pure execution time
+	rng := rand.New(rand.NewSource(42))
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()
 		// Initial setup per sample
-		pn := petrinet.GenerateRandomPetriNet(10, 10)
-		pn.Prune()
-		pn.AddTokensRandomly()
+		pn := petrinet.GenerateRandomPetriNet(rng, 10, 10)
+		pn.Prune(rng)
+		pn.AddTokensRandomly(rng)
 		b.StartTimer()
 
 		rg, err := generation.GenerateReachabilityGraph(pn, 10, 1000)
@@ -112,7 +133,7 @@ func BenchmarkWholeProgram_Pipeline(b *testing.B) {
 
 		lambdaValues := make([]float64, pn.Transitions)
 		for j := range lambdaValues {
-			lambdaValues[j] = float64(1 + rand.Intn(10))
+			lambdaValues[j] = float64(1 + rng.Intn(10))
 		}
 
 		stateMatrix, targetVector := analysis.ComputeStateEquation(rg, lambdaValues)
@@ -124,6 +145,6 @@ func BenchmarkWholeProgram_Pipeline(b *testing.B) {
 		_, _ = analysis.ComputeAverageMarkings(rg, steadyStateProbs)
 
 		// Include simple transformation step like runRandomGeneration when EnableTransformations=true
-		_ = augmentation.GeneratePetriNetVariations(pn, 10, 1, 1000, 3, 1, 10)
+		_ = augmentation.GeneratePetriNetVariations(rng, pn, 10, 1, 1000, 3, rates.Sampler{Min: 1, Max: 10}, analysis.Options{})
 	}
 }
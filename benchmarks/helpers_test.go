@@ -40,10 +40,10 @@ func generateTestReachabilityGraph(pn *petrinet.PetriNet) *generation.Reachabili
 
 // generateRandomLambdaValues generates predictable lambda values.
 func generateRandomLambdaValues(transitions int) []float64 {
-	rand.Seed(42) // Fixed seed for reproducibility
+	rng := rand.New(rand.NewSource(42)) // Fixed seed for reproducibility
 	lambdas := make([]float64, transitions)
 	for i := 0; i < transitions; i++ {
-		lambdas[i] = float64(1 + rand.Intn(10))
+		lambdas[i] = float64(1 + rng.Intn(10))
 	}
 	return lambdas
 }
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"spn-benchmark-ds/internal/pkg/analysis"
+	"spn-benchmark-ds/internal/pkg/generation"
+	"spn-benchmark-ds/internal/pkg/graphdata"
+	"spn-benchmark-ds/internal/pkg/pnml"
+	"spn-benchmark-ds/internal/pkg/reward"
+	"spn-benchmark-ds/internal/pkg/simulation"
+)
+
+// analyzeUsage describes the analyze subcommand.
+const analyzeUsage = `Usage: spn-benchmark-ds analyze [flags] net.pnml
+
+Runs the reachability, steady-state and transient analysis on a PNML net and writes one sample record in the
+configured output format. Transition rates are read from the PNML file and default to 1.
+
+Flags:
+`
+
+// runAnalyzeCommand parses the arguments of the analyze subcommand and runs it.
+func runAnalyzeCommand(args []string) error {
+	flags := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	configPath := flags.String("config", "config.yaml", "Path to the configuration file")
+	outputPath := flags.String("output", "", "Path to the output file (default standard output)")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), analyzeUsage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("analyze expects exactly one PNML file")
+	}
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	// Check the whole configuration before reading the net, since the reachability graph may take
+	// long to build.
+	if _, err := reward.CompileAll(config.Rewards); err != nil {
+		return fmt.Errorf("invalid reward configuration: %w", err)
+	}
+	if err := config.analysisOptions().Validate(); err != nil {
+		return fmt.Errorf("invalid analysis configuration: %w", err)
+	}
+	if err := config.validateSimulation(); err != nil {
+		return fmt.Errorf("invalid simulation configuration: %w", err)
+	}
+	if err := config.validateOutput(); err != nil {
+		return fmt.Errorf("invalid output configuration: %w", err)
+	}
+
+	var output io.Writer = os.Stdout
+	if *outputPath != "" {
+		file, err := os.Create(*outputPath)
+		if err != nil {
+			return fmt.Errorf("error creating output file: %w", err)
+		}
+		defer file.Close()
+		output = file
+	}
+	return analyzeFile(config, flags.Arg(0), output)
+}
+
+// analyzeFile reads a PNML net, builds its reachability graph within the configured limits, solves
+// for the steady state and writes the result as a single sample.
+func analyzeFile(config *Config, path string, output io.Writer) error {
+	model, err := pnml.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	pn := model.Net
+	log.Printf("Read net %q with %d places and %d transitions", model.Name, pn.Places, pn.Transitions)
+	if pn.HasNonExponentialDelays() {
+		return errors.New("net has non-exponential delays and can only be simulated")
+	}
+	if err := config.validateNetRewards(pn); err != nil {
+		return err
+	}
+
+	rg, err := generation.GenerateTangibleReachabilityGraph(pn, config.PlaceUpperBound, config.MarksUpperLimit)
+	if err != nil {
+		return fmt.Errorf("error generating reachability graph: %w", err)
+	}
+	if !rg.IsBounded {
+		return fmt.Errorf("reachability graph exceeds the place bound %d or the marking limit %d", config.PlaceUpperBound, config.MarksUpperLimit)
+	}
+
+	result, stats, err := analysis.Analyze(rg, model.Rates, config.analysisOptions())
+	if err != nil {
+		return err
+	}
+	log.Printf("%s solver finished after %d iterations with residual %.3g", stats.Method, stats.Iterations, stats.Residual)
+
+	labels, err := computeNetLabels(config, pn)
+	if err != nil {
+		return err
+	}
+
+	sw, err := newSampleWriter(output, config.Format)
+	if err != nil {
+		return err
+	}
+	sw.write(&sample{
+		PetriNet:          pn,
+		ReachabilityGraph: rg,
+		LambdaValues:      model.Rates,
+		Analysis:          result,
+		Labels:            labels,
+	})
+	return sw.close()
+}
+
+// exportPNML writes the net of a generated sample, with its firing rates, to the PNML export
+// directory.
+func exportPNML(config *Config, i int, s *sample) error {
+	name := fmt.Sprintf("sample_%06d", i)
+	model := pnml.NewModel(name, s.PetriNet, s.LambdaValues)
+	return pnml.WriteFile(filepath.Join(config.PNMLExportDir, name+".pnml"), model)
+}
+
+// exportGraph writes record j of sample i to the graph export directory as a .npz archive of the
+// graph of its net. The node labels are the average markings and throughputs of the analysis, or
+// the means of the simulation estimates of nets that were simulated instead; records with neither
+// are written without labels.
+func exportGraph(config *Config, i, j int, s *sample) error {
+	g := graphdata.New(s.PetriNet, s.LambdaValues)
+	switch {
+	case s.Analysis != nil && s.Analysis.AverageMarkings != nil:
+		if err := g.SetLabels(s.Analysis.AverageMarkings, s.Analysis.Throughputs); err != nil {
+			return err
+		}
+	case s.Simulation != nil:
+		if err := g.SetLabels(estimateMeans(s.Simulation.AverageMarkings), estimateMeans(s.Simulation.Throughputs)); err != nil {
+			return err
+		}
+	}
+
+	path := filepath.Join(config.GraphExportDir, fmt.Sprintf("sample_%06d_%03d.npz", i, j))
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := g.WriteNPZ(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// estimateMeans returns the means of simulation estimates.
+func estimateMeans(estimates []simulation.Estimate) []float64 {
+	means := make([]float64, len(estimates))
+	for i, e := range estimates {
+		means[i] = e.Mean
+	}
+	return means
+}
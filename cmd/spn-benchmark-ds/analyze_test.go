@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"spn-benchmark-ds/internal/pkg/graphdata"
+	"spn-benchmark-ds/internal/pkg/npy"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+	"spn-benchmark-ds/internal/pkg/pnml"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeFile(t *testing.T) {
+	// P1 -T1-> P2 -T2-> P1 with one token and rates 1 and 3.
+	pn := petrinet.NewPetriNet(2, 2)
+	pn.Matrix = []int{
+		1, 0, 0, 1, 1,
+		0, 1, 1, 0, 0,
+	}
+	pn.InitialMarking = []int{1, 0}
+	path := filepath.Join(t.TempDir(), "cycle.pnml")
+	if err := pnml.WriteFile(path, pnml.NewModel("cycle", pn, []float64{1, 3})); err != nil {
+		t.Fatalf("Error writing pnml file: %v", err)
+	}
+
+	config := &Config{Format: "jsonl", PlaceUpperBound: 10, MarksUpperLimit: 100}
+	var output bytes.Buffer
+	if err := analyzeFile(config, path, &output); err != nil {
+		t.Fatalf("Error analyzing pnml file: %v", err)
+	}
+
+	var result struct {
+		LambdaValues     []float64 `json:"lambda_values"`
+		SteadyStateProbs []float64 `json:"steady_state_probs"`
+	}
+	if err := json.Unmarshal(output.Bytes(), &result); err != nil {
+		t.Fatalf("Error decoding analysis output: %v", err)
+	}
+	if !slices.Equal(result.LambdaValues, []float64{1, 3}) {
+		t.Errorf("Expected the rates of the pnml file, got %v", result.LambdaValues)
+	}
+	expected := []float64{0.75, 0.25}
+	for i, p := range expected {
+		if math.Abs(result.SteadyStateProbs[i]-p) > 1e-9 {
+			t.Errorf("Expected probability %d to be %f, got %f", i, p, result.SteadyStateProbs[i])
+		}
+	}
+
+	pn.SetDelay(0, petrinet.Delay{Kind: petrinet.DelayDeterministic})
+	if err := pnml.WriteFile(path, pnml.NewModel("cycle", pn, []float64{1, 3})); err != nil {
+		t.Fatalf("Error writing pnml file: %v", err)
+	}
+	if err := analyzeFile(config, path, &output); err == nil {
+		t.Errorf("Expected an error for a net with non-exponential delays")
+	}
+}
+
+func TestAnalyzeCommandValidatesConfig(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing.pnml")
+	for name, tc := range map[string]struct{ config, message string }{
+		"format":     {`format: "parquet"`, "invalid output configuration"},
+		"simulation": {"format: \"jsonl\"\nsimulation_mode: \"sometimes\"", "invalid simulation configuration"},
+		"reward":     {"format: \"jsonl\"\nrewards:\n  - name: bad\n    rate: \"p0 +\"", "invalid reward configuration"},
+	} {
+		path := filepath.Join(dir, name+".yaml")
+		if err := os.WriteFile(path, []byte(tc.config+"\n"), 0600); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+		// The net does not exist, so only a check made before reading it can report the error.
+		err := runAnalyzeCommand([]string{"--config", path, missing})
+		if err == nil || !strings.Contains(err.Error(), tc.message) {
+			t.Errorf("%s: expected %q, got %v", name, tc.message, err)
+		}
+	}
+}
+
+func TestRunExportsPNML(t *testing.T) {
+	config := testConfig(t)
+	config.Seed = 7
+	config.PNMLExportDir = filepath.Join(t.TempDir(), "pnml")
+
+	type record struct {
+		PetriNet     *petrinet.PetriNet `json:"petri_net"`
+		LambdaValues []float64          `json:"lambda_values"`
+	}
+	records := runRecords[record](t, config)
+
+	files, err := filepath.Glob(filepath.Join(config.PNMLExportDir, "*.pnml"))
+	if err != nil {
+		t.Fatalf("Error listing exported files: %v", err)
+	}
+	if len(files) == 0 || len(files) != len(records) {
+		t.Fatalf("Expected one pnml file per sample, got %d files for %d samples", len(files), len(records))
+	}
+	for i, file := range files {
+		m, err := pnml.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Error reading exported file %s: %v", file, err)
+		}
+		if !slices.Equal(m.Net.Matrix, records[i].PetriNet.Matrix) {
+			t.Errorf("%s: expected matrix %v, got %v", file, records[i].PetriNet.Matrix, m.Net.Matrix)
+		}
+		if !slices.Equal(m.Rates, records[i].LambdaValues) {
+			t.Errorf("%s: expected rates %v, got %v", file, records[i].LambdaValues, m.Rates)
+		}
+	}
+}
+
+func TestRunExportsGraphs(t *testing.T) {
+	config := testConfig(t)
+	config.NumSamples = 4
+	config.Seed = 7
+	config.EnableTransformations = true
+	config.MaxTransformsPerSample = 2
+	config.GraphExportDir = filepath.Join(t.TempDir(), "graphs")
+
+	type record struct {
+		PetriNet        *petrinet.PetriNet `json:"petri_net"`
+		LambdaValues    []float64          `json:"lambda_values"`
+		AverageMarkings []float64          `json:"average_markings"`
+		Throughputs     []float64          `json:"throughputs"`
+	}
+	records := runRecords[record](t, config)
+
+	files, err := filepath.Glob(filepath.Join(config.GraphExportDir, "*.npz"))
+	if err != nil {
+		t.Fatalf("Error listing exported files: %v", err)
+	}
+	if len(files) == 0 || len(files) != len(records) {
+		t.Fatalf("Expected one npz file per record, got %d files for %d records", len(files), len(records))
+	}
+	for i, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file, err)
+		}
+		arrays, err := npy.ReadNPZ(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("Error reading exported file %s: %v", file, err)
+		}
+		r := records[i]
+		if shape := arrays["place_features"].Shape; !slices.Equal(shape, []int{r.PetriNet.Places, len(graphdata.PlaceFeatures)}) {
+			t.Errorf("%s: expected place features of %d places, got shape %v", file, r.PetriNet.Places, shape)
+		}
+		features := arrays["transition_features"].Data.([]float64)
+		for tr, rate := range r.LambdaValues {
+			if features[tr*len(graphdata.TransitionFeatures)] != rate {
+				t.Errorf("%s: expected rate %v of transition %d, got %v", file, rate, tr, features[tr*len(graphdata.TransitionFeatures)])
+			}
+		}
+		if labels := arrays["place_labels"].Data; !slices.Equal(labels.([]float64), r.AverageMarkings) {
+			t.Errorf("%s: expected place labels %v, got %v", file, r.AverageMarkings, labels)
+		}
+		if labels := arrays["transition_labels"].Data; !slices.Equal(labels.([]float64), r.Throughputs) {
+			t.Errorf("%s: expected transition labels %v, got %v", file, r.Throughputs, labels)
+		}
+	}
+}
@@ -1,12 +1,30 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"spn-benchmark-ds/internal/pkg/analysis"
+	"spn-benchmark-ds/internal/pkg/dataset"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+	"spn-benchmark-ds/internal/pkg/rates"
+	"spn-benchmark-ds/internal/pkg/reward"
+	"spn-benchmark-ds/internal/pkg/simulation"
 
 	"gopkg.in/yaml.v2"
 )
 
+// Simulation modes accepted by Config.SimulationMode.
+const (
+	// simulationOff never simulates.
+	simulationOff = "off"
+	// simulationFallback simulates the nets whose reachability graph exceeds the marking limit
+	// instead of discarding them.
+	simulationFallback = "fallback"
+	// simulationAlways simulates every net in addition to solving it, to cross-check the solver.
+	simulationAlways = "always"
+)
+
 // Config holds the configuration for the dataset generation.
 type Config struct {
 	// GenerationMode is the generation mode (e.g., "random", "grid").
@@ -19,8 +37,20 @@ type Config struct {
 	NumSamples int `yaml:"num_samples"`
 	// OutputFile is the path to the output file.
 	OutputFile string `yaml:"output_file"`
-	// Format is the output format (e.g., "jsonl", "protobuf").
+	// Format is the output format (e.g., "jsonl", "protobuf", "arrow").
 	Format string `yaml:"format"`
+	// OutputDir, if set, writes the dataset to this directory as shards described by a
+	// manifest.json, instead of to OutputFile or OutputGridLocation.
+	OutputDir string `yaml:"output_dir"`
+	// ShardMaxSamples starts a new shard of OutputDir after this many samples. Zero disables the
+	// limit.
+	ShardMaxSamples int `yaml:"shard_max_samples"`
+	// ShardMaxBytes starts a new shard of OutputDir before a sample would take it beyond this many
+	// bytes, before compression. Zero disables the limit.
+	ShardMaxBytes int64 `yaml:"shard_max_bytes"`
+	// Compression is the compression of the shards of OutputDir: "none", the default, "gzip" or
+	// "zstd".
+	Compression string `yaml:"compression"`
 	// PlaceUpperBound is the maximum number of tokens a place can hold.
 	PlaceUpperBound int `yaml:"place_upper_bound"`
 	// MarksLowerLimit is the minimum number of markings a Petri net must have.
@@ -31,6 +61,11 @@ type Config struct {
 	MinFiringRate int `yaml:"min_firing_rate"`
 	// MaxFiringRate is the maximum firing rate of a transition.
 	MaxFiringRate int `yaml:"max_firing_rate"`
+	// FiringRates is the distribution the firing rates are drawn from: a "distribution"
+	// ("int_uniform", the default, "uniform", "log_uniform", "gamma" or "choice") with its "min"
+	// and "max", per-transition "ranges", gamma "shape" and "scale", or the "values" to choose from.
+	// Unset min and max default to MinFiringRate and MaxFiringRate.
+	FiringRates rates.Sampler `yaml:"firing_rates"`
 	// EnableTransformations enables or disables transformations.
 	EnableTransformations bool `yaml:"enable_transformations"`
 	// MaxTransformsPerSample is the maximum number of transformations to apply to a sample.
@@ -51,6 +86,283 @@ type Config struct {
 	TemporaryGridLocation string `yaml:"temporary_grid_location"`
 	// OutputGridLocation is the path to the output grid location.
 	OutputGridLocation string `yaml:"output_grid_location"`
+	// Seed is the base seed of all random streams. Sample i draws from a stream derived from (Seed, i),
+	// so the same seed always produces the same dataset. Zero picks a time-based seed.
+	Seed int64 `yaml:"seed"`
+	// Workers is the number of goroutines generating samples concurrently. Zero uses one per CPU.
+	// The output is identical for any number of workers.
+	Workers int `yaml:"workers"`
+	// Solver is the steady-state solver: "dense", "power", "jacobi", "gauss_seidel", "sor" or "bicgstab".
+	Solver string `yaml:"solver"`
+	// SolverTolerance is the residual at which the iterative solvers stop.
+	SolverTolerance float64 `yaml:"solver_tolerance"`
+	// SolverMaxIterations is the iteration limit of the iterative solvers.
+	SolverMaxIterations int `yaml:"solver_max_iterations"`
+	// SolverRelaxation is the relaxation factor of the "sor" and "jacobi" solvers.
+	SolverRelaxation float64 `yaml:"solver_relaxation"`
+	// SolverMaxResidual drops samples whose steady-state residual exceeds it. Zero disables the check.
+	SolverMaxResidual float64 `yaml:"solver_max_residual"`
+	// SolverMaxClampedMass drops samples that lost more than this share of their probability mass
+	// to clamping negative probabilities. Zero disables the check.
+	SolverMaxClampedMass float64 `yaml:"solver_max_clamped_mass"`
+	// SolverMaxConditionNumber drops samples whose linear system has a larger estimated condition
+	// number. Only the "dense" solver estimates it, and other solvers reject a nonzero limit. Zero
+	// disables the check.
+	SolverMaxConditionNumber float64 `yaml:"solver_max_condition_number"`
+	// ReducibleChainPolicy selects how nets whose chain is not irreducible are handled: "reject"
+	// discards them, and "per_bscc", the default, solves every bottom strongly connected component
+	// and weights it by the probability of being absorbed into it.
+	ReducibleChainPolicy string `yaml:"reducible_chain_policy"`
+	// ImmediateTransitionProb is the probability that a generated transition is immediate.
+	// Zero generates plain SPNs; any other value generates GSPNs.
+	ImmediateTransitionProb float64 `yaml:"immediate_transition_prob"`
+	// MaxImmediateWeight is the maximum firing weight of an immediate transition.
+	MaxImmediateWeight int `yaml:"max_immediate_weight"`
+	// MaxImmediatePriority is the maximum priority of an immediate transition.
+	MaxImmediatePriority int `yaml:"max_immediate_priority"`
+	// MaxArcWeight is the maximum weight (multiplicity) of a generated arc. Values up to one keep
+	// every arc at weight one.
+	MaxArcWeight int `yaml:"max_arc_weight"`
+	// ArcWeightDistribution is the distribution arc weights are drawn from: "uniform" or "geometric".
+	ArcWeightDistribution string `yaml:"arc_weight_distribution"`
+	// InhibitorArcProb is the probability of adding an inhibitor arc between a place and a
+	// transition it does not feed. Zero generates nets without inhibitor arcs.
+	InhibitorArcProb float64 `yaml:"inhibitor_arc_prob"`
+	// MaxInhibitorThreshold is the maximum token threshold of an inhibitor arc.
+	MaxInhibitorThreshold int `yaml:"max_inhibitor_threshold"`
+	// NonExponentialProb is the probability that a timed transition gets a delay distribution drawn
+	// from DelayDistributions instead of an exponential one. Nets with such delays are labeled by
+	// simulation. Zero generates Markovian nets only.
+	NonExponentialProb float64 `yaml:"non_exponential_prob"`
+	// DelayDistributions are the non-exponential delay distributions, each with a kind
+	// ("deterministic", "erlang", "hyperexponential", "uniform" or "lognormal") and its shape
+	// parameters "stages", "scv" or "spread". The firing rate of a transition stays its mean
+	// inverse delay.
+	DelayDistributions []petrinet.Delay `yaml:"delay_distributions"`
+	// PNMLExportDir is the directory the net of every accepted sample is written to as a PNML file,
+	// with its firing rates. Empty disables the export.
+	PNMLExportDir string `yaml:"pnml_export_dir"`
+	// GraphExportDir is the directory every record is written to as a graph of its net, in a .npz
+	// archive of NumPy arrays for graph neural networks. Empty disables the export.
+	GraphExportDir string `yaml:"graph_export_dir"`
+	// CoverabilityLimit is the maximum number of markings of the coverability graph built to
+	// explain why a net was discarded: as unbounded, or as bounded but beyond the place or marking
+	// limits. Zero skips the coverability graph.
+	CoverabilityLimit int `yaml:"coverability_limit"`
+	// EnableInvariants adds the minimal P- and T-semiflows of each net, and whether it is
+	// conservative and consistent, to its samples.
+	EnableInvariants bool `yaml:"enable_invariants"`
+	// EnableSiphonsAndTraps adds the minimal siphons and traps of each net, and whether it is
+	// ordinary, free-choice and has the Commoner property, to its samples.
+	EnableSiphonsAndTraps bool `yaml:"enable_siphons_and_traps"`
+	// ResponseTimePairs are the place and transition pairs, given as {place: p, transition: t},
+	// whose mean response time by Little's law is added to each sample.
+	ResponseTimePairs []analysis.PlaceTransitionPair `yaml:"response_time_pairs"`
+	// TransientTimes are the time points at which the transient distribution and the expected
+	// number of tokens in each place are added to each sample. Empty disables the transient labels.
+	TransientTimes []float64 `yaml:"transient_times"`
+	// TransientEpsilon bounds the truncation error of the transient distributions. Zero selects 1e-9.
+	TransientEpsilon float64 `yaml:"transient_epsilon"`
+	// Rewards are user-defined reward structures, each with a name, a rate expression of the
+	// marking and impulse expressions keyed by transition, whose expected values are added to
+	// each sample.
+	Rewards []reward.Reward `yaml:"rewards"`
+	// SimulationMode selects when nets are simulated: "off", the default, "fallback" to label nets
+	// whose reachability graph exceeds MarksUpperLimit by simulation instead of discarding them, or
+	// "always" to add simulation estimates to every sample.
+	SimulationMode string `yaml:"simulation_mode"`
+	// SimulationTime is the simulated time over which estimates are collected. Zero selects 10000.
+	SimulationTime float64 `yaml:"simulation_time"`
+	// SimulationWarmup is the simulated time discarded before estimates are collected.
+	SimulationWarmup float64 `yaml:"simulation_warmup"`
+	// SimulationBatches is the number of batches of the batch-means confidence intervals.
+	// Zero selects 20.
+	SimulationBatches int `yaml:"simulation_batches"`
+	// SimulationConfidence is the confidence level of the intervals. Zero selects 0.95.
+	SimulationConfidence float64 `yaml:"simulation_confidence"`
+}
+
+// solverOptions returns the steady-state solver options described by the configuration.
+func (c *Config) solverOptions() analysis.SolverOptions {
+	return analysis.SolverOptions{
+		Method:             c.Solver,
+		Tolerance:          c.SolverTolerance,
+		MaxIterations:      c.SolverMaxIterations,
+		Relaxation:         c.SolverRelaxation,
+		MaxResidual:        c.SolverMaxResidual,
+		MaxClampedMass:     c.SolverMaxClampedMass,
+		MaxConditionNumber: c.SolverMaxConditionNumber,
+	}
+}
+
+// analysisOptions returns the options of the steady-state, transient and performance analysis
+// described by the configuration.
+func (c *Config) analysisOptions() analysis.Options {
+	return analysis.Options{
+		Solver:            c.solverOptions(),
+		ReduciblePolicy:   c.ReducibleChainPolicy,
+		ResponseTimePairs: c.ResponseTimePairs,
+		TransientTimes:    c.TransientTimes,
+		TransientEpsilon:  c.TransientEpsilon,
+		Rewards:           c.Rewards,
+	}
+}
+
+// rateSampler returns the firing rate distribution described by the configuration.
+func (c *Config) rateSampler() rates.Sampler {
+	sampler := c.FiringRates
+	if sampler.Min == 0 && sampler.Max == 0 {
+		sampler.Min, sampler.Max = float64(c.MinFiringRate), float64(c.MaxFiringRate)
+	}
+	return sampler
+}
+
+// simulationOptions returns the simulation options described by the configuration. Simulated
+// nets are held to the same place bound as the reachability graphs.
+func (c *Config) simulationOptions() simulation.Options {
+	return simulation.Options{
+		Time:       c.SimulationTime,
+		Warmup:     c.SimulationWarmup,
+		Batches:    c.SimulationBatches,
+		Confidence: c.SimulationConfidence,
+		PlaceLimit: c.PlaceUpperBound,
+	}
+}
+
+// validateSimulation checks the simulation mode and options.
+func (c *Config) validateSimulation() error {
+	switch c.SimulationMode {
+	case "", simulationOff:
+		return nil
+	case simulationFallback, simulationAlways:
+		return c.simulationOptions().Validate()
+	default:
+		return fmt.Errorf("unknown simulation mode %q", c.SimulationMode)
+	}
+}
+
+// validateDelays checks the non-exponential delay distributions.
+func (c *Config) validateDelays() error {
+	if c.NonExponentialProb <= 0 {
+		return nil
+	}
+	if len(c.DelayDistributions) == 0 {
+		return fmt.Errorf("non-exponential delays need at least one delay distribution")
+	}
+	if c.GenerationMode == "grid" {
+		return fmt.Errorf("non-exponential delays are not supported in grid mode, whose samples are solved numerically")
+	}
+	for i, d := range c.DelayDistributions {
+		if err := d.Validate(); err != nil {
+			return fmt.Errorf("delay distribution %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateOutput checks the output format and the sharding and compression of the output
+// directory.
+func (c *Config) validateOutput() error {
+	switch c.Format {
+	case "jsonl", "protobuf", "arrow":
+	default:
+		return fmt.Errorf("unsupported output format %q", c.Format)
+	}
+	if err := dataset.ValidateCompression(c.Compression); err != nil {
+		return err
+	}
+	if c.ShardMaxSamples < 0 || c.ShardMaxBytes < 0 {
+		return fmt.Errorf("shard limits must not be negative, got %d samples and %d bytes", c.ShardMaxSamples, c.ShardMaxBytes)
+	}
+	sharded := c.ShardMaxSamples > 0 || c.ShardMaxBytes > 0 || (c.Compression != "" && c.Compression != dataset.CompressionNone)
+	if sharded && c.OutputDir == "" {
+		return fmt.Errorf("sharding and compression need an output_dir")
+	}
+	return nil
+}
+
+// manifestConfig returns the configuration as recorded in the manifest of a dataset: a JSON
+// object with the keys of the configuration file.
+func (c *Config) manifestConfig() (json.RawMessage, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	var object interface{}
+	if err := yaml.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return json.Marshal(jsonValue(object))
+}
+
+// jsonValue converts a decoded YAML value into one encoding/json can marshal, whose maps have
+// string keys.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			object[fmt.Sprint(key)] = jsonValue(value)
+		}
+		return object
+	case []interface{}:
+		for i, value := range v {
+			v[i] = jsonValue(value)
+		}
+		return v
+	}
+	return v
+}
+
+// validateResponseTimePairs checks that the response time pairs name places and transitions of
+// the generated nets.
+func (c *Config) validateResponseTimePairs() error {
+	for i, pair := range c.ResponseTimePairs {
+		if pair.Place < 0 || pair.Place >= c.NumPlaces {
+			return fmt.Errorf("response time pair %d: place %d out of range [0, %d)", i, pair.Place, c.NumPlaces)
+		}
+		if pair.Transition < 0 || pair.Transition >= c.NumTransitions {
+			return fmt.Errorf("response time pair %d: transition %d out of range [0, %d)", i, pair.Transition, c.NumTransitions)
+		}
+	}
+	return nil
+}
+
+// validateRewards checks that the reward structures only refer to places and transitions of the
+// generated nets, and that they have no impulses when any generated transition may be immediate.
+func (c *Config) validateRewards() error {
+	structures, err := reward.CompileAll(c.Rewards)
+	if err != nil {
+		return err
+	}
+	for _, s := range structures {
+		if err := s.Validate(c.NumPlaces, c.NumTransitions); err != nil {
+			return err
+		}
+		if len(s.Impulses) > 0 && c.ImmediateTransitionProb > 0 {
+			return fmt.Errorf("reward %q has impulses, which are not supported with immediate_transition_prob > 0", s.Name)
+		}
+	}
+	return nil
+}
+
+// validateNetRewards checks that the reward structures only refer to places and transitions of an
+// imported net pn, and that they have no impulse on an immediate transition of pn, which the
+// tangible reachability graph would never earn.
+func (c *Config) validateNetRewards(pn *petrinet.PetriNet) error {
+	structures, err := reward.CompileAll(c.Rewards)
+	if err != nil {
+		return err
+	}
+	for _, s := range structures {
+		if err := s.Validate(pn.Places, pn.Transitions); err != nil {
+			return err
+		}
+		if err := s.ValidateImmediate(pn.Immediate); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // LoadConfig loads the configuration from a YAML file.
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"spn-benchmark-ds/internal/pkg/dataset"
+)
+
+// inspectUsage describes the inspect subcommand.
+const inspectUsage = `Usage: spn-benchmark-ds inspect [flags] dataset
+
+Prints the samples of a dataset file or directory in any of the output formats, compressed or not, followed by a
+summary. The flags select the samples by index or by the size of their net and reachability graph.
+
+Flags:
+`
+
+// inspectOptions selects and formats the samples printed by the inspect subcommand.
+type inspectOptions struct {
+	// Index selects the sample with this index; a negative index selects all samples.
+	Index int
+	// MinPlaces, MaxPlaces, MinMarkings and MaxMarkings select samples by the number of places and
+	// of markings of the reachability graph. Zero disables a limit.
+	MinPlaces, MaxPlaces     int
+	MinMarkings, MaxMarkings int
+	// Limit is the maximum number of samples printed; zero prints all.
+	Limit int
+	// SummaryOnly prints the summary without the samples.
+	SummaryOnly bool
+	// JSON prints the samples as indented JSON objects, with the fields of the JSONL records,
+	// instead of text.
+	JSON bool
+}
+
+// runInspectCommand parses the arguments of the inspect subcommand and runs it.
+func runInspectCommand(args []string) error {
+	var opts inspectOptions
+	flags := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	flags.IntVar(&opts.Index, "index", -1, "Print only the sample with this index")
+	flags.IntVar(&opts.MinPlaces, "min-places", 0, "Select samples with at least this many places")
+	flags.IntVar(&opts.MaxPlaces, "max-places", 0, "Select samples with at most this many places")
+	flags.IntVar(&opts.MinMarkings, "min-markings", 0, "Select samples with at least this many markings")
+	flags.IntVar(&opts.MaxMarkings, "max-markings", 0, "Select samples with at most this many markings")
+	flags.IntVar(&opts.Limit, "limit", 0, "Print at most this many samples (default all)")
+	flags.BoolVar(&opts.SummaryOnly, "summary", false, "Print only the summary")
+	flags.BoolVar(&opts.JSON, "json", false, "Print the samples as indented JSON objects instead of text")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), inspectUsage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("inspect expects exactly one dataset")
+	}
+	return inspectDataset(flags.Arg(0), opts, os.Stdout)
+}
+
+// inspectDataset prints the selected samples of a dataset and a summary of them to w.
+func inspectDataset(path string, opts inspectOptions, w io.Writer) error {
+	r, err := dataset.Open(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var summary datasetSummary
+	printed := 0
+	for i := 0; ; i++ {
+		s, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		summary.total++
+		if !opts.selects(i, s) {
+			continue
+		}
+		summary.add(s)
+		if opts.SummaryOnly || (opts.Limit > 0 && printed == opts.Limit) {
+			continue
+		}
+		if err := printSample(w, i, s, opts.JSON); err != nil {
+			return err
+		}
+		printed++
+	}
+	summary.print(w, r.Format())
+	return nil
+}
+
+// selects reports whether sample i is selected.
+func (o inspectOptions) selects(i int, s *dataset.Sample) bool {
+	if o.Index >= 0 && i != o.Index {
+		return false
+	}
+	places, markings := s.PetriNet.Places, numMarkings(s)
+	return withinLimits(places, o.MinPlaces, o.MaxPlaces) && withinLimits(markings, o.MinMarkings, o.MaxMarkings)
+}
+
+// withinLimits reports whether n is within the limits, where zero disables a limit.
+func withinLimits(n, min, max int) bool {
+	return (min == 0 || n >= min) && (max == 0 || n <= max)
+}
+
+// numMarkings returns the number of markings of the reachability graph of a sample, or zero
+// without one.
+func numMarkings(s *dataset.Sample) int {
+	if s.ReachabilityGraph == nil {
+		return 0
+	}
+	return s.ReachabilityGraph.NumVertices
+}
+
+// printSample prints sample i as text or as an indented JSON object with the fields of a JSONL
+// record.
+func printSample(w io.Writer, i int, s *dataset.Sample, asJSON bool) error {
+	if asJSON {
+		record := toJSONSample(&sample{
+			PetriNet:          s.PetriNet,
+			ReachabilityGraph: s.ReachabilityGraph,
+			LambdaValues:      s.LambdaValues,
+			Analysis:          s.Analysis,
+			Simulation:        s.Simulation,
+			Labels:            netLabels{Invariants: s.Invariants, SiphonsAndTraps: s.SiphonsAndTraps},
+		})
+		data, err := json.MarshalIndent(record, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshalling sample %d: %w", i, err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	pn := s.PetriNet
+	fmt.Fprintf(w, "Sample %d\n", i)
+	arcs, inhibitors, immediate := 0, 0, 0
+	for p := 0; p < pn.Places; p++ {
+		for t := 0; t < 2*pn.Transitions; t++ {
+			if pn.At(p, t) != 0 {
+				arcs++
+			}
+		}
+		for t := 0; t < pn.Transitions; t++ {
+			if pn.Inhibitor(p, t) > 0 {
+				inhibitors++
+			}
+		}
+	}
+	for t := 0; t < pn.Transitions; t++ {
+		if pn.IsImmediate(t) {
+			immediate++
+		}
+	}
+	fmt.Fprintf(w, "  Net: %d places, %d transitions (%d immediate), %d arcs, %d inhibitor arcs\n", pn.Places, pn.Transitions, immediate, arcs, inhibitors)
+	fmt.Fprintf(w, "  Initial marking: %v\n", pn.InitialMarking)
+	fmt.Fprintf(w, "  Firing rates: %v\n", s.LambdaValues)
+	if rg := s.ReachabilityGraph; rg != nil {
+		fmt.Fprintf(w, "  Reachability graph: %d markings, %d arcs\n", rg.NumVertices, rg.NumEdges)
+	}
+	if a := s.Analysis; a != nil {
+		fmt.Fprintf(w, "  Average markings: %v\n", a.AverageMarkings)
+		fmt.Fprintf(w, "  Throughputs: %v\n", a.Throughputs)
+		if stats := a.SolverStats; stats != nil {
+			fmt.Fprintf(w, "  Solver: %s, %d iterations, residual %g, converged %t\n", stats.Method, stats.Iterations, stats.Residual, stats.Converged)
+		}
+		if a.Chain != nil {
+			fmt.Fprintf(w, "  Chain: %d components, %d bottom components, %d dead markings\n", a.Chain.NumComponents, len(a.Chain.BottomComponents), len(a.Chain.DeadMarkings))
+		}
+	}
+	if sim := s.Simulation; sim != nil {
+		fmt.Fprintf(w, "  Average markings (simulated): %v\n", estimateMeans(sim.AverageMarkings))
+		fmt.Fprintf(w, "  Throughputs (simulated): %v\n", estimateMeans(sim.Throughputs))
+		fmt.Fprintf(w, "  Simulation: %d firings in %d batches over time %g\n", sim.Firings, sim.Batches, sim.Time)
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// datasetSummary summarizes the selected samples of a dataset.
+type datasetSummary struct {
+	total, selected     int
+	solved, simulated   int
+	places, transitions sizeStats
+	markings            sizeStats
+	notConverged        int
+}
+
+// sizeStats accumulates the minimum, mean and maximum of a size.
+type sizeStats struct {
+	n, min, max, sum int
+}
+
+// add adds a size.
+func (s *sizeStats) add(v int) {
+	if s.n == 0 || v < s.min {
+		s.min = v
+	}
+	if s.n == 0 || v > s.max {
+		s.max = v
+	}
+	s.n++
+	s.sum += v
+}
+
+// String formats the statistics.
+func (s sizeStats) String() string {
+	if s.n == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("min %d, mean %.1f, max %d", s.min, float64(s.sum)/float64(s.n), s.max)
+}
+
+// add adds a selected sample.
+func (d *datasetSummary) add(s *dataset.Sample) {
+	d.selected++
+	d.places.add(s.PetriNet.Places)
+	d.transitions.add(s.PetriNet.Transitions)
+	if s.ReachabilityGraph != nil {
+		d.markings.add(s.ReachabilityGraph.NumVertices)
+	}
+	if s.Analysis != nil {
+		d.solved++
+		if stats := s.Analysis.SolverStats; stats != nil && !stats.Converged {
+			d.notConverged++
+		}
+	}
+	if s.Simulation != nil {
+		d.simulated++
+	}
+}
+
+// print prints the summary.
+func (d *datasetSummary) print(w io.Writer, format string) {
+	fmt.Fprintf(w, "Format: %s\n", format)
+	fmt.Fprintf(w, "Samples: %d of %d selected, %d solved (%d not converged), %d simulated\n", d.selected, d.total, d.solved, d.notConverged, d.simulated)
+	fmt.Fprintf(w, "Places: %v\n", d.places)
+	fmt.Fprintf(w, "Transitions: %v\n", d.transitions)
+	fmt.Fprintf(w, "Markings: %v\n", d.markings)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInspectDataset(t *testing.T) {
+	dir := t.TempDir()
+	config := &Config{
+		NumPlaces:              5,
+		NumTransitions:         4,
+		NumSamples:             6,
+		OutputFile:             filepath.Join(dir, "output.jsonl"),
+		Format:                 "jsonl",
+		PlaceUpperBound:        10,
+		MarksLowerLimit:        1,
+		MarksUpperLimit:        100,
+		MinFiringRate:          1,
+		MaxFiringRate:          10,
+		EnableTransformations:  true,
+		MaxTransformsPerSample: 2,
+		EnableInvariants:       true,
+		Seed:                   11,
+	}
+	if err := run(config); err != nil {
+		t.Fatalf("Error running generation: %v", err)
+	}
+	config.Format = "protobuf"
+	config.OutputDir = filepath.Join(dir, "dataset")
+	config.Compression = "zstd"
+	config.ShardMaxSamples = 2
+	if err := run(config); err != nil {
+		t.Fatalf("Error running generation: %v", err)
+	}
+
+	content, err := os.ReadFile(config.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(content), []byte("\n"))
+
+	// Samples read back from either format print as the records of the JSONL file.
+	for _, path := range []string{config.OutputFile, config.OutputDir} {
+		var out bytes.Buffer
+		if err := inspectDataset(path, inspectOptions{Index: -1, JSON: true}, &out); err != nil {
+			t.Fatalf("Error inspecting %s: %v", path, err)
+		}
+		decoder := json.NewDecoder(&out)
+		for i, line := range lines {
+			var record json.RawMessage
+			if err := decoder.Decode(&record); err != nil {
+				t.Fatalf("%s: error decoding sample %d: %v", path, i, err)
+			}
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, record); err != nil {
+				t.Fatalf("%s: error compacting sample %d: %v", path, i, err)
+			}
+			if !bytes.Equal(compact.Bytes(), line) {
+				t.Errorf("%s: sample %d differs from the JSONL record:\n%s\n%s", path, i, compact.Bytes(), line)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	if err := inspectDataset(config.OutputDir, inspectOptions{Index: 1}, &out); err != nil {
+		t.Fatalf("Error inspecting dataset: %v", err)
+	}
+	text := out.String()
+	if !strings.Contains(text, "Sample 1\n") || strings.Contains(text, "Sample 0\n") || strings.Contains(text, "Sample 2\n") {
+		t.Errorf("Expected only sample 1, got:\n%s", text)
+	}
+	if !strings.Contains(text, "Format: protobuf\n") || !strings.Contains(text, fmt.Sprintf("Samples: 1 of %d selected", len(lines))) {
+		t.Errorf("Expected a summary of one selected protobuf sample, got:\n%s", text)
+	}
+
+	out.Reset()
+	if err := inspectDataset(config.OutputFile, inspectOptions{Index: -1, MinPlaces: 100, SummaryOnly: true}, &out); err != nil {
+		t.Fatalf("Error inspecting dataset: %v", err)
+	}
+	if text := out.String(); strings.Contains(text, "Sample ") || !strings.Contains(text, "Samples: 0 of ") {
+		t.Errorf("Expected a summary without samples, got:\n%s", text)
+	}
+}
@@ -10,19 +10,42 @@ import (
 	"os"
 	"path/filepath"
 	"spn-benchmark-ds/internal/pkg/analysis"
+	"spn-benchmark-ds/internal/pkg/arrowipc"
 	"spn-benchmark-ds/internal/pkg/augmentation"
+	"spn-benchmark-ds/internal/pkg/dataset"
 	"spn-benchmark-ds/internal/pkg/generation"
 	"spn-benchmark-ds/internal/pkg/grid"
 	"spn-benchmark-ds/internal/pkg/petrinet"
 	"spn-benchmark-ds/internal/pkg/report"
+	"spn-benchmark-ds/internal/pkg/simulation"
 	"spn-benchmark-ds/internal/pkg/spn"
-
-	"google.golang.org/protobuf/proto"
+	"spn-benchmark-ds/internal/pkg/structural"
+	"spn-benchmark-ds/internal/pkg/utils"
+	"time"
 )
 
+// simulationStream keys the random streams of the simulations apart from those of the samples, so
+// enabling simulation does not change the numeric samples.
+const simulationStream int64 = 1
+
 // main is the entry point of the application.
 // It parses the command-line arguments, loads the configuration, and runs the generation process.
+// "spn-benchmark-ds analyze net.pnml" analyzes a single PNML net instead, and
+// "spn-benchmark-ds inspect dataset" prints the samples of a dataset.
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "analyze" {
+		if err := runAnalyzeCommand(os.Args[2:]); err != nil {
+			log.Fatalf("Error analyzing net: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "inspect" {
+		if err := runInspectCommand(os.Args[2:]); err != nil {
+			log.Fatalf("Error inspecting dataset: %v", err)
+		}
+		return
+	}
+
 	configPath := flag.String("config", "config.yaml", "Path to the configuration file")
 	flag.Parse()
 
@@ -39,81 +62,132 @@ func main() {
 // run is the main function of the application.
 // It generates the dataset based on the given configuration.
 func run(config *Config) error {
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+		log.Printf("No seed configured, using seed %d", config.Seed)
+	}
+	if err := config.analysisOptions().Validate(); err != nil {
+		return fmt.Errorf("invalid analysis configuration: %w", err)
+	}
+	if err := config.validateResponseTimePairs(); err != nil {
+		return fmt.Errorf("invalid analysis configuration: %w", err)
+	}
+	if err := config.validateRewards(); err != nil {
+		return fmt.Errorf("invalid reward configuration: %w", err)
+	}
+	if err := config.validateSimulation(); err != nil {
+		return fmt.Errorf("invalid simulation configuration: %w", err)
+	}
+	if err := config.validateDelays(); err != nil {
+		return fmt.Errorf("invalid delay configuration: %w", err)
+	}
+	if err := config.rateSampler().Validate(); err != nil {
+		return fmt.Errorf("invalid firing rate configuration: %w", err)
+	}
+	if err := config.validateOutput(); err != nil {
+		return fmt.Errorf("invalid output configuration: %w", err)
+	}
+	if err := petrinet.ValidateArcWeightDistribution(config.ArcWeightDistribution); err != nil {
+		return fmt.Errorf("invalid arc weight configuration: %w", err)
+	}
+	generate := runRandomGeneration
 	if config.GenerationMode == "grid" {
-		return runGridGeneration(config)
+		generate = runGridGeneration
 	}
-	return runRandomGeneration(config)
+	if err := generate(config); err != nil {
+		return err
+	}
+	fmt.Println("Dataset generation complete.")
+	return nil
+}
+
+// sample is a single dataset record: a net, its reachability graph and the analysis results for
+// one set of firing rates.
+type sample struct {
+	PetriNet          *petrinet.PetriNet
+	ReachabilityGraph *generation.ReachabilityGraph
+	LambdaValues      []float64
+	Analysis          *analysis.SPNAnalysisResult
+	// Simulation holds the simulation estimates, if the net was simulated.
+	Simulation *simulation.Result
+	// Labels holds the optional structural labels of the net.
+	Labels netLabels
+}
+
+// netLabels holds the structural labels of a net. Disabled labels are nil.
+type netLabels struct {
+	Invariants      *structural.Invariants
+	SiphonsAndTraps *structural.SiphonsAndTraps
+}
+
+// sampleBatch holds the records produced for one sample index, or the reason it was skipped.
+type sampleBatch struct {
+	samples []*sample
+	err     error
 }
 
 // runRandomGeneration generates the dataset based on the given configuration.
+// Samples are generated concurrently and written in index order, so the output does not depend on
+// the number of workers.
 func runRandomGeneration(config *Config) error {
-	file, err := os.Create(config.OutputFile)
+	output, err := createOutput(config, config.OutputFile)
 	if err != nil {
-		return fmt.Errorf("error creating output file: %w", err)
+		return err
 	}
-	defer file.Close()
-
-	var results []*report.SampleResult
-	for i := 0; i < config.NumSamples; i++ {
-		pn := petrinet.GenerateRandomPetriNet(config.NumPlaces, config.NumTransitions)
-		log.Printf("Generated Petri net with %d places and %d transitions", pn.Places, pn.Transitions)
-		pn.Prune()
-		log.Printf("Pruned Petri net")
-		pn.AddTokensRandomly()
-		log.Printf("Added tokens randomly")
-		rg, err := generation.GenerateReachabilityGraph(pn, config.PlaceUpperBound, config.MarksUpperLimit)
-		if err != nil {
-			log.Printf("Skipping sample %d: error generating reachability graph: %v", i, err)
-			continue
-		}
+	defer output.abort()
 
-		if !rg.IsBounded || rg.NumVertices < config.MarksLowerLimit {
-			log.Printf("Skipping sample %d: graph is unbounded or has too few markings", i)
-			continue
+	if config.PNMLExportDir != "" {
+		if err := os.MkdirAll(config.PNMLExportDir, 0755); err != nil {
+			return fmt.Errorf("error creating pnml export directory: %w", err)
 		}
-
-		lambdaValues := make([]float64, pn.Transitions)
-		for i := range lambdaValues {
-			lambdaValues[i] = float64(config.MinFiringRate + rand.Intn(config.MaxFiringRate-config.MinFiringRate+1))
+	}
+	if config.GraphExportDir != "" {
+		if err := os.MkdirAll(config.GraphExportDir, 0755); err != nil {
+			return fmt.Errorf("error creating graph export directory: %w", err)
 		}
+	}
 
-		stateMatrix, targetVector := analysis.ComputeStateEquation(rg, lambdaValues)
-		steadyStateProbs, err := analysis.SolveForSteadyState(stateMatrix, targetVector)
-		if err != nil {
-			log.Printf("Skipping sample %d: error solving for steady state: %v", i, err)
-			continue
+	var results []*report.SampleResult
+	err = runOrdered(config.NumSamples, workerCount(config), func(i int) sampleBatch {
+		samples, err := generateSamples(config, i)
+		return sampleBatch{samples: samples, err: err}
+	}, func(i int, batch sampleBatch) error {
+		if batch.err != nil {
+			log.Printf("Skipping sample %d: %v", i, batch.err)
+			return nil
 		}
-
-		avgMarkings, markingDensities := analysis.ComputeAverageMarkings(rg, steadyStateProbs)
-
-		analysisResult := &analysis.SPNAnalysisResult{
-			SteadyStateProbs: steadyStateProbs,
-			AverageMarkings:  avgMarkings,
-			MarkingDensities: markingDensities,
+		if config.PNMLExportDir != "" && len(batch.samples) > 0 {
+			if err := exportPNML(config, i, batch.samples[0]); err != nil {
+				return fmt.Errorf("error exporting sample %d: %w", i, err)
+			}
 		}
-
-		if config.EnableTransformations {
-			variations := augmentation.GeneratePetriNetVariations(pn, config.PlaceUpperBound, config.MarksLowerLimit, config.MarksUpperLimit, config.MaxTransformsPerSample, config.MinFiringRate, config.MaxFiringRate)
-			for _, variation := range variations {
-				writeSample(file, config.Format, pn, rg, lambdaValues, variation.SteadyStateProbs, variation.AverageMarkings, variation.MarkingDensities)
-				results = append(results, &report.SampleResult{
-					NumPlaces:      pn.Places,
-					NumTransitions: pn.Transitions,
-					Analysis:       variation,
-				})
+		for j, s := range batch.samples {
+			output.write(s)
+			if config.GraphExportDir != "" {
+				if err := exportGraph(config, i, j, s); err != nil {
+					return fmt.Errorf("error exporting graph of sample %d: %w", i, err)
+				}
+			}
+			if s.Analysis == nil {
+				continue
 			}
-		} else {
-			writeSample(file, config.Format, pn, rg, lambdaValues, steadyStateProbs, avgMarkings, markingDensities)
 			results = append(results, &report.SampleResult{
-				NumPlaces:      pn.Places,
-				NumTransitions: pn.Transitions,
-				Analysis:       analysisResult,
+				NumPlaces:      s.PetriNet.Places,
+				NumTransitions: s.PetriNet.Transitions,
+				Analysis:       s.Analysis,
 			})
 		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := output.close(); err != nil {
+		return err
 	}
 
 	if config.EnableStatisticsReport {
-		reportFile, err := os.Create(config.OutputFile + ".html")
+		reportFile, err := os.Create(reportPath(config))
 		if err != nil {
 			return fmt.Errorf("error creating report file: %w", err)
 		}
@@ -127,6 +201,175 @@ func runRandomGeneration(config *Config) error {
 	return nil
 }
 
+// generateSamples runs the generate, prune, reachability, solve and augment pipeline for sample i.
+// It returns the records to write, or an error describing why the sample was skipped.
+// In the "fallback" simulation mode, nets whose reachability graph exceeds the marking limit are
+// simulated instead and written without a graph or numeric results. Nets with non-exponential
+// delays are not Markovian, so they are always simulated and written without numeric results.
+func generateSamples(config *Config, i int) ([]*sample, error) {
+	rng := utils.NewRand(config.Seed, int64(i))
+	pn, err := generatePetriNet(config, rng, i)
+	if err != nil {
+		return nil, err
+	}
+	rg, err := generation.GenerateTangibleReachabilityGraph(pn, config.PlaceUpperBound, config.MarksUpperLimit)
+	if err != nil {
+		return nil, fmt.Errorf("error generating reachability graph: %w", err)
+	}
+	simulateOnly := config.SimulationMode == simulationFallback && rg.Truncation == generation.MarkingLimitExceeded
+	if !simulateOnly {
+		if err := checkGraph(config, pn, rg); err != nil {
+			return nil, err
+		}
+	}
+	labels, err := computeNetLabels(config, pn)
+	if err != nil {
+		return nil, err
+	}
+
+	lambdaValues := config.rateSampler().Sample(rng, pn.Transitions)
+
+	nonMarkovian := pn.HasNonExponentialDelays()
+	var simulationResult *simulation.Result
+	if simulateOnly || nonMarkovian || config.SimulationMode == simulationAlways {
+		simulationResult, err = simulation.Simulate(pn, lambdaValues, config.simulationOptions(), utils.NewRand(config.Seed, simulationStream, int64(i)))
+		if err != nil {
+			return nil, fmt.Errorf("error simulating: %w", err)
+		}
+		log.Printf("Sample %d: simulated %d firings over %g time units", i, simulationResult.Firings, simulationResult.Time)
+	}
+	if simulateOnly {
+		return []*sample{{PetriNet: pn, LambdaValues: lambdaValues, Simulation: simulationResult, Labels: labels}}, nil
+	}
+	if nonMarkovian {
+		return []*sample{{PetriNet: pn, ReachabilityGraph: rg, LambdaValues: lambdaValues, Simulation: simulationResult, Labels: labels}}, nil
+	}
+
+	analysisResult, stats, err := analysis.Analyze(rg, lambdaValues, config.analysisOptions())
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Sample %d: %s solver finished after %d iterations with residual %.3g, clamped mass %.3g and condition number %.3g",
+		i, stats.Method, stats.Iterations, stats.Residual, stats.ClampedMass, stats.ConditionNumber)
+
+	if !config.EnableTransformations {
+		return []*sample{{PetriNet: pn, ReachabilityGraph: rg, LambdaValues: lambdaValues, Analysis: analysisResult, Simulation: simulationResult, Labels: labels}}, nil
+	}
+
+	// Each variation is written with its own net, graph and firing rates. Its labels and simulation
+	// are computed again, since the Commoner property and the estimates depend on the marking.
+	variations := augmentation.GeneratePetriNetVariations(rng, pn, config.PlaceUpperBound, config.MarksLowerLimit, config.MarksUpperLimit, config.MaxTransformsPerSample, config.rateSampler(), config.analysisOptions())
+	samples := make([]*sample, 0, len(variations))
+	for j, v := range variations {
+		variationLabels, err := computeNetLabels(config, v.PetriNet)
+		if err != nil {
+			return nil, err
+		}
+		var variationSimulation *simulation.Result
+		if config.SimulationMode == simulationAlways {
+			variationSimulation, err = simulation.Simulate(v.PetriNet, v.LambdaValues, config.simulationOptions(), utils.NewRand(config.Seed, simulationStream, int64(i), int64(j+1)))
+			if err != nil {
+				return nil, fmt.Errorf("error simulating variation %d: %w", j, err)
+			}
+		}
+		samples = append(samples, &sample{PetriNet: v.PetriNet, ReachabilityGraph: v.ReachabilityGraph, LambdaValues: v.LambdaValues, Analysis: v.Analysis, Simulation: variationSimulation, Labels: variationLabels})
+	}
+	return samples, nil
+}
+
+// generateNet generates, prunes and marks a random Petri net and builds its reachability graph.
+// For GSPNs the returned graph is the tangible reachability graph.
+// It returns an error describing why the net was rejected if it does not pass the sample filter.
+func generateNet(config *Config, rng *rand.Rand, i int) (*petrinet.PetriNet, *generation.ReachabilityGraph, error) {
+	pn, err := generatePetriNet(config, rng, i)
+	if err != nil {
+		return nil, nil, err
+	}
+	rg, err := generation.GenerateTangibleReachabilityGraph(pn, config.PlaceUpperBound, config.MarksUpperLimit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error generating reachability graph: %w", err)
+	}
+	if err := checkGraph(config, pn, rg); err != nil {
+		return nil, nil, err
+	}
+	return pn, rg, nil
+}
+
+// generatePetriNet generates, prunes and marks a random Petri net.
+func generatePetriNet(config *Config, rng *rand.Rand, i int) (*petrinet.PetriNet, error) {
+	pn := petrinet.GenerateRandomPetriNet(rng, config.NumPlaces, config.NumTransitions)
+	log.Printf("Sample %d: generated Petri net with %d places and %d transitions", i, pn.Places, pn.Transitions)
+	pn.Prune(rng)
+	log.Printf("Sample %d: pruned Petri net", i)
+	if err := pn.AssignArcWeights(rng, config.MaxArcWeight, config.ArcWeightDistribution); err != nil {
+		return nil, fmt.Errorf("error assigning arc weights: %w", err)
+	}
+	pn.AddTokensRandomly(rng)
+	log.Printf("Sample %d: added tokens randomly", i)
+	if config.ImmediateTransitionProb > 0 {
+		pn.AssignImmediateTransitions(rng, config.ImmediateTransitionProb, config.MaxImmediateWeight, config.MaxImmediatePriority)
+	}
+	if config.InhibitorArcProb > 0 {
+		pn.AddInhibitorArcsRandomly(rng, config.InhibitorArcProb, config.MaxInhibitorThreshold)
+	}
+	if config.NonExponentialProb > 0 {
+		pn.AssignDelays(rng, config.NonExponentialProb, config.DelayDistributions)
+	}
+	return pn, nil
+}
+
+// checkGraph returns an error describing why a net is rejected if its reachability graph does not
+// pass the sample filter.
+func checkGraph(config *Config, pn *petrinet.PetriNet, rg *generation.ReachabilityGraph) error {
+	if !rg.IsBounded {
+		return truncationReason(config, pn, rg)
+	}
+	if rg.NumVertices < config.MarksLowerLimit {
+		return fmt.Errorf("graph has %d markings, fewer than the lower limit %d", rg.NumVertices, config.MarksLowerLimit)
+	}
+	return nil
+}
+
+// computeNetLabels computes the structural labels of a net that are enabled in the configuration.
+func computeNetLabels(config *Config, pn *petrinet.PetriNet) (netLabels, error) {
+	var labels netLabels
+	var err error
+	if config.EnableInvariants {
+		if labels.Invariants, err = structural.ComputeInvariants(pn, 0); err != nil {
+			return labels, fmt.Errorf("error computing invariants: %w", err)
+		}
+	}
+	if config.EnableSiphonsAndTraps {
+		if labels.SiphonsAndTraps, err = structural.AnalyzeSiphonsAndTraps(pn, 0); err != nil {
+			return labels, fmt.Errorf("error computing siphons and traps: %w", err)
+		}
+	}
+	return labels, nil
+}
+
+// truncationReason explains why the reachability graph of a net was truncated. When the coverability
+// limit is positive, the coverability graph of the net tells unbounded nets apart from bounded nets
+// that merely exceed the configured limits.
+func truncationReason(config *Config, pn *petrinet.PetriNet, rg *generation.ReachabilityGraph) error {
+	if config.CoverabilityLimit <= 0 {
+		return fmt.Errorf("graph is truncated: %v", rg.Truncation)
+	}
+	cg, err := generation.GenerateCoverabilityGraph(pn, config.CoverabilityLimit)
+	if err != nil {
+		return fmt.Errorf("graph is truncated (%v) and its coverability graph failed: %w", rg.Truncation, err)
+	}
+	switch {
+	case len(cg.UnboundedPlaces) > 0 && cg.Exact:
+		return fmt.Errorf("net is unbounded in places %v", cg.UnboundedPlaces)
+	case len(cg.UnboundedPlaces) > 0:
+		return fmt.Errorf("graph is truncated (%v) and the net may be unbounded in places %v", rg.Truncation, cg.UnboundedPlaces)
+	case !cg.Complete:
+		return fmt.Errorf("graph is truncated (%v) and boundedness is undecided within %d coverability markings", rg.Truncation, config.CoverabilityLimit)
+	default:
+		return fmt.Errorf("net is bounded with place bounds %v but its graph is truncated: %v", cg.PlaceBounds, rg.Truncation)
+	}
+}
+
 // runGridGeneration generates the dataset based on the given configuration.
 func runGridGeneration(config *Config) error {
 	// Generate raw data
@@ -141,122 +384,498 @@ func runGridGeneration(config *Config) error {
 	}
 
 	// Sample and transform data
-	results, err := grid.SampleAndTransformData(config.TemporaryGridLocation, config.SamplesPerGrid, config.LambdaVariationsPerSample, config.MinFiringRate, config.MaxFiringRate)
+	results, err := grid.SampleAndTransformData(config.TemporaryGridLocation, config.SamplesPerGrid, config.LambdaVariationsPerSample, config.rateSampler(), config.Seed, config.analysisOptions())
 	if err != nil {
 		return fmt.Errorf("error sampling and transforming data: %w", err)
 	}
 
 	// Package dataset
-	file, err := os.Create(config.OutputGridLocation)
+	output, err := createOutput(config, config.OutputGridLocation)
 	if err != nil {
-		return fmt.Errorf("error creating output file: %w", err)
+		return err
 	}
-	defer file.Close()
+	defer output.abort()
 
 	for _, result := range results {
-		writeSample(file, config.Format, result.PetriNet, result.ReachabilityGraph, result.LambdaValues, result.Analysis.SteadyStateProbs, result.Analysis.AverageMarkings, result.Analysis.MarkingDensities)
+		output.write(&sample{
+			PetriNet:          result.PetriNet,
+			ReachabilityGraph: result.ReachabilityGraph,
+			LambdaValues:      result.LambdaValues,
+			Analysis:          result.Analysis,
+		})
 	}
 
-	return nil
+	return output.close()
 }
 
+// generateRawData generates the unlabelled nets used to fill the grid.
+// Like runRandomGeneration, it generates samples concurrently and writes them in index order.
 func generateRawData(config *Config, outputPath string) error {
 	file, err := os.Create(outputPath)
 	if err != nil {
 		return fmt.Errorf("error creating output file: %w", err)
 	}
 	defer file.Close()
+	// The raw data is read back by the grid partitioning, so it is always JSONL.
+	output, err := newSampleWriter(file, "jsonl")
+	if err != nil {
+		return err
+	}
 
-	for i := 0; i < config.NumSamples; i++ {
-		pn := petrinet.GenerateRandomPetriNet(config.NumPlaces, config.NumTransitions)
-		log.Printf("Generated Petri net with %d places and %d transitions", pn.Places, pn.Transitions)
-		pn.Prune()
-		log.Printf("Pruned Petri net")
-		pn.AddTokensRandomly()
-		log.Printf("Added tokens randomly")
-		rg, err := generation.GenerateReachabilityGraph(pn, config.PlaceUpperBound, config.MarksUpperLimit)
+	err = runOrdered(config.NumSamples, workerCount(config), func(i int) sampleBatch {
+		pn, rg, err := generateNet(config, utils.NewRand(config.Seed, int64(i)), i)
+		if err != nil {
+			return sampleBatch{err: err}
+		}
+		labels, err := computeNetLabels(config, pn)
 		if err != nil {
-			log.Printf("Skipping sample %d: error generating reachability graph: %v", i, err)
-			continue
+			return sampleBatch{err: err}
+		}
+		return sampleBatch{samples: []*sample{{PetriNet: pn, ReachabilityGraph: rg, Labels: labels}}}
+	}, func(i int, batch sampleBatch) error {
+		if batch.err != nil {
+			log.Printf("Skipping sample %d: %v", i, batch.err)
+			return nil
 		}
+		if config.PNMLExportDir != "" && len(batch.samples) > 0 {
+			if err := exportPNML(config, i, batch.samples[0]); err != nil {
+				return fmt.Errorf("error exporting sample %d: %w", i, err)
+			}
+		}
+		for _, s := range batch.samples {
+			output.write(s)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return output.close()
+}
+
+// sampleWriter writes samples to an output in one of the supported formats: JSONL, one JSON object
+// per line, a protobuf dataset framed by spn.Writer or an Arrow IPC file with a row per sample.
+// Sharded outputs get the encoded samples instead, or the protobuf messages to encode for Arrow.
+type sampleWriter struct {
+	w      io.Writer
+	format string
+	proto  *spn.Writer
+	arrow  *arrowipc.Writer
+	shards *dataset.Writer
+	// file is the output file the writer owns, if any.
+	file   *os.File
+	record []byte
+}
 
-		if !rg.IsBounded || rg.NumVertices < config.MarksLowerLimit {
-			log.Printf("Skipping sample %d: graph is unbounded or has too few markings", i)
-			continue
+// createOutput creates the output of a dataset: the shards of the output directory, if one is
+// configured, and the file at path otherwise.
+func createOutput(config *Config, path string) (*sampleWriter, error) {
+	if config.OutputDir == "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("error creating output file: %w", err)
+		}
+		sw, err := newSampleWriter(file, config.Format)
+		if err != nil {
+			file.Close()
+			return nil, err
 		}
-		writeSample(file, config.Format, pn, rg, nil, nil, nil, nil)
+		sw.file = file
+		return sw, nil
 	}
-	return nil
+
+	manifestConfig, err := config.manifestConfig()
+	if err != nil {
+		return nil, err
+	}
+	opts := dataset.Options{
+		Format:      config.Format,
+		Extension:   "jsonl",
+		Compression: config.Compression,
+		MaxSamples:  config.ShardMaxSamples,
+		MaxBytes:    config.ShardMaxBytes,
+		Config:      manifestConfig,
+	}
+	switch config.Format {
+	case "protobuf":
+		// Every shard is a protobuf dataset of its own.
+		opts.Extension = "pb"
+		opts.Header = func(samples int) []byte {
+			count := spn.UnknownCount
+			if samples >= 0 {
+				count = uint64(samples)
+			}
+			return spn.Header{Version: spn.SchemaVersion, Count: count}.Encode()
+		}
+	case "arrow":
+		// Every shard is an Arrow IPC file of its own.
+		opts.Extension = "arrow"
+		opts.NewEncoder = func(w io.Writer) (dataset.Encoder, error) {
+			arrowWriter, err := arrowipc.NewWriter(w, (&spn.SPNData{}).ProtoReflect().Descriptor(), arrowipc.DefaultBatchSize)
+			return arrowEncoder{arrowWriter}, err
+		}
+	}
+	shards, err := dataset.NewWriter(config.OutputDir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error creating output directory: %w", err)
+	}
+	return &sampleWriter{format: config.Format, shards: shards}, nil
 }
 
-// writeSample writes a sample to the output file in the specified format.
-func writeSample(writer io.Writer, format string, pn *petrinet.PetriNet, rg *generation.ReachabilityGraph, lambdaValues, steadyStateProbs, avgMarkings []float64, markingDensities [][]float64) {
+// reportPath returns the path of the statistics report: next to the output file, or in the
+// output directory.
+func reportPath(config *Config) string {
+	if config.OutputDir != "" {
+		return filepath.Join(config.OutputDir, "report.html")
+	}
+	return config.OutputFile + ".html"
+}
+
+// newSampleWriter returns a writer of samples to w in the given format. For the protobuf format it
+// writes the header of the dataset, and for the Arrow format the schema.
+func newSampleWriter(w io.Writer, format string) (*sampleWriter, error) {
+	sw := &sampleWriter{w: w, format: format}
 	switch format {
 	case "jsonl":
-		result := map[string]interface{}{
-			"petri_net":          pn,
-			"reachability_graph": rg,
-			"lambda_values":      lambdaValues,
-			"steady_state_probs": steadyStateProbs,
-			"average_markings":   avgMarkings,
-			"marking_densities":  markingDensities,
-		}
-		data, err := json.Marshal(result)
+	case "protobuf":
+		protoWriter, err := spn.NewWriter(w)
 		if err != nil {
-			log.Printf("Skipping sample: error marshalling to JSON: %v", err)
-			return
+			return nil, fmt.Errorf("error writing dataset header: %w", err)
 		}
-		fmt.Fprintln(writer, string(data))
-	case "protobuf":
-		spnData := &spn.SPNData{
-			PetriNet: &spn.PetriNet{
-				Places:      int32(pn.Places),
-				Transitions: int32(pn.Transitions),
-				Matrix:      toInt32Slice(pn.Matrix),
-			},
-			ReachabilityGraph: &spn.ReachabilityGraph{
-				Vertices:       toProtoVertices(rg),
-				Edges:          toProtoEdges(rg),
-				ArcTransitions: toInt32Slice(rg.ArcTransitions),
-			},
-			LambdaValues:     lambdaValues,
-			SteadyStateProbs: steadyStateProbs,
-			AverageMarkings:  avgMarkings,
-			MarkingDensities: toProtoMarkingDensities(markingDensities),
-		}
-		data, err := proto.Marshal(spnData)
+		sw.proto = protoWriter
+	case "arrow":
+		arrowWriter, err := arrowipc.NewWriter(w, (&spn.SPNData{}).ProtoReflect().Descriptor(), arrowipc.DefaultBatchSize)
 		if err != nil {
-			log.Printf("Skipping sample: error marshalling to protobuf: %v", err)
+			return nil, fmt.Errorf("error writing Arrow schema: %w", err)
+		}
+		sw.arrow = arrowWriter
+	default:
+		return nil, fmt.Errorf("unsupported output format: %s", format)
+	}
+	return sw, nil
+}
+
+// write writes a sample to the output. Samples that cannot be encoded are logged and skipped.
+// Raw samples without analysis results are written with empty labels.
+func (sw *sampleWriter) write(s *sample) {
+	if sw.shards != nil && sw.format == "arrow" {
+		if err := sw.shards.Encode(toProtoSample(s)); err != nil {
+			log.Printf("Skipping sample: error writing to file: %v", err)
+		}
+		return
+	}
+	if sw.shards != nil {
+		var err error
+		if sw.format == "protobuf" {
+			sw.record, err = spn.AppendRecord(sw.record[:0], toProtoSample(s))
+		} else {
+			sw.record, err = appendJSONRecord(sw.record[:0], toJSONSample(s))
+		}
+		if err != nil {
+			log.Printf("Skipping sample: error marshalling sample: %v", err)
 			return
 		}
-		if _, err := writer.Write(data); err != nil {
+		if err := sw.shards.Write(sw.record); err != nil {
 			log.Printf("Skipping sample: error writing to file: %v", err)
 		}
-	default:
-		log.Fatalf("Unsupported output format: %s", format)
+		return
 	}
+	if sw.proto != nil {
+		if err := sw.proto.Write(toProtoSample(s)); err != nil {
+			log.Printf("Skipping sample: error writing to file: %v", err)
+		}
+		return
+	}
+	if sw.arrow != nil {
+		if err := sw.arrow.Write(toProtoSample(s)); err != nil {
+			log.Printf("Skipping sample: error writing to file: %v", err)
+		}
+		return
+	}
+	data, err := json.Marshal(toJSONSample(s))
+	if err != nil {
+		log.Printf("Skipping sample: error marshalling to JSON: %v", err)
+		return
+	}
+	fmt.Fprintln(sw.w, string(data))
+}
 
-	fmt.Println("Dataset generation complete.")
+// close finishes the output. For the protobuf format it flushes the samples and records their
+// number in the dataset header when the output can seek, and for the Arrow format it writes the
+// last record batch and the footer; sharded outputs get their manifest.
+func (sw *sampleWriter) close() error {
+	if sw.shards != nil {
+		if err := sw.shards.Close(); err != nil {
+			return fmt.Errorf("error finishing dataset: %w", err)
+		}
+		sw.shards = nil
+		return nil
+	}
+	if sw.proto != nil {
+		if err := sw.proto.Close(); err != nil {
+			return fmt.Errorf("error finishing protobuf dataset: %w", err)
+		}
+	}
+	if sw.arrow != nil {
+		if err := sw.arrow.Close(); err != nil {
+			return fmt.Errorf("error finishing Arrow file: %w", err)
+		}
+	}
+	if sw.file != nil {
+		err := sw.file.Close()
+		sw.file = nil
+		if err != nil {
+			return fmt.Errorf("error closing output file: %w", err)
+		}
+	}
+	return nil
 }
 
-// toProtoVertices converts the vertices of a reachability graph to the protobuf format.
-func toProtoVertices(rg *generation.ReachabilityGraph) []*spn.Vertex {
-	var protoVertices []*spn.Vertex
-	for i := 0; i < rg.NumVertices; i++ {
-		v := rg.Vertex(i)
-		protoVertices = append(protoVertices, &spn.Vertex{Marking: toInt32Slice(v)})
+// abort closes the output file of a writer that was not closed, after an error.
+func (sw *sampleWriter) abort() {
+	if sw.file != nil {
+		sw.file.Close()
 	}
-	return protoVertices
 }
 
-// toProtoEdges converts the edges of a reachability graph to the protobuf format.
-func toProtoEdges(rg *generation.ReachabilityGraph) []*spn.Edge {
-	var protoEdges []*spn.Edge
-	for i := 0; i < rg.NumEdges; i++ {
-		e := rg.Edge(i)
-		protoEdges = append(protoEdges, &spn.Edge{Src: int32(e[0]), Dest: int32(e[1])})
+// arrowEncoder encodes the samples of an Arrow shard, given as protobuf messages.
+type arrowEncoder struct {
+	*arrowipc.Writer
+}
+
+// Encode appends a sample to the shard.
+func (e arrowEncoder) Encode(sample interface{}) error {
+	return e.Write(sample.(*spn.SPNData))
+}
+
+// appendJSONRecord appends a JSONL record, with its newline, to b.
+func appendJSONRecord(b []byte, v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return b, err
+	}
+	return append(append(b, data...), '\n'), nil
+}
+
+// sampleAnalysis returns the analysis results of a sample, or empty results for raw and simulated
+// samples.
+func sampleAnalysis(s *sample) *analysis.SPNAnalysisResult {
+	if s.Analysis == nil {
+		return &analysis.SPNAnalysisResult{}
+	}
+	return s.Analysis
+}
+
+// toJSONSample converts a sample to the record of the JSONL format.
+func toJSONSample(s *sample) map[string]interface{} {
+	pn, rg, lambdaValues := s.PetriNet, s.ReachabilityGraph, s.LambdaValues
+	analysisResult := sampleAnalysis(s)
+	result := map[string]interface{}{
+		"petri_net":          pn,
+		"reachability_graph": rg,
+		"lambda_values":      lambdaValues,
+		"steady_state_probs": analysisResult.SteadyStateProbs,
+		"average_markings":   analysisResult.AverageMarkings,
+		"marking_densities":  analysisResult.MarkingDensities,
+		"throughputs":        analysisResult.Throughputs,
+		"token_flow_rates":   analysisResult.TokenFlowRates,
+		"sojourn_times":      analysisResult.SojournTimes,
+	}
+	if analysisResult.ResponseTimes != nil {
+		result["response_times"] = analysisResult.ResponseTimes
+	}
+	if analysisResult.Transient != nil {
+		result["transient"] = analysisResult.Transient
+	}
+	if analysisResult.Rewards != nil {
+		result["rewards"] = analysisResult.Rewards
+	}
+	if chain := analysisResult.Chain; chain != nil && !chain.IsIrreducible() {
+		result["chain_structure"] = chain
+	}
+	if analysisResult.SolverStats != nil {
+		result["solver_stats"] = analysisResult.SolverStats
+	}
+	if s.Simulation != nil {
+		result["simulation"] = s.Simulation
+	}
+	if s.Labels.Invariants != nil {
+		result["invariants"] = s.Labels.Invariants
+	}
+	if s.Labels.SiphonsAndTraps != nil {
+		result["siphons_and_traps"] = s.Labels.SiphonsAndTraps
+	}
+	return result
+}
+
+// toProtoSample converts a sample to the protobuf format.
+func toProtoSample(s *sample) *spn.SPNData {
+	pn, rg, lambdaValues := s.PetriNet, s.ReachabilityGraph, s.LambdaValues
+	analysisResult := sampleAnalysis(s)
+	return &spn.SPNData{
+		PetriNet:          pn.ToProto(),
+		ReachabilityGraph: toProtoReachabilityGraph(rg),
+		LambdaValues:      lambdaValues,
+		SteadyStateProbs:  analysisResult.SteadyStateProbs,
+		AverageMarkings:   analysisResult.AverageMarkings,
+		MarkingDensities:  toProtoMarkingDensities(analysisResult.MarkingDensities),
+		Invariants:        toProtoInvariants(s.Labels.Invariants),
+		SiphonsAndTraps:   toProtoSiphonsAndTraps(s.Labels.SiphonsAndTraps),
+		Transient:         toProtoTransient(analysisResult.Transient),
+		Throughputs:       analysisResult.Throughputs,
+		TokenFlowRates:    analysisResult.TokenFlowRates,
+		SojournTimes:      analysisResult.SojournTimes,
+		ResponseTimes:     toProtoResponseTimes(analysisResult.ResponseTimes),
+		Rewards:           toProtoRewards(analysisResult.Rewards),
+		ChainStructure:    toProtoChainStructure(analysisResult.Chain),
+		SolverStats:       toProtoSolverStats(analysisResult.SolverStats),
+		Simulation:        toProtoSimulation(s.Simulation),
+	}
+}
+
+// toProtoInvariants converts the structural labels of a net to the protobuf format.
+func toProtoInvariants(invariants *structural.Invariants) *spn.Invariants {
+	if invariants == nil {
+		return nil
+	}
+	toSemiflows := func(semiflows [][]int) []*spn.Semiflow {
+		result := make([]*spn.Semiflow, len(semiflows))
+		for i, s := range semiflows {
+			result[i] = &spn.Semiflow{Weights: spn.ToInt32Slice(s)}
+		}
+		return result
+	}
+	return &spn.Invariants{
+		PSemiflows:   toSemiflows(invariants.PSemiflows),
+		TSemiflows:   toSemiflows(invariants.TSemiflows),
+		Conservative: invariants.Conservative,
+		Consistent:   invariants.Consistent,
+	}
+}
+
+// toProtoSiphonsAndTraps converts the deadlock labels of a net to the protobuf format.
+func toProtoSiphonsAndTraps(st *structural.SiphonsAndTraps) *spn.SiphonsAndTraps {
+	if st == nil {
+		return nil
+	}
+	toPlaceSets := func(sets [][]int) []*spn.PlaceSet {
+		result := make([]*spn.PlaceSet, len(sets))
+		for i, s := range sets {
+			result[i] = &spn.PlaceSet{Places: spn.ToInt32Slice(s)}
+		}
+		return result
+	}
+	return &spn.SiphonsAndTraps{
+		MinimalSiphons:   toPlaceSets(st.MinimalSiphons),
+		MinimalTraps:     toPlaceSets(st.MinimalTraps),
+		Ordinary:         st.Ordinary,
+		FreeChoice:       st.FreeChoice,
+		CommonerProperty: st.CommonerProperty,
+	}
+}
+
+// toProtoResponseTimes converts the response times of a sample to the protobuf format.
+func toProtoResponseTimes(times []analysis.ResponseTime) []*spn.ResponseTime {
+	var result []*spn.ResponseTime
+	for _, r := range times {
+		result = append(result, &spn.ResponseTime{Place: int32(r.Place), Transition: int32(r.Transition), ResponseTime: r.Time})
+	}
+	return result
+}
+
+// toProtoChainStructure converts the component decomposition of a reducible chain to the protobuf
+// format. Irreducible chains are left out.
+func toProtoChainStructure(chain *analysis.ChainStructure) *spn.ChainStructure {
+	if chain == nil || chain.IsIrreducible() {
+		return nil
+	}
+	bottom := make([]*spn.MarkingSet, len(chain.BottomComponents))
+	for i, states := range chain.BottomComponents {
+		bottom[i] = &spn.MarkingSet{Markings: spn.ToInt32Slice(states)}
+	}
+	return &spn.ChainStructure{
+		NumComponents:           int32(chain.NumComponents),
+		BottomComponents:        bottom,
+		DeadMarkings:            spn.ToInt32Slice(chain.DeadMarkings),
+		AbsorptionProbabilities: chain.AbsorptionProbabilities,
+	}
+}
+
+// toProtoSolverStats converts the accuracy metrics of a steady-state solution to the protobuf
+// format.
+func toProtoSolverStats(stats *analysis.SolverStats) *spn.SolverStats {
+	if stats == nil {
+		return nil
+	}
+	return &spn.SolverStats{
+		Method:          stats.Method,
+		Iterations:      int32(stats.Iterations),
+		Residual:        stats.Residual,
+		Converged:       stats.Converged,
+		ClampedMass:     stats.ClampedMass,
+		ConditionNumber: stats.ConditionNumber,
+	}
+}
+
+// toProtoRewards converts the reward values of a sample to the protobuf format.
+func toProtoRewards(values []analysis.RewardValue) []*spn.RewardValue {
+	var result []*spn.RewardValue
+	for _, v := range values {
+		result = append(result, &spn.RewardValue{Name: v.Name, SteadyState: v.SteadyState, Transient: v.Transient})
+	}
+	return result
+}
+
+// toProtoTransient converts the transient distributions of a sample to the protobuf format.
+func toProtoTransient(transient *analysis.TransientResult) *spn.Transient {
+	if transient == nil {
+		return nil
+	}
+	points := make([]*spn.TransientPoint, len(transient.Times))
+	for i, t := range transient.Times {
+		points[i] = &spn.TransientPoint{
+			Time:            t,
+			Probs:           transient.Probs[i],
+			AverageMarkings: transient.AverageMarkings[i],
+		}
+	}
+	return &spn.Transient{Points: points}
+}
+
+// toProtoReachabilityGraph converts a reachability graph to the protobuf format. Simulated samples
+// have no graph.
+func toProtoReachabilityGraph(rg *generation.ReachabilityGraph) *spn.ReachabilityGraph {
+	if rg == nil {
+		return nil
+	}
+	return rg.ToProto()
+}
+
+// toProtoSimulation converts the simulation estimates of a sample to the protobuf format.
+func toProtoSimulation(result *simulation.Result) *spn.Simulation {
+	if result == nil {
+		return nil
+	}
+	toEstimates := func(estimates []simulation.Estimate) []*spn.Estimate {
+		converted := make([]*spn.Estimate, len(estimates))
+		for i, e := range estimates {
+			converted[i] = &spn.Estimate{Mean: e.Mean, HalfWidth: e.HalfWidth}
+		}
+		return converted
+	}
+	densities := make([]*spn.EstimateList, len(result.MarkingDensities))
+	for p, d := range result.MarkingDensities {
+		densities[p] = &spn.EstimateList{Estimates: toEstimates(d)}
+	}
+	return &spn.Simulation{
+		AverageMarkings:  toEstimates(result.AverageMarkings),
+		Throughputs:      toEstimates(result.Throughputs),
+		MarkingDensities: densities,
+		Time:             result.Time,
+		Firings:          int64(result.Firings),
+		Batches:          int32(result.Batches),
+		Confidence:       result.Confidence,
 	}
-	return protoEdges
 }
 
 // toProtoMarkingDensities converts the marking densities to the protobuf format.
@@ -267,12 +886,3 @@ func toProtoMarkingDensities(densities [][]float64) []*spn.MarkingDensity {
 	}
 	return protoDensities
 }
-
-// toInt32Slice converts a slice of ints to a slice of int32s.
-func toInt32Slice(slice []int) []int32 {
-	var result []int32
-	for _, v := range slice {
-		result = append(result, int32(v))
-	}
-	return result
-}
@@ -1,10 +1,27 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"spn-benchmark-ds/internal/pkg/analysis"
+	"spn-benchmark-ds/internal/pkg/arrowipc"
+	"spn-benchmark-ds/internal/pkg/dataset"
+	"spn-benchmark-ds/internal/pkg/generation"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+	"spn-benchmark-ds/internal/pkg/rates"
+	"spn-benchmark-ds/internal/pkg/simulation"
+	"spn-benchmark-ds/internal/pkg/spn"
+	"spn-benchmark-ds/internal/pkg/structural"
 	"strings"
 	"testing"
+
+	"google.golang.org/protobuf/proto"
 )
 
 func TestRun(t *testing.T) {
@@ -188,3 +205,691 @@ output_grid_location: "test_grid_output.jsonl"
 	os.RemoveAll("test_grid")
 	os.Remove("test_grid_output.jsonl")
 }
+
+// testConfig returns the configuration shared by the run tests: nets of 5 places and 3
+// transitions generated with seed 3 and written as JSONL to a temporary directory.
+func testConfig(t *testing.T) *Config {
+	t.Helper()
+	return &Config{
+		NumPlaces:       5,
+		NumTransitions:  3,
+		NumSamples:      5,
+		OutputFile:      filepath.Join(t.TempDir(), "output.jsonl"),
+		Format:          "jsonl",
+		PlaceUpperBound: 10,
+		MarksLowerLimit: 1,
+		MarksUpperLimit: 100,
+		MinFiringRate:   1,
+		MaxFiringRate:   10,
+		Seed:            3,
+	}
+}
+
+// loadTestConfig loads the configuration of testConfig from a YAML file, with extra appended to
+// it, for tests of options that are read from the configuration file.
+func loadTestConfig(t *testing.T, extra string) *Config {
+	t.Helper()
+	dir := t.TempDir()
+	content := `
+num_places: 5
+num_transitions: 3
+num_samples: 5
+output_file: "` + filepath.Join(dir, "output.jsonl") + `"
+format: "jsonl"
+place_upper_bound: 10
+marks_lower_limit: 1
+marks_upper_limit: 100
+min_firing_rate: 1
+max_firing_rate: 10
+seed: 3
+` + extra
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Error loading config: %v", err)
+	}
+	return config
+}
+
+// runOutput runs the generation with config and returns the contents of its output file.
+func runOutput(t *testing.T, config *Config) []byte {
+	t.Helper()
+	if err := run(config); err != nil {
+		t.Fatalf("Error running generation: %v", err)
+	}
+	content, err := os.ReadFile(config.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	return content
+}
+
+// runRecords runs the generation with config and decodes every record of its JSONL output.
+func runRecords[T any](t *testing.T, config *Config) []T {
+	t.Helper()
+	var records []T
+	for _, line := range strings.Split(strings.TrimSpace(string(runOutput(t, config))), "\n") {
+		if line == "" {
+			continue
+		}
+		var record T
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Error decoding output record: %v", err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestRunIsReproducibleWithSeed(t *testing.T) {
+	config := testConfig(t)
+	config.EnableTransformations = true
+	config.MaxTransformsPerSample = 2
+	config.Seed = 1234
+
+	var outputs [2][]byte
+	for i := range outputs {
+		outputs[i] = runOutput(t, config)
+	}
+
+	if len(outputs[0]) == 0 {
+		t.Fatalf("Output file is empty")
+	}
+	if string(outputs[0]) != string(outputs[1]) {
+		t.Errorf("Expected identical datasets for the same seed")
+	}
+}
+
+func TestRunIsIndependentOfWorkerCount(t *testing.T) {
+	config := testConfig(t)
+	config.NumSamples = 20
+	config.EnableTransformations = true
+	config.MaxTransformsPerSample = 2
+	config.Seed = 99
+
+	var outputs []string
+	for _, workers := range []int{1, 4} {
+		config.Workers = workers
+		outputs = append(outputs, string(runOutput(t, config)))
+	}
+
+	if len(outputs[0]) == 0 {
+		t.Fatalf("Output file is empty")
+	}
+	if outputs[0] != outputs[1] {
+		t.Errorf("Expected identical datasets for 1 and 4 workers")
+	}
+}
+
+func TestRunWritesVariationNets(t *testing.T) {
+	config := testConfig(t)
+	config.EnableTransformations = true
+	config.MaxTransformsPerSample = 2
+
+	type record struct {
+		PetriNet struct {
+			InitialMarking []int
+		} `json:"petri_net"`
+		ReachabilityGraph struct {
+			Vertices       []int
+			VerticesStride int
+			NumVertices    int
+		} `json:"reachability_graph"`
+		SteadyStateProbs []float64 `json:"steady_state_probs"`
+		AverageMarkings  []float64 `json:"average_markings"`
+	}
+	for _, r := range runRecords[record](t, config) {
+		// Each record pairs a variation's net with its own graph and results, so the graph starts
+		// from the net's initial marking and the average markings follow from its markings.
+		rg := r.ReachabilityGraph
+		if !reflect.DeepEqual(rg.Vertices[:rg.VerticesStride], r.PetriNet.InitialMarking) {
+			t.Errorf("Expected the graph to start from the initial marking %v, got %v", r.PetriNet.InitialMarking, rg.Vertices[:rg.VerticesStride])
+		}
+		if len(r.SteadyStateProbs) != rg.NumVertices {
+			t.Fatalf("Expected %d steady-state probabilities, got %d", rg.NumVertices, len(r.SteadyStateProbs))
+		}
+		for p, average := range r.AverageMarkings {
+			var want float64
+			for k, prob := range r.SteadyStateProbs {
+				want += prob * float64(rg.Vertices[k*rg.VerticesStride+p])
+			}
+			if math.Abs(average-want) > 1e-9 {
+				t.Errorf("Expected average marking %g of place %d, got %g", want, p, average)
+			}
+		}
+	}
+}
+
+func TestRunRejectsUnknownSolver(t *testing.T) {
+	config := testConfig(t)
+	config.Solver = "cholesky"
+	if err := run(config); err == nil {
+		t.Errorf("Expected an error for an unknown solver")
+	}
+}
+
+func TestTruncationReason(t *testing.T) {
+	// T1 keeps its token in P1 and adds one to P2 on every firing.
+	unbounded := petrinet.NewPetriNet(2, 1)
+	unbounded.Matrix = []int{
+		1, 1, 1,
+		0, 1, 0,
+	}
+	unbounded.InitialMarking = []int{1, 0}
+	// T1 and T2 move ten tokens back and forth between P1 and P2.
+	bounded := petrinet.NewPetriNet(2, 2)
+	bounded.Matrix = []int{
+		1, 0, 0, 1, 10,
+		0, 1, 1, 0, 0,
+	}
+	bounded.InitialMarking = []int{10, 0}
+
+	tests := []struct {
+		name              string
+		pn                *petrinet.PetriNet
+		coverabilityLimit int
+		want              string
+	}{
+		{"unbounded", unbounded, 100, "net is unbounded in places [1]"},
+		{"bounded", bounded, 100, "net is bounded with place bounds [10 10]"},
+		{"undecided", bounded, 2, "boundedness is undecided"},
+		{"disabled", unbounded, 0, "graph is truncated: place upper limit exceeded"},
+	}
+	for _, tt := range tests {
+		config := &Config{PlaceUpperBound: 5, MarksUpperLimit: 100, CoverabilityLimit: tt.coverabilityLimit}
+		rg, err := generation.GenerateReachabilityGraph(tt.pn, config.PlaceUpperBound, config.MarksUpperLimit)
+		if err != nil {
+			t.Fatalf("%s: error generating reachability graph: %v", tt.name, err)
+		}
+		if rg.IsBounded {
+			t.Fatalf("%s: expected a truncated reachability graph", tt.name)
+		}
+		if err := truncationReason(config, tt.pn, rg); !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected reason containing %q, got %q", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestRunWithStructuralLabels(t *testing.T) {
+	config := testConfig(t)
+	config.EnableInvariants = true
+	config.EnableSiphonsAndTraps = true
+
+	type record struct {
+		Invariants      *structural.Invariants      `json:"invariants"`
+		SiphonsAndTraps *structural.SiphonsAndTraps `json:"siphons_and_traps"`
+	}
+	for _, r := range runRecords[record](t, config) {
+		if r.Invariants == nil || r.SiphonsAndTraps == nil {
+			t.Fatalf("Expected every sample to carry invariants, siphons and traps")
+		}
+		for _, y := range r.Invariants.PSemiflows {
+			if len(y) != config.NumPlaces {
+				t.Errorf("Expected P-semiflows over %d places, got %v", config.NumPlaces, y)
+			}
+		}
+	}
+}
+
+func TestRunWithTransientLabels(t *testing.T) {
+	config := testConfig(t)
+	config.TransientTimes = []float64{0.5, 0}
+
+	type record struct {
+		SteadyStateProbs []float64                 `json:"steady_state_probs"`
+		Transient        *analysis.TransientResult `json:"transient"`
+	}
+	for _, r := range runRecords[record](t, config) {
+		if r.Transient == nil || len(r.Transient.Probs) != 2 || len(r.Transient.AverageMarkings) != 2 {
+			t.Fatalf("Expected every sample to carry transient labels for two time points, got %+v", r.Transient)
+		}
+		if !slices.Equal(r.Transient.Times, config.TransientTimes) {
+			t.Errorf("Expected the time points in the configured order, got %v", r.Transient.Times)
+		}
+		for _, probs := range r.Transient.Probs {
+			if len(probs) != len(r.SteadyStateProbs) {
+				t.Errorf("Expected %d transient probabilities, got %d", len(r.SteadyStateProbs), len(probs))
+			}
+		}
+	}
+
+	config.TransientTimes = []float64{-1}
+	if err := run(config); err == nil {
+		t.Errorf("Expected an error for a negative transient time point")
+	}
+}
+
+func TestRunWithPerformanceMeasures(t *testing.T) {
+	config := testConfig(t)
+	config.ResponseTimePairs = []analysis.PlaceTransitionPair{{Place: 4, Transition: 2}}
+
+	type record struct {
+		Throughputs    []float64               `json:"throughputs"`
+		TokenFlowRates []float64               `json:"token_flow_rates"`
+		SojournTimes   []float64               `json:"sojourn_times"`
+		ResponseTimes  []analysis.ResponseTime `json:"response_times"`
+	}
+	for _, r := range runRecords[record](t, config) {
+		if len(r.Throughputs) != config.NumTransitions || len(r.TokenFlowRates) != config.NumPlaces || len(r.SojournTimes) != config.NumPlaces {
+			t.Fatalf("Expected per-transition throughputs and per-place flows, got %+v", r)
+		}
+		if len(r.ResponseTimes) != 1 || r.ResponseTimes[0].Place != 4 || r.ResponseTimes[0].Transition != 2 {
+			t.Errorf("Expected the response time of the configured pair, got %+v", r.ResponseTimes)
+		}
+	}
+
+	config.ResponseTimePairs = []analysis.PlaceTransitionPair{{Place: 5, Transition: 0}}
+	if err := run(config); err == nil {
+		t.Errorf("Expected an error for a response time pair out of range")
+	}
+}
+
+func TestRunWithRewards(t *testing.T) {
+	config := loadTestConfig(t, `
+transient_times: [1]
+rewards:
+  - name: tokens
+    rate: "p0 + p1 + p2 + p3 + p4"
+  - name: firings
+    impulses: {0: "1", 1: "1", 2: "1"}
+`)
+	if len(config.Rewards) != 2 || config.Rewards[1].Impulses[2] != "1" {
+		t.Fatalf("Expected two reward structures, got %+v", config.Rewards)
+	}
+
+	type record struct {
+		Rewards []analysis.RewardValue `json:"rewards"`
+	}
+	for _, r := range runRecords[record](t, config) {
+		if len(r.Rewards) != 2 || r.Rewards[0].Name != "tokens" || len(r.Rewards[0].Transient) != 1 {
+			t.Fatalf("Expected two rewards with one transient value each, got %+v", r.Rewards)
+		}
+	}
+
+	config.ImmediateTransitionProb = 0.2
+	if err := run(config); err == nil {
+		t.Errorf("Expected an error for impulses on transitions that may be immediate")
+	}
+
+	config.ImmediateTransitionProb = 0
+	config.Rewards[0].Rate = "p5"
+	if err := run(config); err == nil {
+		t.Errorf("Expected an error for a reward on a place out of range")
+	}
+}
+
+func TestRunReducibleChainPolicy(t *testing.T) {
+	config := testConfig(t)
+	config.NumSamples = 10
+
+	type record struct {
+		ChainStructure *analysis.ChainStructure `json:"chain_structure"`
+	}
+	for _, policy := range []string{"", analysis.ReduciblePerBSCC} {
+		config.ReducibleChainPolicy = policy
+		reducible := 0
+		for _, r := range runRecords[record](t, config) {
+			if r.ChainStructure == nil {
+				continue
+			}
+			reducible++
+			if len(r.ChainStructure.AbsorptionProbabilities) != len(r.ChainStructure.BottomComponents) {
+				t.Errorf("Expected an absorption probability per bottom component, got %+v", r.ChainStructure)
+			}
+		}
+		if reducible == 0 {
+			t.Errorf("Expected some reducible chains with policy %q", policy)
+		}
+	}
+
+	config.ReducibleChainPolicy = analysis.ReducibleReject
+	for _, r := range runRecords[record](t, config) {
+		if r.ChainStructure != nil {
+			t.Errorf("Expected reducible chains to be rejected, got %+v", r.ChainStructure)
+		}
+	}
+
+	config.ReducibleChainPolicy = "ignore"
+	if err := run(config); err == nil {
+		t.Errorf("Expected an error for an unknown policy")
+	}
+}
+
+func TestRunSolverAccuracyLimits(t *testing.T) {
+	config := testConfig(t)
+	config.NumSamples = 10
+
+	type record struct {
+		SolverStats *analysis.SolverStats `json:"solver_stats"`
+	}
+	// Chains whose bottom components are single markings are solved without a linear system and
+	// have no condition number.
+	solved := 0
+	for _, r := range runRecords[record](t, config) {
+		if r.SolverStats == nil || r.SolverStats.Method != analysis.MethodDense {
+			t.Fatalf("Expected dense solver statistics, got %+v", r.SolverStats)
+		}
+		if r.SolverStats.ConditionNumber >= 1 {
+			solved++
+		}
+	}
+	if solved == 0 {
+		t.Fatalf("Expected some samples with a condition number")
+	}
+
+	// No system is conditioned better than the identity, so only samples without one remain.
+	config.SolverMaxConditionNumber = 0.5
+	for _, r := range runRecords[record](t, config) {
+		if r.SolverStats.ConditionNumber > config.SolverMaxConditionNumber {
+			t.Errorf("Expected samples above the condition number limit to be rejected, got %+v", r.SolverStats)
+		}
+	}
+
+	config.Solver = analysis.MethodPower
+	if err := run(config); err == nil {
+		t.Errorf("Expected an error for a condition number limit with an iterative solver")
+	}
+	config.Solver = ""
+	config.SolverMaxResidual = -1
+	if err := run(config); err == nil {
+		t.Errorf("Expected an error for a negative accuracy limit")
+	}
+}
+
+func TestRunWithSimulation(t *testing.T) {
+	config := testConfig(t)
+	config.NumTransitions = 4
+	config.NumSamples = 20
+	config.MarksUpperLimit = 6
+	config.Seed = 5
+	config.SimulationMode = simulationFallback
+	config.SimulationTime = 2000
+	config.SimulationWarmup = 10
+	config.SimulationBatches = 10
+	config.SimulationConfidence = 0.9
+
+	type record struct {
+		ReachabilityGraph *generation.ReachabilityGraph `json:"reachability_graph"`
+		AverageMarkings   []float64                     `json:"average_markings"`
+		Simulation        *simulation.Result            `json:"simulation"`
+	}
+	simulated := 0
+	for _, r := range runRecords[record](t, config) {
+		if r.Simulation == nil {
+			if r.ReachabilityGraph == nil {
+				t.Errorf("Expected a reachability graph for a sample that was not simulated")
+			}
+			continue
+		}
+		simulated++
+		if r.ReachabilityGraph != nil || r.AverageMarkings != nil {
+			t.Errorf("Expected simulated samples to have no numeric results")
+		}
+		if len(r.Simulation.AverageMarkings) != config.NumPlaces || r.Simulation.Batches != 10 || r.Simulation.Confidence != 0.9 {
+			t.Errorf("Expected estimates for %d places in 10 batches at 0.9, got %+v", config.NumPlaces, r.Simulation)
+		}
+	}
+	if simulated == 0 {
+		t.Errorf("Expected some nets beyond the marking limit to be simulated")
+	}
+
+	config.SimulationMode = simulationAlways
+	config.MarksUpperLimit = 100
+	for _, r := range runRecords[record](t, config) {
+		if r.Simulation == nil || r.ReachabilityGraph == nil || r.AverageMarkings == nil {
+			t.Fatalf("Expected every sample to be solved and simulated")
+		}
+	}
+
+	config.Format = "protobuf"
+	config.SimulationMode = simulationFallback
+	config.MarksUpperLimit = 6
+	if err := run(config); err != nil {
+		t.Fatalf("Error running generation: %v", err)
+	}
+
+	config.SimulationMode = "sometimes"
+	if err := run(config); err == nil {
+		t.Errorf("Expected an error for an unknown simulation mode")
+	}
+}
+
+func TestRunWithNonExponentialDelays(t *testing.T) {
+	config := loadTestConfig(t, `
+simulation_time: 1000
+non_exponential_prob: 0.5
+delay_distributions:
+  - kind: deterministic
+  - kind: erlang
+    stages: 3
+  - kind: lognormal
+    scv: 0.5
+`)
+	if len(config.DelayDistributions) != 3 || config.DelayDistributions[1].Stages != 3 || config.DelayDistributions[2].SCV != 0.5 {
+		t.Fatalf("Expected three delay distributions, got %+v", config.DelayDistributions)
+	}
+	config.NumTransitions = 4
+	config.NumSamples = 10
+
+	type record struct {
+		PetriNet         *petrinet.PetriNet `json:"petri_net"`
+		SteadyStateProbs []float64          `json:"steady_state_probs"`
+		Simulation       *simulation.Result `json:"simulation"`
+	}
+	nonMarkovian := 0
+	for _, r := range runRecords[record](t, config) {
+		if !r.PetriNet.HasNonExponentialDelays() {
+			if r.SteadyStateProbs == nil || r.Simulation != nil {
+				t.Errorf("Expected Markovian nets to be solved numerically only")
+			}
+			continue
+		}
+		nonMarkovian++
+		if r.SteadyStateProbs != nil || r.Simulation == nil {
+			t.Errorf("Expected nets with non-exponential delays to be simulated only")
+		}
+	}
+	if nonMarkovian == 0 {
+		t.Errorf("Expected some nets with non-exponential delays")
+	}
+
+	config.DelayDistributions = []petrinet.Delay{{Kind: petrinet.DelayUniform, Spread: 2}}
+	if err := run(config); err == nil {
+		t.Errorf("Expected an error for an invalid delay distribution")
+	}
+}
+
+func TestRunWithFiringRateDistribution(t *testing.T) {
+	config := loadTestConfig(t, `
+firing_rates:
+  distribution: "log_uniform"
+  min: 0.001
+  max: 1000
+  ranges:
+    - {min: 5, max: 6}
+`)
+	if config.FiringRates.Distribution != rates.LogUniform || len(config.FiringRates.Ranges) != 1 {
+		t.Fatalf("Expected a log-uniform distribution with one range, got %+v", config.FiringRates)
+	}
+
+	type record struct {
+		LambdaValues []float64 `json:"lambda_values"`
+	}
+	for _, r := range runRecords[record](t, config) {
+		if lambda := r.LambdaValues[0]; lambda < 5 || lambda > 6 {
+			t.Errorf("Expected the rate of transition 0 in the configured range [5, 6], got %g", lambda)
+		}
+	}
+
+	config.FiringRates.Distribution = "normal"
+	if err := run(config); err == nil {
+		t.Errorf("Expected an error for an unknown rate distribution")
+	}
+}
+
+func TestRunProtobufMatchesJSONL(t *testing.T) {
+	config := testConfig(t)
+	config.NumTransitions = 4
+	config.NumSamples = 8
+	config.EnableTransformations = true
+	config.MaxTransformsPerSample = 2
+	config.Seed = 11
+	lines := strings.Split(strings.TrimSpace(string(runOutput(t, config))), "\n")
+
+	dir := filepath.Dir(config.OutputFile)
+	protoFile, arrowFile := filepath.Join(dir, "output.pb"), filepath.Join(dir, "output.arrow")
+	config.Format = "protobuf"
+	config.OutputFile = protoFile
+	if err := run(config); err != nil {
+		t.Fatalf("Error running generation: %v", err)
+	}
+	config.Format = "arrow"
+	config.OutputFile = arrowFile
+	if err := run(config); err != nil {
+		t.Fatalf("Error running generation: %v", err)
+	}
+
+	file, err := os.Open(protoFile)
+	if err != nil {
+		t.Fatalf("Failed to open output file: %v", err)
+	}
+	defer file.Close()
+	reader, err := spn.NewReader(file)
+	if err != nil {
+		t.Fatalf("Error reading dataset header: %v", err)
+	}
+	if reader.Header().Count != uint64(len(lines)) {
+		t.Fatalf("Expected the header to count %d samples, got %d", len(lines), reader.Header().Count)
+	}
+	arrowData, err := os.ReadFile(arrowFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	arrowReader, err := arrowipc.NewReader(bytes.NewReader(arrowData), int64(len(arrowData)), (&spn.SPNData{}).ProtoReflect().Descriptor())
+	if err != nil {
+		t.Fatalf("Error reading Arrow file: %v", err)
+	}
+	for i, line := range lines {
+		var record struct {
+			PetriNet          *petrinet.PetriNet            `json:"petri_net"`
+			ReachabilityGraph *generation.ReachabilityGraph `json:"reachability_graph"`
+			LambdaValues      []float64                     `json:"lambda_values"`
+			SteadyStateProbs  []float64                     `json:"steady_state_probs"`
+			Throughputs       []float64                     `json:"throughputs"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Error decoding output record: %v", err)
+		}
+		data, err := reader.Next()
+		if err != nil {
+			t.Fatalf("Error reading sample %d: %v", i, err)
+		}
+		if int(data.PetriNet.Places) != record.PetriNet.Places || len(data.PetriNet.Matrix) != len(record.PetriNet.Matrix) {
+			t.Fatalf("Expected sample %d to hold the same net in both formats", i)
+		}
+		for j, v := range record.PetriNet.Matrix {
+			if int(data.PetriNet.Matrix[j]) != v {
+				t.Fatalf("Expected sample %d to hold the same net in both formats", i)
+			}
+		}
+		if len(data.ReachabilityGraph.Vertices) != record.ReachabilityGraph.NumVertices {
+			t.Errorf("Expected sample %d to hold %d markings, got %d", i, record.ReachabilityGraph.NumVertices, len(data.ReachabilityGraph.Vertices))
+		}
+		expectEqualFloats(t, "lambda values", record.LambdaValues, data.LambdaValues)
+		expectEqualFloats(t, "steady-state probabilities", record.SteadyStateProbs, data.SteadyStateProbs)
+		expectEqualFloats(t, "throughputs", record.Throughputs, data.Throughputs)
+
+		row := &spn.SPNData{}
+		if err := arrowReader.Next(row); err != nil {
+			t.Fatalf("Error reading row %d: %v", i, err)
+		}
+		if !proto.Equal(row, data) {
+			t.Errorf("Expected row %d of the Arrow file to hold the protobuf sample", i)
+		}
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("Expected the dataset to end after %d samples, got %v", len(lines), err)
+	}
+	if err := arrowReader.Next(&spn.SPNData{}); err != io.EOF {
+		t.Errorf("Expected the Arrow file to end after %d rows, got %v", len(lines), err)
+	}
+}
+
+// expectEqualFloats checks that two slices hold the same values.
+func expectEqualFloats(t *testing.T, name string, expected, got []float64) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d %s, got %d", len(expected), name, len(got))
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected %s[%d] to be %g, got %g", name, i, expected[i], got[i])
+		}
+	}
+}
+
+func TestRunShardedOutput(t *testing.T) {
+	config := loadTestConfig(t, `
+enable_statistics_report: true
+shard_max_samples: 3
+`)
+	config.NumSamples = 10
+	for _, format := range []string{"jsonl", "protobuf", "arrow"} {
+		for _, compression := range []string{dataset.CompressionNone, dataset.CompressionGzip, dataset.CompressionZstd} {
+			config.Format, config.Compression = format, compression
+			config.OutputDir = filepath.Join(t.TempDir(), "dataset")
+			if err := run(config); err != nil {
+				t.Fatalf("Error running generation: %v", err)
+			}
+			if _, err := os.Stat(config.OutputFile); !os.IsNotExist(err) {
+				t.Fatalf("Expected no output file besides the output directory")
+			}
+			if _, err := os.Stat(filepath.Join(config.OutputDir, "report.html")); err != nil {
+				t.Errorf("Expected the report in the output directory: %v", err)
+			}
+
+			manifest, err := dataset.ReadManifest(config.OutputDir)
+			if err != nil {
+				t.Fatalf("Error reading manifest: %v", err)
+			}
+			var manifestConfig struct {
+				Seed            int64 `json:"seed"`
+				ShardMaxSamples int   `json:"shard_max_samples"`
+			}
+			if err := json.Unmarshal(manifest.Config, &manifestConfig); err != nil || manifestConfig.Seed != 3 || manifestConfig.ShardMaxSamples != 3 {
+				t.Errorf("Expected the manifest to record the configuration, got %s", manifest.Config)
+			}
+			if manifest.Format != format || manifest.Compression != compression || manifest.Samples == 0 {
+				t.Fatalf("Expected a %s manifest with %s compression and samples, got %+v", format, compression, manifest)
+			}
+			if samples := countSamples(t, config.OutputDir); samples != manifest.Samples {
+				t.Errorf("Expected %d samples in the shards, got %d", manifest.Samples, samples)
+			}
+		}
+	}
+
+	config.OutputDir = ""
+	if err := run(config); err == nil {
+		t.Errorf("Expected an error for sharding without an output directory")
+	}
+}
+
+// countSamples returns the number of samples of a dataset file or directory.
+func countSamples(t *testing.T, path string) int {
+	t.Helper()
+	r, err := dataset.Open(path)
+	if err != nil {
+		t.Fatalf("Error opening dataset: %v", err)
+	}
+	defer r.Close()
+	for count := 0; ; count++ {
+		if _, err := r.Next(); err == io.EOF {
+			return count
+		} else if err != nil {
+			t.Fatalf("Error reading dataset: %v", err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+)
+
+// workerCount returns the number of generation workers to use for the given configuration.
+// A non-positive value in the configuration means one worker per CPU.
+func workerCount(config *Config) int {
+	if config.Workers > 0 {
+		return config.Workers
+	}
+	return runtime.NumCPU()
+}
+
+// runOrdered calls produce for every index in [0, n) on up to workers goroutines and hands the
+// results to consume on the calling goroutine, strictly in index order.
+// Workers never run more than a small window ahead of consume, so memory stays bounded even when
+// one sample is much slower than its successors. The first error returned by consume stops the
+// pool and is returned.
+func runOrdered[T any](n, workers int, produce func(index int) T, consume func(index int, result T) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	type item struct {
+		index  int
+		result T
+	}
+
+	jobs := make(chan int)
+	results := make(chan item, workers)
+	done := make(chan struct{})
+	window := make(chan struct{}, 4*workers)
+
+	go func() {
+		defer close(jobs)
+		for i := 0; i < n; i++ {
+			select {
+			case window <- struct{}{}:
+			case <-done:
+				return
+			}
+			select {
+			case jobs <- i:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				r := produce(i)
+				select {
+				case results <- item{index: i, result: r}:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	pending := make(map[int]T, cap(window))
+	next := 0
+	var err error
+	for it := range results {
+		if err != nil {
+			// Drain the remaining results so the workers can exit.
+			continue
+		}
+		pending[it.index] = it.result
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			if err = consume(next, r); err != nil {
+				close(done)
+				break
+			}
+			next++
+			<-window
+		}
+	}
+	return err
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestRunOrderedPreservesOrder(t *testing.T) {
+	const n = 200
+	var got []int
+	err := runOrdered(n, 8, func(i int) int {
+		time.Sleep(time.Duration(rand.Intn(200)) * time.Microsecond)
+		return i * i
+	}, func(i int, result int) error {
+		if result != i*i {
+			t.Errorf("Expected result %d for index %d, but got %d", i*i, i, result)
+		}
+		got = append(got, i)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("runOrdered returned an error: %v", err)
+	}
+
+	if len(got) != n {
+		t.Fatalf("Expected %d results, but got %d", n, len(got))
+	}
+	for i, index := range got {
+		if index != i {
+			t.Fatalf("Expected index %d at position %d, but got %d", i, i, index)
+		}
+	}
+}
+
+func TestRunOrderedStopsOnError(t *testing.T) {
+	stop := errors.New("stop")
+	consumed := 0
+	err := runOrdered(100, 4, func(i int) int {
+		return i
+	}, func(i int, result int) error {
+		consumed++
+		if i == 10 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("Expected the consumer error, but got %v", err)
+	}
+	if consumed != 11 {
+		t.Errorf("Expected 11 consumed results, but got %d", consumed)
+	}
+}
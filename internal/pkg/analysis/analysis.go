@@ -3,6 +3,7 @@ package analysis
 import (
 	"fmt"
 	"spn-benchmark-ds/internal/pkg/generation"
+	"spn-benchmark-ds/internal/pkg/reward"
 
 	"gonum.org/v1/gonum/mat"
 )
@@ -15,10 +16,108 @@ type SPNAnalysisResult struct {
 	AverageMarkings []float64
 	// MarkingDensities is a slice of marking densities for each place.
 	MarkingDensities [][]float64
+	// Throughputs holds the mean number of firings per unit time of each transition, immediate
+	// transitions included.
+	Throughputs []float64
+	// TokenFlowRates holds the rate at which tokens enter, and in steady state leave, each place.
+	TokenFlowRates []float64
+	// SojournTimes holds the mean time a token spends in each place.
+	SojournTimes []float64
+	// ResponseTimes holds the mean response time of each requested place and transition pair.
+	ResponseTimes []ResponseTime
+	// Transient holds the transient distributions, or nil when no time points were requested.
+	Transient *TransientResult
+	// Rewards holds the expected value of each requested reward structure.
+	Rewards []RewardValue
+	// Chain holds the component decomposition of the chain.
+	Chain *ChainStructure
+	// SolverStats holds the accuracy metrics of the steady-state solution.
+	SolverStats *SolverStats
+}
+
+// Options selects the analyses run by Analyze.
+type Options struct {
+	// Solver selects and tunes the steady-state solver.
+	Solver SolverOptions
+	// ReduciblePolicy is ReducibleReject or ReduciblePerBSCC and selects how chains that are not
+	// irreducible are handled. Empty selects ReduciblePerBSCC.
+	ReduciblePolicy string
+	// ResponseTimePairs are the place and transition pairs whose mean response time is computed.
+	ResponseTimePairs []PlaceTransitionPair
+	// TransientTimes are the time points of the transient analysis. Empty skips it.
+	TransientTimes []float64
+	// TransientEpsilon bounds the truncation error of each transient distribution.
+	// Zero selects DefaultTransientEpsilon.
+	TransientEpsilon float64
+	// Rewards are the reward structures evaluated against the steady-state and transient
+	// distributions.
+	Rewards []reward.Reward
+}
+
+// Validate checks the solver options and policy, the transient analysis parameters and the syntax
+// of the reward structures.
+func (o Options) Validate() error {
+	if err := o.Solver.Validate(); err != nil {
+		return err
+	}
+	if err := validatePolicy(o.ReduciblePolicy); err != nil {
+		return err
+	}
+	if _, err := reward.CompileAll(o.Rewards); err != nil {
+		return err
+	}
+	if o.TransientEpsilon < 0 {
+		return fmt.Errorf("transient error bound must not be negative, got %g", o.TransientEpsilon)
+	}
+	return validateTransient(o.TransientTimes, o.TransientEpsilon)
+}
+
+// Analyze checks the irreducibility of the CTMC induced by a reachability graph and a set of
+// lambda values, solves it for its steady state and, if time points are given, its transient
+// distributions, and derives the token statistics of each place, the throughput of each
+// transition and the requested rewards.
+func Analyze(rg *generation.ReachabilityGraph, lambdaValues []float64, opts Options) (*SPNAnalysisResult, *SolverStats, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, nil, err
+	}
+	steadyStateProbs, stats, chain, err := SolveSteadyStateWithPolicy(rg, lambdaValues, opts.Solver, opts.ReduciblePolicy)
+	if err != nil {
+		return nil, stats, fmt.Errorf("error solving for steady state: %w", err)
+	}
+	avgMarkings, markingDensities := ComputeAverageMarkings(rg, steadyStateProbs)
+	throughputs := ComputeThroughputs(rg, lambdaValues, steadyStateProbs)
+	tokenFlowRates := ComputeTokenFlowRates(rg, lambdaValues, steadyStateProbs)
+	result := &SPNAnalysisResult{
+		SteadyStateProbs: steadyStateProbs,
+		AverageMarkings:  avgMarkings,
+		MarkingDensities: markingDensities,
+		Throughputs:      throughputs,
+		TokenFlowRates:   tokenFlowRates,
+		SojournTimes:     ComputeSojournTimes(avgMarkings, tokenFlowRates),
+		Chain:            chain,
+		SolverStats:      stats,
+	}
+	if len(opts.ResponseTimePairs) > 0 {
+		if result.ResponseTimes, err = ComputeResponseTimes(avgMarkings, throughputs, opts.ResponseTimePairs); err != nil {
+			return nil, stats, err
+		}
+	}
+	if len(opts.TransientTimes) > 0 {
+		if result.Transient, err = ComputeTransient(rg, lambdaValues, opts.TransientTimes, opts.TransientEpsilon); err != nil {
+			return nil, stats, fmt.Errorf("error solving for transient distributions: %w", err)
+		}
+	}
+	if len(opts.Rewards) > 0 {
+		if result.Rewards, err = computeRewards(rg, lambdaValues, result, opts.Rewards); err != nil {
+			return nil, stats, fmt.Errorf("error computing rewards: %w", err)
+		}
+	}
+	return result, stats, nil
 }
 
 // ComputeStateEquation computes the state equation for the SPN.
 // It takes a reachability graph and a slice of lambda values and returns a state matrix and a target vector.
+// For GSPNs the graph must be a tangible reachability graph, whose arc probabilities scale the rates.
 func ComputeStateEquation(rg *generation.ReachabilityGraph, lambdaValues []float64) (*mat.Dense, *mat.VecDense) {
 	numVertices := rg.NumVertices
 	data := make([]float64, (numVertices+1)*numVertices)
@@ -27,7 +126,7 @@ func ComputeStateEquation(rg *generation.ReachabilityGraph, lambdaValues []float
 		edge := rg.Edge(i)
 		srcIdx, destIdx := edge[0], edge[1]
 		transIdx := rg.ArcTransitions[i]
-		rate := lambdaValues[transIdx]
+		rate := lambdaValues[transIdx] * rg.ArcProbability(i)
 		data[srcIdx*numVertices+srcIdx] -= rate
 		data[destIdx*numVertices+srcIdx] += rate
 	}
@@ -46,7 +145,15 @@ func ComputeStateEquation(rg *generation.ReachabilityGraph, lambdaValues []float
 
 // SolveForSteadyState solves for steady-state probabilities.
 // It takes a state matrix and a target vector and returns a slice of steady-state probabilities.
+// Negative components left by rounding are set to zero and the rest renormalized.
 func SolveForSteadyState(stateMatrix *mat.Dense, targetVector *mat.VecDense) ([]float64, error) {
+	probs, _, _, err := solveStateEquation(stateMatrix, targetVector)
+	return probs, err
+}
+
+// solveStateEquation solves the state equation with an LU factorization and also returns the mass
+// lost to clamping negative probabilities and the estimated condition number of the system.
+func solveStateEquation(stateMatrix *mat.Dense, targetVector *mat.VecDense) ([]float64, float64, float64, error) {
 	_, numVertices := stateMatrix.Dims()
 
 	// ⚡ Bolt: Optimized matrix/vector preparation for the solver.
@@ -58,25 +165,17 @@ func SolveForSteadyState(stateMatrix *mat.Dense, targetVector *mat.VecDense) ([]
 	A := stateMatrix.Slice(1, numVertices+1, 0, numVertices)
 	b := targetVector.SliceVec(1, numVertices+1)
 
+	var lu mat.LU
+	lu.Factorize(A)
 	var x mat.VecDense
-	if err := x.SolveVec(A, b); err != nil {
-		return nil, fmt.Errorf("failed to solve linear system: %v", err)
+	if err := lu.SolveVecTo(&x, false, b); err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to solve linear system: %v", err)
 	}
 
 	probs := x.RawVector().Data
-	probSum := 0.0
-	for _, p := range probs {
-		if p < 0 {
-			p = 0
-		}
-		probSum += p
-	}
-	if probSum > 1e-9 {
-		for i := range probs {
-			probs[i] /= probSum
-		}
-	}
-	return probs, nil
+	clamped := clampedMass(probs)
+	normalize(probs)
+	return probs, clamped, lu.Cond(), nil
 }
 
 // ComputeAverageMarkings calculates the average number of tokens for each place.
@@ -0,0 +1,342 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"spn-benchmark-ds/internal/pkg/generation"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Policies for chains that are not irreducible, accepted by Options.ReduciblePolicy.
+const (
+	// ReducibleReject rejects chains that are not irreducible with a *NotIrreducibleError.
+	ReducibleReject = "reject"
+	// ReduciblePerBSCC solves every bottom strongly connected component on its own and weights its
+	// stationary distribution by the probability of being absorbed into it from the initial
+	// distribution.
+	ReduciblePerBSCC = "per_bscc"
+)
+
+// ChainStructure describes the strongly connected components of the CTMC induced by a
+// reachability graph. The chain is irreducible when it has a single component; otherwise its
+// long-run behavior depends on the bottom components, which it never leaves once entered.
+type ChainStructure struct {
+	// Components holds the component of each marking.
+	Components []int `json:"-"`
+	// NumComponents is the number of strongly connected components.
+	NumComponents int `json:"num_components"`
+	// BottomComponents holds the markings of each bottom component, sorted, in the order of their
+	// smallest marking.
+	BottomComponents [][]int `json:"bottom_components"`
+	// DeadMarkings holds the markings without any outgoing arc in the reachability graph, in which
+	// no transition is enabled.
+	DeadMarkings []int `json:"dead_markings"`
+	// AbsorptionProbabilities holds the probability of ending up in each bottom component from the
+	// initial distribution. It is only set when the chain was solved per bottom component.
+	AbsorptionProbabilities []float64 `json:"absorption_probabilities,omitempty"`
+}
+
+// IsIrreducible reports whether every marking can reach every other marking.
+func (c *ChainStructure) IsIrreducible() bool {
+	return c.NumComponents <= 1
+}
+
+// NotIrreducibleError is returned when the steady state of a chain that is not irreducible is
+// requested under the ReducibleReject policy. Such a chain has transient markings, and if it has
+// several bottom components, its long-run distribution depends on where it starts.
+type NotIrreducibleError struct {
+	// Structure is the component decomposition of the chain.
+	Structure *ChainStructure
+}
+
+func (e *NotIrreducibleError) Error() string {
+	return fmt.Sprintf("chain is not irreducible: %d strongly connected components, %d bottom components, %d dead markings",
+		e.Structure.NumComponents, len(e.Structure.BottomComponents), len(e.Structure.DeadMarkings))
+}
+
+// AnalyzeChainStructure decomposes the chain of the generator g of a reachability graph into
+// strongly connected components with Tarjan's algorithm, finds its bottom components and lists
+// the dead markings of the graph. Entries of g with a zero rate are not arcs of the chain.
+func AnalyzeChainStructure(rg *generation.ReachabilityGraph, g *Generator) *ChainStructure {
+	// The SCCs of a graph are those of its transpose, so Tarjan runs directly on the transposed
+	// CSR layout of the generator: the successors of i are the sources of the rates into i.
+	const unvisited = -1
+	index := make([]int, g.N)
+	low := make([]int, g.N)
+	onStack := make([]bool, g.N)
+	comp := make([]int, g.N)
+	next := make([]int, g.N)
+	for i := range index {
+		index[i] = unvisited
+	}
+	var stack, calls []int
+	counter, numComponents := 0, 0
+
+	for root := 0; root < g.N; root++ {
+		if index[root] != unvisited {
+			continue
+		}
+		calls = append(calls[:0], root)
+		index[root], low[root] = counter, counter
+		counter++
+		next[root] = g.RowPtr[root]
+		stack = append(stack, root)
+		onStack[root] = true
+		for len(calls) > 0 {
+			u := calls[len(calls)-1]
+			if k := next[u]; k < g.RowPtr[u+1] {
+				next[u]++
+				w := g.ColIdx[k]
+				if g.Values[k] == 0 {
+					continue
+				}
+				if index[w] == unvisited {
+					index[w], low[w] = counter, counter
+					counter++
+					next[w] = g.RowPtr[w]
+					stack = append(stack, w)
+					onStack[w] = true
+					calls = append(calls, w)
+				} else if onStack[w] {
+					low[u] = min(low[u], index[w])
+				}
+				continue
+			}
+			calls = calls[:len(calls)-1]
+			if len(calls) > 0 {
+				parent := calls[len(calls)-1]
+				low[parent] = min(low[parent], low[u])
+			}
+			if low[u] == index[u] {
+				for {
+					w := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					onStack[w] = false
+					comp[w] = numComponents
+					if w == u {
+						break
+					}
+				}
+				numComponents++
+			}
+		}
+	}
+
+	// A component is a bottom component when no arc leaves it.
+	bottom := make([]bool, numComponents)
+	for c := range bottom {
+		bottom[c] = true
+	}
+	for i := 0; i < g.N; i++ {
+		for k := g.RowPtr[i]; k < g.RowPtr[i+1]; k++ {
+			if j := g.ColIdx[k]; g.Values[k] != 0 && comp[j] != comp[i] {
+				bottom[comp[j]] = false
+			}
+		}
+	}
+
+	structure := &ChainStructure{Components: comp, NumComponents: numComponents, DeadMarkings: []int{}}
+	members := make(map[int][]int)
+	for i := 0; i < g.N; i++ {
+		if bottom[comp[i]] {
+			members[comp[i]] = append(members[comp[i]], i)
+		}
+	}
+	// Dead markings are read from the arcs of the graph rather than the exit rates: a marking
+	// whose only arcs are self-loops or have a zero rate is absorbing but not dead.
+	live := make([]bool, rg.NumVertices)
+	for i := 0; i < rg.NumEdges; i++ {
+		live[rg.Edges[i*rg.EdgesStride]] = true
+	}
+	for v, isLive := range live {
+		if !isLive {
+			structure.DeadMarkings = append(structure.DeadMarkings, v)
+		}
+	}
+	for _, states := range members {
+		structure.BottomComponents = append(structure.BottomComponents, states)
+	}
+	sort.Slice(structure.BottomComponents, func(a, b int) bool {
+		return structure.BottomComponents[a][0] < structure.BottomComponents[b][0]
+	})
+	return structure
+}
+
+// SolveSteadyStateWithPolicy checks whether the chain induced by a reachability graph is
+// irreducible before solving for its steady state. Irreducible chains are solved by
+// SolveSteadyState. Other chains are rejected with a *NotIrreducibleError under ReducibleReject;
+// under ReduciblePerBSCC each bottom component is solved with the given options and weighted by
+// its absorption probability, and transient markings get probability zero. The statistics then
+// add up the iterations and weighted clamped mass of the components and keep their largest
+// condition number. An empty policy selects ReduciblePerBSCC, which gives the same distribution
+// as SolveSteadyState for chains with a single bottom component.
+func SolveSteadyStateWithPolicy(rg *generation.ReachabilityGraph, lambdaValues []float64, opts SolverOptions, policy string) ([]float64, *SolverStats, *ChainStructure, error) {
+	if err := validatePolicy(policy); err != nil {
+		return nil, nil, nil, err
+	}
+	g := NewGenerator(rg, lambdaValues)
+	structure := AnalyzeChainStructure(rg, g)
+	if structure.IsIrreducible() {
+		probs, stats, err := SolveSteadyState(rg, lambdaValues, opts)
+		return probs, stats, structure, err
+	}
+	if policy == ReducibleReject {
+		return nil, nil, structure, &NotIrreducibleError{Structure: structure}
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, nil, structure, err
+	}
+
+	absorption, err := absorptionProbabilities(g, structure, initialDistribution(rg, g.N))
+	if err != nil {
+		return nil, nil, structure, err
+	}
+	structure.AbsorptionProbabilities = absorption
+
+	probs := make([]float64, g.N)
+	stats := &SolverStats{Method: opts.withDefaults().Method, Converged: true}
+	for b, states := range structure.BottomComponents {
+		if absorption[b] == 0 {
+			continue
+		}
+		local := []float64{1}
+		if len(states) > 1 {
+			var localStats *SolverStats
+			local, localStats, err = SolveSteadyState(subgraph(rg, states), lambdaValues, opts)
+			if err != nil {
+				return nil, localStats, structure, fmt.Errorf("bottom component %d: %w", b, err)
+			}
+			stats.Iterations += localStats.Iterations
+			stats.ClampedMass += absorption[b] * localStats.ClampedMass
+			stats.ConditionNumber = max(stats.ConditionNumber, localStats.ConditionNumber)
+		}
+		for k, v := range states {
+			probs[v] = absorption[b] * local[k]
+		}
+	}
+	stats.Residual = g.Residual(probs)
+	if err := opts.checkAccuracy(stats); err != nil {
+		return nil, stats, structure, err
+	}
+	return probs, stats, structure, nil
+}
+
+// validatePolicy checks a policy for chains that are not irreducible.
+func validatePolicy(policy string) error {
+	switch policy {
+	case "", ReducibleReject, ReduciblePerBSCC:
+		return nil
+	default:
+		return fmt.Errorf("unknown policy %q for chains that are not irreducible", policy)
+	}
+}
+
+// initialDistribution returns the initial distribution of a graph over its n markings.
+func initialDistribution(rg *generation.ReachabilityGraph, n int) []float64 {
+	pi := make([]float64, n)
+	if rg.InitialDistribution != nil {
+		copy(pi, rg.InitialDistribution)
+	} else if n > 0 {
+		pi[0] = 1
+	}
+	return pi
+}
+
+// absorptionProbabilities returns the probability of ending up in each bottom component from the
+// initial distribution pi0. The absorption probabilities X of the transient markings T solve
+// -Q_TT X = Q_TB, with one column per bottom component, which is solved densely.
+func absorptionProbabilities(g *Generator, structure *ChainStructure, pi0 []float64) ([]float64, error) {
+	numBottom := len(structure.BottomComponents)
+	bottomOf := make([]int, g.N)
+	for i := range bottomOf {
+		bottomOf[i] = -1
+	}
+	for b, states := range structure.BottomComponents {
+		for _, v := range states {
+			bottomOf[v] = b
+		}
+	}
+
+	absorption := make([]float64, numBottom)
+	transientIndex := make([]int, g.N)
+	var transient []int
+	for i := 0; i < g.N; i++ {
+		if b := bottomOf[i]; b >= 0 {
+			absorption[b] += pi0[i]
+			transientIndex[i] = -1
+			continue
+		}
+		transientIndex[i] = len(transient)
+		transient = append(transient, i)
+	}
+	if len(transient) == 0 {
+		return absorption, nil
+	}
+
+	nt := len(transient)
+	a := mat.NewDense(nt, nt, nil)
+	rhs := mat.NewDense(nt, numBottom, nil)
+	for ti, i := range transient {
+		a.Set(ti, ti, -g.Diag[i])
+	}
+	// Row i of the transposed generator holds the rates q(j, i) from each source j into i.
+	for i := 0; i < g.N; i++ {
+		for k := g.RowPtr[i]; k < g.RowPtr[i+1]; k++ {
+			tj := transientIndex[g.ColIdx[k]]
+			if tj < 0 {
+				continue
+			}
+			if ti := transientIndex[i]; ti >= 0 {
+				a.Set(tj, ti, a.At(tj, ti)-g.Values[k])
+			} else {
+				b := bottomOf[i]
+				rhs.Set(tj, b, rhs.At(tj, b)+g.Values[k])
+			}
+		}
+	}
+
+	var x mat.Dense
+	if err := x.Solve(a, rhs); err != nil {
+		return nil, fmt.Errorf("failed to solve for absorption probabilities: %v", err)
+	}
+	for ti, i := range transient {
+		if pi0[i] == 0 {
+			continue
+		}
+		for b := 0; b < numBottom; b++ {
+			absorption[b] += pi0[i] * x.At(ti, b)
+		}
+	}
+	return absorption, nil
+}
+
+// subgraph returns the graph induced by the given sorted markings, which must have no arcs
+// leaving them.
+func subgraph(rg *generation.ReachabilityGraph, states []int) *generation.ReachabilityGraph {
+	local := make(map[int]int, len(states))
+	sub := &generation.ReachabilityGraph{
+		VerticesStride: rg.VerticesStride,
+		EdgesStride:    2,
+		IsBounded:      rg.IsBounded,
+		Vertices:       make([]int, 0, len(states)*rg.VerticesStride),
+	}
+	for k, v := range states {
+		local[v] = k
+		sub.Vertices = append(sub.Vertices, rg.Vertex(v)...)
+	}
+	sub.NumVertices = len(states)
+	for i := 0; i < rg.NumEdges; i++ {
+		src, ok := local[rg.Edges[i*rg.EdgesStride]]
+		if !ok {
+			continue
+		}
+		sub.Edges = append(sub.Edges, src, local[rg.Edges[i*rg.EdgesStride+1]])
+		sub.ArcTransitions = append(sub.ArcTransitions, rg.ArcTransitions[i])
+		if rg.ArcProbabilities != nil {
+			sub.ArcProbabilities = append(sub.ArcProbabilities, rg.ArcProbabilities[i])
+		}
+		sub.NumEdges++
+	}
+	return sub
+}
@@ -0,0 +1,123 @@
+package analysis
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"spn-benchmark-ds/internal/pkg/generation"
+	"testing"
+)
+
+// forkGraph returns a chain where marking 0 moves to the dead marking 1 with transition 0 or to
+// the cycle 2 <-> 3 with transition 1.
+func forkGraph() *generation.ReachabilityGraph {
+	return &generation.ReachabilityGraph{
+		Vertices:       []int{1, 0, 0, 0, 1, 0, 0, 0, 1, 0, 1, 1},
+		Edges:          []int{0, 1, 0, 2, 2, 3, 3, 2},
+		VerticesStride: 3,
+		EdgesStride:    2,
+		NumVertices:    4,
+		NumEdges:       4,
+		ArcTransitions: []int{0, 1, 2, 3},
+		IsBounded:      true,
+	}
+}
+
+func TestAnalyzeChainStructure(t *testing.T) {
+	structure := AnalyzeChainStructure(cyclicGraph(), NewGenerator(cyclicGraph(), []float64{1, 2, 3, 4}))
+	if !structure.IsIrreducible() || len(structure.BottomComponents) != 1 || len(structure.DeadMarkings) != 0 {
+		t.Errorf("Expected the cyclic chain to be irreducible, got %+v", structure)
+	}
+
+	structure = AnalyzeChainStructure(forkGraph(), NewGenerator(forkGraph(), []float64{1, 3, 2, 4}))
+	if structure.IsIrreducible() || structure.NumComponents != 3 {
+		t.Fatalf("Expected 3 components, got %+v", structure)
+	}
+	if !slices.EqualFunc(structure.BottomComponents, [][]int{{1}, {2, 3}}, slices.Equal[[]int]) {
+		t.Errorf("Expected bottom components [[1] [2 3]], got %v", structure.BottomComponents)
+	}
+	if !slices.Equal(structure.DeadMarkings, []int{1}) {
+		t.Errorf("Expected marking 1 to be dead, got %v", structure.DeadMarkings)
+	}
+	if structure.Components[2] != structure.Components[3] || structure.Components[0] == structure.Components[2] {
+		t.Errorf("Expected markings 2 and 3 to share a component apart from marking 0, got %v", structure.Components)
+	}
+
+	// A self-loop leaves marking 1 absorbing in the chain, but a transition is enabled in it.
+	rg := forkGraph()
+	rg.AddEdge([2]int{1, 1})
+	rg.ArcTransitions = append(rg.ArcTransitions, 4)
+	structure = AnalyzeChainStructure(rg, NewGenerator(rg, []float64{1, 3, 2, 4, 5}))
+	if len(structure.DeadMarkings) != 0 {
+		t.Errorf("Expected no dead markings once marking 1 has a self-loop, got %v", structure.DeadMarkings)
+	}
+	if !slices.EqualFunc(structure.BottomComponents, [][]int{{1}, {2, 3}}, slices.Equal[[]int]) {
+		t.Errorf("Expected marking 1 to remain a bottom component, got %v", structure.BottomComponents)
+	}
+}
+
+func TestSolveSteadyStateWithPolicy(t *testing.T) {
+	rg := forkGraph()
+	lambdaValues := []float64{1, 3, 2, 4}
+
+	_, _, _, err := SolveSteadyStateWithPolicy(rg, lambdaValues, SolverOptions{}, ReducibleReject)
+	var notIrreducible *NotIrreducibleError
+	if !errors.As(err, &notIrreducible) || len(notIrreducible.Structure.BottomComponents) != 2 {
+		t.Fatalf("Expected a NotIrreducibleError with 2 bottom components, got %v", err)
+	}
+
+	// Marking 0 is absorbed into marking 1 with probability 1/4 and into the cycle with 3/4, where
+	// the chain spends 2/3 of the time in marking 2.
+	expected := []float64{0, 1.0 / 4.0, 3.0 / 4.0 * 2.0 / 3.0, 3.0 / 4.0 / 3.0}
+	for _, method := range []string{MethodDense, MethodGaussSeidel, MethodPower} {
+		probs, stats, structure, err := SolveSteadyStateWithPolicy(rg, lambdaValues, SolverOptions{Method: method, Tolerance: 1e-12}, ReduciblePerBSCC)
+		if err != nil {
+			t.Fatalf("%s: error solving per bottom component: %v", method, err)
+		}
+		expectSlice(t, method+" probability", probs, expected)
+		expectSlice(t, method+" absorption probability", structure.AbsorptionProbabilities, []float64{0.25, 0.75})
+		if stats.Residual > 1e-9 {
+			t.Errorf("%s: expected a stationary distribution, got residual %g", method, stats.Residual)
+		}
+	}
+
+	// Starting in the cycle, the dead marking is never reached.
+	rg.InitialDistribution = []float64{0, 0, 1, 0}
+	probs, _, _, err := SolveSteadyStateWithPolicy(rg, lambdaValues, SolverOptions{}, "")
+	if err != nil {
+		t.Fatalf("Error solving per bottom component: %v", err)
+	}
+	expectSlice(t, "probability", probs, []float64{0, 0, 2.0 / 3.0, 1.0 / 3.0})
+
+	if _, _, _, err := SolveSteadyStateWithPolicy(rg, lambdaValues, SolverOptions{}, "ignore"); err == nil {
+		t.Errorf("Expected an error for an unknown policy")
+	}
+}
+
+func TestSolveSteadyStateWithPolicyMatchesSingleBottomComponent(t *testing.T) {
+	// 0 -> 1 <-> 2: marking 0 is transient and the rest is a single bottom component, which the
+	// plain solver handles too.
+	rg := &generation.ReachabilityGraph{
+		Vertices:       []int{1, 0, 0, 0, 1, 0, 0, 0, 1},
+		Edges:          []int{0, 1, 1, 2, 2, 1},
+		VerticesStride: 3,
+		EdgesStride:    2,
+		NumVertices:    3,
+		NumEdges:       3,
+		ArcTransitions: []int{0, 1, 2},
+		IsBounded:      true,
+	}
+	lambdaValues := []float64{5, 1, 3}
+	expected, _, err := SolveSteadyState(rg, lambdaValues, SolverOptions{})
+	if err != nil {
+		t.Fatalf("Error solving for steady state: %v", err)
+	}
+	probs, _, structure, err := SolveSteadyStateWithPolicy(rg, lambdaValues, SolverOptions{}, ReduciblePerBSCC)
+	if err != nil {
+		t.Fatalf("Error solving per bottom component: %v", err)
+	}
+	expectSlice(t, "probability", probs, expected)
+	if math.Abs(probs[1]-0.75) > 1e-9 || structure.IsIrreducible() {
+		t.Errorf("Expected π1 = 3/4 in a reducible chain, got %v and %+v", probs, structure)
+	}
+}
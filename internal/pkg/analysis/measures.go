@@ -0,0 +1,102 @@
+package analysis
+
+import (
+	"fmt"
+	"spn-benchmark-ds/internal/pkg/generation"
+)
+
+// PlaceTransitionPair names a place and a transition whose mean response time is computed.
+type PlaceTransitionPair struct {
+	// Place is the index of the place.
+	Place int
+	// Transition is the index of the transition.
+	Transition int
+}
+
+// ResponseTime is the mean response time of a place and transition pair.
+type ResponseTime struct {
+	// Place is the index of the place.
+	Place int `json:"place"`
+	// Transition is the index of the transition.
+	Transition int `json:"transition"`
+	// Time is the mean number of tokens in the place divided by the throughput of the transition,
+	// or zero if the transition never fires.
+	Time float64 `json:"response_time"`
+}
+
+// ComputeThroughputs returns the throughput of each transition, the mean number of firings per
+// unit time: the sum of π(m)·λ(t) over the markings m enabling t. It is computed arc by arc, so the
+// arc probabilities of tangible reachability graphs split the rate of a firing over the markings
+// it may lead to. Immediate transitions fire inside the vanishing markings that tangible graphs
+// eliminate, and each arc credits them with its rate times their mean number of firings on the
+// way; graphs without these counts give immediate transitions a throughput of zero.
+func ComputeThroughputs(rg *generation.ReachabilityGraph, lambdaValues []float64, steadyStateProbs []float64) []float64 {
+	throughputs := make([]float64, len(lambdaValues))
+	for i := 0; i < rg.NumEdges; i++ {
+		src := rg.Edges[i*rg.EdgesStride]
+		t := rg.ArcTransitions[i]
+		rate := steadyStateProbs[src] * lambdaValues[t] * rg.ArcProbability(i)
+		throughputs[t] += rate
+		if rg.ImmediateFirings != nil {
+			for _, f := range rg.ImmediateFirings[i] {
+				throughputs[f.Transition] += rate * f.Count
+			}
+		}
+	}
+	return throughputs
+}
+
+// ComputeTokenFlowRates returns the rate at which tokens enter each place: the sum over the arcs
+// of the reachability graph of their rate times the number of tokens they add to the place.
+// Tokens a transition takes from a place and puts back are not counted, and in steady state every
+// place loses tokens at the same rate as it gains them.
+func ComputeTokenFlowRates(rg *generation.ReachabilityGraph, lambdaValues []float64, steadyStateProbs []float64) []float64 {
+	numPlaces := rg.VerticesStride
+	flows := make([]float64, numPlaces)
+	for i := 0; i < rg.NumEdges; i++ {
+		src, dest := rg.Edges[i*rg.EdgesStride], rg.Edges[i*rg.EdgesStride+1]
+		rate := steadyStateProbs[src] * lambdaValues[rg.ArcTransitions[i]] * rg.ArcProbability(i)
+		for p := 0; p < numPlaces; p++ {
+			if delta := rg.Vertices[dest*numPlaces+p] - rg.Vertices[src*numPlaces+p]; delta > 0 {
+				flows[p] += rate * float64(delta)
+			}
+		}
+	}
+	return flows
+}
+
+// ComputeSojournTimes returns the mean time a token spends in each place by Little's law, the
+// average marking divided by the token flow rate. Places whose marking never changes have no
+// flow and get zero.
+func ComputeSojournTimes(avgMarkings []float64, tokenFlowRates []float64) []float64 {
+	times := make([]float64, len(avgMarkings))
+	for p := range times {
+		if flow := tokenFlowRates[p]; flow > 0 {
+			times[p] = avgMarkings[p] / flow
+		}
+	}
+	return times
+}
+
+// ComputeResponseTimes returns the mean response time of each pair by Little's law: the mean
+// number of tokens in the place divided by the throughput of the transition. It is the mean time
+// a token waits in the place before the transition removes it when the transition is the only one
+// consuming from the place, one token at a time. Immediate transitions fire at the throughput
+// credited to them by ComputeThroughputs, and a place they consume from that is only marked in
+// vanishing markings has no tokens in the tangible graph, so its tokens wait no time.
+func ComputeResponseTimes(avgMarkings []float64, throughputs []float64, pairs []PlaceTransitionPair) ([]ResponseTime, error) {
+	times := make([]ResponseTime, len(pairs))
+	for i, pair := range pairs {
+		if pair.Place < 0 || pair.Place >= len(avgMarkings) {
+			return nil, fmt.Errorf("response time pair %d: place %d out of range [0, %d)", i, pair.Place, len(avgMarkings))
+		}
+		if pair.Transition < 0 || pair.Transition >= len(throughputs) {
+			return nil, fmt.Errorf("response time pair %d: transition %d out of range [0, %d)", i, pair.Transition, len(throughputs))
+		}
+		times[i] = ResponseTime{Place: pair.Place, Transition: pair.Transition}
+		if x := throughputs[pair.Transition]; x > 0 {
+			times[i].Time = avgMarkings[pair.Place] / x
+		}
+	}
+	return times, nil
+}
@@ -0,0 +1,133 @@
+package analysis
+
+import (
+	"math"
+	"spn-benchmark-ds/internal/pkg/generation"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+	"spn-benchmark-ds/internal/pkg/testnets"
+	"testing"
+)
+
+// expectSlice reports every element of got that differs from expected by more than 1e-9.
+func expectSlice(t *testing.T, name string, got, expected []float64) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d %s, got %v", len(expected), name, got)
+	}
+	for i := range expected {
+		if math.Abs(got[i]-expected[i]) > 1e-9 {
+			t.Errorf("Expected %s %d to be %f, but got %f", name, i, expected[i], got[i])
+		}
+	}
+}
+
+func TestAnalyzeTwoStateMeasures(t *testing.T) {
+	lambda, mu := 2.0, 3.0
+	pairs := []PlaceTransitionPair{{Place: 0, Transition: 0}, {Place: 1, Transition: 1}}
+	result, _, err := Analyze(twoStateGraph(), []float64{lambda, mu}, Options{ResponseTimePairs: pairs})
+	if err != nil {
+		t.Fatalf("Error analyzing: %v", err)
+	}
+
+	// π0 = μ/(λ+μ) and both transitions fire λμ/(λ+μ) times per unit time. A token stays 1/λ in
+	// P0 and 1/μ in P1.
+	x := lambda * mu / (lambda + mu)
+	expectSlice(t, "throughput", result.Throughputs, []float64{x, x})
+	expectSlice(t, "token flow rate", result.TokenFlowRates, []float64{x, x})
+	expectSlice(t, "sojourn time", result.SojournTimes, []float64{1 / lambda, 1 / mu})
+	if len(result.ResponseTimes) != 2 {
+		t.Fatalf("Expected 2 response times, got %v", result.ResponseTimes)
+	}
+	for i, expected := range []float64{1 / lambda, 1 / mu} {
+		r := result.ResponseTimes[i]
+		if r.Place != pairs[i].Place || r.Transition != pairs[i].Transition || math.Abs(r.Time-expected) > 1e-9 {
+			t.Errorf("Expected response time %f for %+v, got %+v", expected, pairs[i], r)
+		}
+	}
+
+	pairs = []PlaceTransitionPair{{Place: 2, Transition: 0}}
+	if _, _, err := Analyze(twoStateGraph(), []float64{lambda, mu}, Options{ResponseTimePairs: pairs}); err == nil {
+		t.Errorf("Expected an error for a place out of range")
+	}
+}
+
+func TestMeasuresWithWeightedArcs(t *testing.T) {
+	// The birth-death chain over (4,0), (2,1), (0,2) of TestSolveSteadyStateWithWeightedArcs, with
+	// π = (1, 2, 4)/7.
+	pn := petrinet.NewPetriNet(2, 2)
+	pn.Matrix = []int{
+		2, 0, 0, 2, 4,
+		0, 1, 1, 0, 0,
+	}
+	pn.InitialMarking = []int{4, 0}
+	rg, err := generation.GenerateReachabilityGraph(pn, 10, 100)
+	if err != nil {
+		t.Fatalf("Error generating reachability graph: %v", err)
+	}
+	result, _, err := Analyze(rg, []float64{2.0, 1.0}, Options{})
+	if err != nil {
+		t.Fatalf("Error analyzing: %v", err)
+	}
+
+	// T0 is enabled in (4,0) and (2,1), T1 in (2,1) and (0,2). Each firing of T1 adds two tokens
+	// to P1 and each firing of T0 one token to P2.
+	expectSlice(t, "throughput", result.Throughputs, []float64{6.0 / 7.0, 6.0 / 7.0})
+	expectSlice(t, "token flow rate", result.TokenFlowRates, []float64{12.0 / 7.0, 6.0 / 7.0})
+	expectSlice(t, "sojourn time", result.SojournTimes, []float64{2.0 / 3.0, 5.0 / 3.0})
+}
+
+func TestThroughputsScaleByArcProbabilities(t *testing.T) {
+	// The tangible graph of TestSolveSteadyStateScalesRatesByArcProbabilities, with
+	// π = (16, 2, 3)/21. The firings of T0 are split by the immediate choice between T1 and T2.
+	rg := &generation.ReachabilityGraph{
+		Vertices:         []int{1, 0, 0, 0, 1, 0, 0, 0, 1},
+		Edges:            []int{0, 1, 0, 2, 1, 0, 2, 0},
+		VerticesStride:   3,
+		EdgesStride:      2,
+		NumVertices:      3,
+		NumEdges:         4,
+		ArcTransitions:   []int{0, 0, 1, 2},
+		ArcProbabilities: []float64{0.25, 0.75, 1, 1},
+		IsBounded:        true,
+	}
+	probs := []float64{16.0 / 21.0, 2.0 / 21.0, 3.0 / 21.0}
+	throughputs := ComputeThroughputs(rg, []float64{1.0, 2.0, 4.0}, probs)
+	expectSlice(t, "throughput", throughputs, []float64{16.0 / 21.0, 4.0 / 21.0, 12.0 / 21.0})
+}
+
+func TestThroughputsOfImmediateTransitions(t *testing.T) {
+	rg, err := generation.GenerateTangibleReachabilityGraph(testnets.ChoiceNet(0), 10, 100)
+	if err != nil {
+		t.Fatalf("Error generating tangible reachability graph: %v", err)
+	}
+	result, _, err := Analyze(rg, []float64{1, 0, 0, 2, 4}, Options{})
+	if err != nil {
+		t.Fatalf("Error analyzing: %v", err)
+	}
+
+	// The tangible chain is the one of TestThroughputsScaleByArcProbabilities, and the immediate
+	// transitions t1 and t2 split the firings of T0 by weight.
+	x := 16.0 / 21.0
+	expectSlice(t, "throughput", result.Throughputs, []float64{x, x / 4, 3 * x / 4, x / 4, 3 * x / 4})
+}
+
+func TestResponseTimesOfImmediateTransitions(t *testing.T) {
+	rg, err := generation.GenerateTangibleReachabilityGraph(testnets.ChoiceNet(0), 10, 100)
+	if err != nil {
+		t.Fatalf("Error generating tangible reachability graph: %v", err)
+	}
+	// The token waits 1/λ0 in P0 for T0, and no time in P1 for t1 or t2.
+	pairs := []PlaceTransitionPair{{Place: 0, Transition: 0}, {Place: 1, Transition: 1}, {Place: 1, Transition: 2}}
+	result, _, err := Analyze(rg, []float64{1, 0, 0, 2, 4}, Options{ResponseTimePairs: pairs})
+	if err != nil {
+		t.Fatalf("Error analyzing: %v", err)
+	}
+	for i, expected := range []float64{1, 0, 0} {
+		if r := result.ResponseTimes[i]; math.Abs(r.Time-expected) > 1e-9 {
+			t.Errorf("Expected response time %f for %+v, got %+v", expected, pairs[i], r)
+		}
+	}
+	if result.Throughputs[1] <= 0 || result.Throughputs[2] <= 0 {
+		t.Errorf("Expected t1 and t2 to fire, but got throughputs %v", result.Throughputs)
+	}
+}
@@ -0,0 +1,60 @@
+package analysis
+
+import (
+	"math"
+	"spn-benchmark-ds/internal/pkg/reward"
+	"testing"
+)
+
+func TestAnalyzeRewards(t *testing.T) {
+	lambda, mu := 2.0, 3.0
+	rewards := []reward.Reward{
+		// The probability of having a token in P0.
+		{Name: "p0_marked", Rate: "p0 > 0"},
+		// The firing rate of T0, as an impulse of one per firing.
+		{Name: "t0_firings", Impulses: map[int]string{0: "1"}},
+		// A cost of 5 per unit time in P1 plus 2 per firing of T1.
+		{Name: "cost", Rate: "5 * p1", Impulses: map[int]string{1: "2"}},
+	}
+	opts := Options{TransientTimes: []float64{0, 0.5}, TransientEpsilon: 1e-12, Rewards: rewards}
+	result, _, err := Analyze(twoStateGraph(), []float64{lambda, mu}, opts)
+	if err != nil {
+		t.Fatalf("Error analyzing: %v", err)
+	}
+	if len(result.Rewards) != len(rewards) {
+		t.Fatalf("Expected %d rewards, got %v", len(rewards), result.Rewards)
+	}
+
+	pi0 := mu / (lambda + mu)
+	expected := []float64{pi0, pi0 * lambda, 5*(1-pi0) + 2*(1-pi0)*mu}
+	for i, v := range result.Rewards {
+		if v.Name != rewards[i].Name || math.Abs(v.SteadyState-expected[i]) > 1e-9 {
+			t.Errorf("Expected reward %q to be %f, got %+v", rewards[i].Name, expected[i], v)
+		}
+	}
+
+	// p0(t) = μ/(λ+μ) + λ/(λ+μ)·e^{-(λ+μ)t} from P0.
+	for j, tm := range opts.TransientTimes {
+		p0 := pi0 + lambda/(lambda+mu)*math.Exp(-(lambda+mu)*tm)
+		expected := []float64{p0, p0 * lambda, 5*(1-p0) + 2*(1-p0)*mu}
+		for i, v := range result.Rewards {
+			if math.Abs(v.Transient[j]-expected[i]) > 1e-9 {
+				t.Errorf("Expected reward %q at t=%g to be %f, got %f", v.Name, tm, expected[i], v.Transient[j])
+			}
+		}
+	}
+}
+
+func TestAnalyzeRewardErrors(t *testing.T) {
+	lambdaValues := []float64{2.0, 3.0}
+	for _, r := range []reward.Reward{
+		{Name: "syntax", Rate: "p0 +"},
+		{Name: "place", Rate: "p2"},
+		{Name: "transition", Impulses: map[int]string{2: "1"}},
+		{Name: "infinite", Rate: "1 / p1"},
+	} {
+		if _, _, err := Analyze(twoStateGraph(), lambdaValues, Options{Rewards: []reward.Reward{r}}); err == nil {
+			t.Errorf("Expected an error for reward %q", r.Name)
+		}
+	}
+}
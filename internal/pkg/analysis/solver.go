@@ -0,0 +1,463 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"spn-benchmark-ds/internal/pkg/generation"
+)
+
+// Steady-state solution methods accepted by SolverOptions.Method.
+const (
+	// MethodDense solves the full linear system with a dense LU factorization.
+	MethodDense = "dense"
+	// MethodPower iterates the uniformized chain π ← π(I + Q/Λ).
+	MethodPower = "power"
+	// MethodJacobi sweeps the balance equations using the previous iterate only.
+	MethodJacobi = "jacobi"
+	// MethodGaussSeidel sweeps the balance equations in place.
+	MethodGaussSeidel = "gauss_seidel"
+	// MethodSOR is Gauss–Seidel with successive over-relaxation.
+	MethodSOR = "sor"
+	// MethodBiCGSTAB solves the normalized system with Jacobi-preconditioned BiCGSTAB.
+	MethodBiCGSTAB = "bicgstab"
+)
+
+const (
+	defaultTolerance     = 1e-10
+	defaultMaxIterations = 10000
+)
+
+// ErrNotConverged is returned when an iterative solver reaches its iteration limit.
+var ErrNotConverged = errors.New("solver did not converge")
+
+// ErrUnreliable is returned when a steady-state solution exceeds one of the accuracy limits of the
+// solver options.
+var ErrUnreliable = errors.New("steady-state solution is numerically unreliable")
+
+// SolverOptions selects and tunes the steady-state solver.
+// Zero values select the defaults: the dense method, a tolerance of 1e-10,
+// 10000 iterations and a relaxation factor of 1.
+type SolverOptions struct {
+	// Method is one of the Method* constants.
+	Method string
+	// Tolerance is the convergence threshold on the residual of iterative methods.
+	Tolerance float64
+	// MaxIterations is the iteration limit of iterative methods.
+	MaxIterations int
+	// Relaxation is the relaxation factor ω, in (0, 2), of SOR and weighted Jacobi.
+	// Values below one damp the Jacobi iteration, which otherwise oscillates on periodic chains.
+	Relaxation float64
+	// MaxResidual rejects solutions whose residual exceeds it. Zero disables the check.
+	MaxResidual float64
+	// MaxClampedMass rejects solutions that lost more than this share of their mass to clamping
+	// negative probabilities. Zero disables the check.
+	MaxClampedMass float64
+	// MaxConditionNumber rejects solutions whose estimated condition number exceeds it. Only the
+	// dense method estimates it, so Validate rejects it for the others. Zero disables the check.
+	MaxConditionNumber float64
+}
+
+// SolverStats reports how a steady-state solution was obtained and how accurate it is.
+type SolverStats struct {
+	// Method is the method that produced the solution.
+	Method string `json:"method"`
+	// Iterations is the number of iterations performed; zero for the dense method.
+	Iterations int `json:"iterations"`
+	// Residual is the infinity norm of πQ for the returned distribution.
+	Residual float64 `json:"residual"`
+	// Converged is true if the residual reached the tolerance.
+	Converged bool `json:"converged"`
+	// ClampedMass is the total magnitude of the negative probabilities set to zero before the
+	// final normalization, relative to the remaining mass.
+	ClampedMass float64 `json:"clamped_mass"`
+	// ConditionNumber is the estimated condition number of the linear system solved by the dense
+	// method, in the 1-norm. It is zero for iterative methods.
+	ConditionNumber float64 `json:"condition_number"`
+}
+
+// Validate checks that the options name a known method and sensible parameters.
+func (o SolverOptions) Validate() error {
+	switch o.Method {
+	case "", MethodDense, MethodPower, MethodJacobi, MethodGaussSeidel, MethodSOR, MethodBiCGSTAB:
+	default:
+		return fmt.Errorf("unknown steady-state solver %q", o.Method)
+	}
+	if o.Tolerance < 0 {
+		return fmt.Errorf("solver tolerance must not be negative, got %g", o.Tolerance)
+	}
+	if o.MaxIterations < 0 {
+		return fmt.Errorf("solver iteration limit must not be negative, got %d", o.MaxIterations)
+	}
+	if o.Relaxation < 0 || o.Relaxation >= 2 {
+		return fmt.Errorf("SOR relaxation factor must be in (0, 2), got %g", o.Relaxation)
+	}
+	if o.MaxResidual < 0 || o.MaxClampedMass < 0 || o.MaxConditionNumber < 0 {
+		return fmt.Errorf("solver accuracy limits must not be negative")
+	}
+	if o.MaxConditionNumber > 0 && o.Method != "" && o.Method != MethodDense {
+		return fmt.Errorf("the condition number limit requires the %s solver, %s does not estimate it", MethodDense, o.Method)
+	}
+	return nil
+}
+
+// checkAccuracy returns an error wrapping ErrUnreliable if the statistics of a solution exceed
+// one of the accuracy limits.
+func (o SolverOptions) checkAccuracy(stats *SolverStats) error {
+	switch {
+	case o.MaxResidual > 0 && stats.Residual > o.MaxResidual:
+		return fmt.Errorf("%w: residual %g exceeds %g", ErrUnreliable, stats.Residual, o.MaxResidual)
+	case o.MaxClampedMass > 0 && stats.ClampedMass > o.MaxClampedMass:
+		return fmt.Errorf("%w: clamped mass %g exceeds %g", ErrUnreliable, stats.ClampedMass, o.MaxClampedMass)
+	case o.MaxConditionNumber > 0 && stats.ConditionNumber > o.MaxConditionNumber:
+		return fmt.Errorf("%w: condition number %g exceeds %g", ErrUnreliable, stats.ConditionNumber, o.MaxConditionNumber)
+	}
+	return nil
+}
+
+// withDefaults fills in zero-valued options.
+func (o SolverOptions) withDefaults() SolverOptions {
+	if o.Method == "" {
+		o.Method = MethodDense
+	}
+	if o.Tolerance == 0 {
+		o.Tolerance = defaultTolerance
+	}
+	if o.MaxIterations == 0 {
+		o.MaxIterations = defaultMaxIterations
+	}
+	if o.Relaxation == 0 {
+		o.Relaxation = 1
+	}
+	return o
+}
+
+// SolveSteadyState computes the steady-state probabilities of the CTMC induced by a reachability
+// graph and a set of lambda values, using the method selected in opts.
+// Iterative methods that do not reach the tolerance return an error wrapping ErrNotConverged, and
+// solutions beyond the accuracy limits of opts an error wrapping ErrUnreliable, together with the
+// statistics of the solution.
+func SolveSteadyState(rg *generation.ReachabilityGraph, lambdaValues []float64, opts SolverOptions) ([]float64, *SolverStats, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, nil, err
+	}
+	opts = opts.withDefaults()
+
+	if opts.Method == MethodDense {
+		stateMatrix, targetVector := ComputeStateEquation(rg, lambdaValues)
+		probs, clamped, cond, err := solveStateEquation(stateMatrix, targetVector)
+		if err != nil {
+			return nil, nil, err
+		}
+		g := NewGenerator(rg, lambdaValues)
+		stats := &SolverStats{Method: opts.Method, Residual: g.Residual(probs), Converged: true, ClampedMass: clamped, ConditionNumber: cond}
+		if err := opts.checkAccuracy(stats); err != nil {
+			return nil, stats, err
+		}
+		return probs, stats, nil
+	}
+
+	g := NewGenerator(rg, lambdaValues)
+	if g.N == 0 {
+		return []float64{}, &SolverStats{Method: opts.Method, Converged: true}, nil
+	}
+
+	var pi []float64
+	var iterations int
+	var err error
+	switch opts.Method {
+	case MethodPower:
+		pi, iterations = solvePower(g, opts)
+	case MethodJacobi:
+		pi, iterations, err = solveJacobi(g, opts)
+	case MethodGaussSeidel:
+		opts.Relaxation = 1
+		pi, iterations, err = solveSOR(g, opts)
+	case MethodSOR:
+		pi, iterations, err = solveSOR(g, opts)
+	case MethodBiCGSTAB:
+		pi, iterations, err = solveBiCGSTAB(g, opts)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clamped := clampedMass(pi)
+	normalize(pi)
+	stats := &SolverStats{Method: opts.Method, Iterations: iterations, Residual: g.Residual(pi), ClampedMass: clamped}
+	stats.Converged = stats.Residual <= opts.Tolerance
+	if !stats.Converged {
+		return nil, stats, fmt.Errorf("%s: %w after %d iterations (residual %g)", opts.Method, ErrNotConverged, iterations, stats.Residual)
+	}
+	if err := opts.checkAccuracy(stats); err != nil {
+		return nil, stats, err
+	}
+	return pi, stats, nil
+}
+
+// solvePower runs the power method on the uniformized chain P = I + Q/Λ.
+func solvePower(g *Generator, opts SolverOptions) ([]float64, int) {
+	lambda := 0.0
+	for _, d := range g.Diag {
+		lambda = math.Max(lambda, -d)
+	}
+	pi := uniform(g.N)
+	if lambda == 0 {
+		// No state has an exit rate, so every distribution is stationary.
+		return pi, 0
+	}
+	// Scaling Λ slightly above the largest exit rate keeps P aperiodic.
+	lambda *= 1.02
+
+	piQ := make([]float64, g.N)
+	for k := 1; k <= opts.MaxIterations; k++ {
+		g.MulVec(piQ, pi)
+		residual := 0.0
+		for i := range pi {
+			residual = math.Max(residual, math.Abs(piQ[i]))
+			pi[i] += piQ[i] / lambda
+		}
+		rescale(pi)
+		if residual <= opts.Tolerance {
+			return pi, k
+		}
+	}
+	return pi, opts.MaxIterations
+}
+
+// solveJacobi runs the weighted Jacobi iteration π_i ← (1-ω)π_i - ω Σ_{j≠i} π_j q(j, i) / q(i, i).
+func solveJacobi(g *Generator, opts SolverOptions) ([]float64, int, error) {
+	if err := checkNoAbsorbingStates(g, MethodJacobi); err != nil {
+		return nil, 0, err
+	}
+	omega := opts.Relaxation
+	pi := uniform(g.N)
+	next := make([]float64, g.N)
+	for k := 1; k <= opts.MaxIterations; k++ {
+		for i := 0; i < g.N; i++ {
+			sum := 0.0
+			for p := g.RowPtr[i]; p < g.RowPtr[i+1]; p++ {
+				sum += g.Values[p] * pi[g.ColIdx[p]]
+			}
+			next[i] = (1-omega)*pi[i] + omega*(-sum/g.Diag[i])
+		}
+		pi, next = next, pi
+		rescale(pi)
+		if g.Residual(pi) <= opts.Tolerance {
+			return pi, k, nil
+		}
+	}
+	return pi, opts.MaxIterations, nil
+}
+
+// solveSOR runs successive over-relaxation; with a relaxation factor of 1 it is Gauss–Seidel.
+func solveSOR(g *Generator, opts SolverOptions) ([]float64, int, error) {
+	if err := checkNoAbsorbingStates(g, opts.Method); err != nil {
+		return nil, 0, err
+	}
+	omega := opts.Relaxation
+	pi := uniform(g.N)
+	for k := 1; k <= opts.MaxIterations; k++ {
+		for i := 0; i < g.N; i++ {
+			sum := 0.0
+			for p := g.RowPtr[i]; p < g.RowPtr[i+1]; p++ {
+				sum += g.Values[p] * pi[g.ColIdx[p]]
+			}
+			pi[i] = (1-omega)*pi[i] + omega*(-sum/g.Diag[i])
+		}
+		rescale(pi)
+		if g.Residual(pi) <= opts.Tolerance {
+			return pi, k, nil
+		}
+	}
+	return pi, opts.MaxIterations, nil
+}
+
+// solveBiCGSTAB solves Ax = e_0, where A is Qᵀ with its first equation replaced by the
+// normalization constraint Σπ = 1, using right Jacobi preconditioning.
+func solveBiCGSTAB(g *Generator, opts SolverOptions) ([]float64, int, error) {
+	n := g.N
+	apply := func(dst, x []float64) {
+		g.MulVec(dst, x)
+		sum := 0.0
+		for _, v := range x {
+			sum += v
+		}
+		dst[0] = sum
+	}
+	invDiag := make([]float64, n)
+	invDiag[0] = 1
+	for i := 1; i < n; i++ {
+		invDiag[i] = 1
+		if g.Diag[i] != 0 {
+			invDiag[i] = 1 / g.Diag[i]
+		}
+	}
+	precondition := func(dst, x []float64) {
+		for i := range x {
+			dst[i] = x[i] * invDiag[i]
+		}
+	}
+	// The linear residual only bounds πQ up to the normalization, so candidates are accepted on
+	// the residual of the normalized distribution, like the other methods.
+	candidate := make([]float64, n)
+	accepted := func(x []float64) bool {
+		copy(candidate, x)
+		normalize(candidate)
+		return g.Residual(candidate) <= opts.Tolerance
+	}
+	converged := func(x, r []float64) bool {
+		return norm(r) <= opts.Tolerance && accepted(x)
+	}
+
+	// Starting from zero makes the initial residual e_0, which cannot be orthogonal to the first
+	// search direction because the normalization row of A is all ones.
+	x := make([]float64, n)
+	r := make([]float64, n)
+	r[0] = 1
+	rHat := make([]float64, n)
+	copy(rHat, r)
+
+	p := make([]float64, n)
+	v := make([]float64, n)
+	y := make([]float64, n)
+	s := make([]float64, n)
+	xs := make([]float64, n)
+	z := make([]float64, n)
+	t := make([]float64, n)
+	rho, alpha, omega := 1.0, 1.0, 1.0
+
+	if converged(x, r) {
+		return x, 0, nil
+	}
+	for k := 1; k <= opts.MaxIterations; k++ {
+		rhoNext := dot(rHat, r)
+		if rhoNext == 0 {
+			return x, k, fmt.Errorf("%s: breakdown after %d iterations", MethodBiCGSTAB, k)
+		}
+		beta := (rhoNext / rho) * (alpha / omega)
+		rho = rhoNext
+		for i := range p {
+			p[i] = r[i] + beta*(p[i]-omega*v[i])
+		}
+		precondition(y, p)
+		apply(v, y)
+		alpha = rho / dot(rHat, v)
+		if math.IsNaN(alpha) || math.IsInf(alpha, 0) {
+			return x, k, fmt.Errorf("%s: breakdown after %d iterations", MethodBiCGSTAB, k)
+		}
+		for i := range s {
+			s[i] = r[i] - alpha*v[i]
+		}
+		if norm(s) <= opts.Tolerance {
+			for i := range x {
+				xs[i] = x[i] + alpha*y[i]
+			}
+			if accepted(xs) {
+				return xs, k, nil
+			}
+		}
+		precondition(z, s)
+		apply(t, z)
+		omega = dot(t, s) / dot(t, t)
+		if math.IsNaN(omega) || math.IsInf(omega, 0) {
+			return x, k, fmt.Errorf("%s: breakdown after %d iterations", MethodBiCGSTAB, k)
+		}
+		for i := range x {
+			x[i] += alpha*y[i] + omega*z[i]
+			r[i] = s[i] - omega*t[i]
+		}
+		if converged(x, r) {
+			return x, k, nil
+		}
+		if omega == 0 {
+			return x, k, fmt.Errorf("%s: breakdown after %d iterations", MethodBiCGSTAB, k)
+		}
+	}
+	return x, opts.MaxIterations, nil
+}
+
+// checkNoAbsorbingStates reports an error if the sweep methods would divide by a zero exit rate.
+func checkNoAbsorbingStates(g *Generator, method string) error {
+	for i, d := range g.Diag {
+		if d == 0 {
+			return fmt.Errorf("%s requires every marking to have an exit rate, marking %d is absorbing", method, i)
+		}
+	}
+	return nil
+}
+
+// clampedMass returns the total magnitude of the negative components of probs relative to the
+// sum of its positive components, which is the mass normalize discards.
+func clampedMass(probs []float64) float64 {
+	negative, positive := 0.0, 0.0
+	for _, p := range probs {
+		if p < 0 {
+			negative -= p
+		} else {
+			positive += p
+		}
+	}
+	if positive == 0 {
+		return 0
+	}
+	return negative / positive
+}
+
+// rescale scales the vector to sum to one. Unlike normalize it keeps negative entries, so the
+// iterative methods return them to SolveSteadyState, which clamps them once and reports their
+// mass.
+func rescale(probs []float64) {
+	sum := 0.0
+	for _, p := range probs {
+		sum += p
+	}
+	if sum != 0 {
+		for i := range probs {
+			probs[i] /= sum
+		}
+	}
+}
+
+// normalize clamps negative entries to zero and rescales the vector to sum to one.
+func normalize(probs []float64) {
+	sum := 0.0
+	for i, p := range probs {
+		if p < 0 {
+			probs[i] = 0
+			continue
+		}
+		sum += p
+	}
+	if sum > 0 {
+		for i := range probs {
+			probs[i] /= sum
+		}
+	}
+}
+
+// uniform returns the uniform distribution over n states.
+func uniform(n int) []float64 {
+	v := make([]float64, n)
+	for i := range v {
+		v[i] = 1 / float64(n)
+	}
+	return v
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// norm returns the infinity norm of v.
+func norm(v []float64) float64 {
+	m := 0.0
+	for _, x := range v {
+		m = math.Max(m, math.Abs(x))
+	}
+	return m
+}
@@ -0,0 +1,250 @@
+package analysis
+
+import (
+	"errors"
+	"math"
+	"spn-benchmark-ds/internal/pkg/generation"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+	"testing"
+)
+
+// cyclicGraph returns the reachability graph of a three-state chain 0 -> 1 -> 2 -> 0 with an
+// extra arc 1 -> 0.
+func cyclicGraph() *generation.ReachabilityGraph {
+	return &generation.ReachabilityGraph{
+		Vertices:       []int{2, 0, 1, 1, 0, 2},
+		Edges:          []int{0, 1, 1, 2, 2, 0, 1, 0},
+		VerticesStride: 2,
+		EdgesStride:    2,
+		NumVertices:    3,
+		NumEdges:       4,
+		ArcTransitions: []int{0, 1, 2, 3},
+		IsBounded:      true,
+	}
+}
+
+func TestSolveSteadyStateMethodsAgree(t *testing.T) {
+	rg := cyclicGraph()
+	lambdaValues := []float64{1.0, 2.0, 3.0, 4.0}
+
+	// Balance equations: π0 = 4π1 + 3π2, 6π1 = π0, 3π2 = 2π1.
+	expected := []float64{18.0 / 23.0, 3.0 / 23.0, 2.0 / 23.0}
+
+	methods := []string{MethodDense, MethodPower, MethodJacobi, MethodGaussSeidel, MethodSOR, MethodBiCGSTAB}
+	for _, method := range methods {
+		t.Run(method, func(t *testing.T) {
+			opts := SolverOptions{Method: method, Tolerance: 1e-12, MaxIterations: 100000, Relaxation: 1.2}
+			probs, stats, err := SolveSteadyState(rg, lambdaValues, opts)
+			if err != nil {
+				t.Fatalf("Error solving with %s: %v", method, err)
+			}
+			for i, p := range expected {
+				if math.Abs(probs[i]-p) > 1e-9 {
+					t.Errorf("Expected probability %d to be %f, but got %f", i, p, probs[i])
+				}
+			}
+			if !stats.Converged {
+				t.Errorf("Expected %s to converge", method)
+			}
+			if stats.Residual > 1e-9 {
+				t.Errorf("Expected a small residual, but got %g", stats.Residual)
+			}
+		})
+	}
+}
+
+func TestSolveSteadyStateReportsNonConvergence(t *testing.T) {
+	opts := SolverOptions{Method: MethodPower, Tolerance: 1e-14, MaxIterations: 2}
+	_, stats, err := SolveSteadyState(cyclicGraph(), []float64{1.0, 2.0, 3.0, 4.0}, opts)
+	if !errors.Is(err, ErrNotConverged) {
+		t.Fatalf("Expected ErrNotConverged, but got %v", err)
+	}
+	if stats == nil || stats.Iterations != 2 || stats.Converged {
+		t.Errorf("Expected statistics for 2 unconverged iterations, but got %+v", stats)
+	}
+}
+
+func TestSolveSteadyStateRejectsAbsorbingStatesForSweeps(t *testing.T) {
+	// P1 -> T1 -> P2: marking 1 is absorbing.
+	rg := &generation.ReachabilityGraph{
+		Vertices:       []int{1, 0, 0, 1},
+		Edges:          []int{0, 1},
+		VerticesStride: 2,
+		EdgesStride:    2,
+		NumVertices:    2,
+		NumEdges:       1,
+		ArcTransitions: []int{0},
+		IsBounded:      true,
+	}
+	if _, _, err := SolveSteadyState(rg, []float64{1.0}, SolverOptions{Method: MethodGaussSeidel}); err == nil {
+		t.Errorf("Expected an error for an absorbing marking")
+	}
+
+	// The power method handles absorbing markings: all mass ends up in marking 1.
+	probs, _, err := SolveSteadyState(rg, []float64{1.0}, SolverOptions{Method: MethodPower})
+	if err != nil {
+		t.Fatalf("Error solving with the power method: %v", err)
+	}
+	if !float64Equals(probs[1], 1.0) {
+		t.Errorf("Expected probability 1 for the absorbing marking, but got %f", probs[1])
+	}
+}
+
+func TestSolverOptionsValidate(t *testing.T) {
+	if err := (SolverOptions{Method: "cholesky"}).Validate(); err == nil {
+		t.Errorf("Expected an error for an unknown method")
+	}
+	if err := (SolverOptions{Method: MethodSOR, Relaxation: 2.5}).Validate(); err == nil {
+		t.Errorf("Expected an error for a relaxation factor outside (0, 2)")
+	}
+	if err := (SolverOptions{MaxResidual: -1}).Validate(); err == nil {
+		t.Errorf("Expected an error for a negative accuracy limit")
+	}
+	if err := (SolverOptions{Method: MethodPower, MaxConditionNumber: 1e12}).Validate(); err == nil {
+		t.Errorf("Expected an error for a condition number limit on an iterative method")
+	}
+	if err := (SolverOptions{MaxConditionNumber: 1e12}).Validate(); err != nil {
+		t.Errorf("Expected a condition number limit to be valid for the dense method, but got %v", err)
+	}
+	if err := (SolverOptions{}).Validate(); err != nil {
+		t.Errorf("Expected the zero value to be valid, but got %v", err)
+	}
+}
+
+func TestSolveSteadyStateReportsAccuracy(t *testing.T) {
+	lambdaValues := []float64{1.0, 2.0, 3.0, 4.0}
+	_, stats, err := SolveSteadyState(cyclicGraph(), lambdaValues, SolverOptions{Method: MethodDense})
+	if err != nil {
+		t.Fatalf("Error solving: %v", err)
+	}
+	if stats.ConditionNumber < 1 || math.IsInf(stats.ConditionNumber, 0) {
+		t.Errorf("Expected a finite condition number of at least 1, but got %g", stats.ConditionNumber)
+	}
+	if stats.ClampedMass != 0 {
+		t.Errorf("Expected no clamped mass, but got %g", stats.ClampedMass)
+	}
+
+	// A solution that converges to a loose tolerance is rejected by a tighter residual limit.
+	opts := SolverOptions{Method: MethodJacobi, Tolerance: 1e-3, MaxResidual: 1e-12}
+	_, stats, err = SolveSteadyState(cyclicGraph(), lambdaValues, opts)
+	if !errors.Is(err, ErrUnreliable) {
+		t.Fatalf("Expected ErrUnreliable, but got %v", err)
+	}
+	if stats == nil || !stats.Converged || stats.Residual <= opts.MaxResidual {
+		t.Errorf("Expected statistics of a converged solution above the residual limit, but got %+v", stats)
+	}
+
+	opts = SolverOptions{Method: MethodDense, MaxConditionNumber: 1}
+	if _, _, err := SolveSteadyState(cyclicGraph(), lambdaValues, opts); !errors.Is(err, ErrUnreliable) {
+		t.Errorf("Expected ErrUnreliable for a condition number above the limit, but got %v", err)
+	}
+}
+
+func TestSolveSteadyStateReportsClampedMass(t *testing.T) {
+	// Marking 0 is transient: T0 leaves it for the cycle 1 <-> 2. Over-relaxation with ω > 1 makes
+	// its probability alternate in sign as it decays, so the solution is clamped.
+	rg := &generation.ReachabilityGraph{
+		Vertices:       []int{1, 0, 0, 1, 0, 0},
+		Edges:          []int{0, 1, 1, 2, 2, 1},
+		VerticesStride: 2,
+		EdgesStride:    2,
+		NumVertices:    3,
+		NumEdges:       3,
+		ArcTransitions: []int{0, 1, 2},
+		IsBounded:      true,
+	}
+	opts := SolverOptions{Method: MethodSOR, Tolerance: 1e-3, Relaxation: 1.2}
+	probs, stats, err := SolveSteadyState(rg, []float64{1.0, 1.0, 1.0}, opts)
+	if err != nil {
+		t.Fatalf("Error solving: %v", err)
+	}
+	if stats.ClampedMass <= 0 {
+		t.Errorf("Expected a positive clamped mass, but got %g", stats.ClampedMass)
+	}
+	if probs[0] != 0 {
+		t.Errorf("Expected the clamped probability of marking 0 to be 0, but got %g", probs[0])
+	}
+
+	opts.MaxClampedMass = stats.ClampedMass / 2
+	if _, _, err := SolveSteadyState(rg, []float64{1.0, 1.0, 1.0}, opts); !errors.Is(err, ErrUnreliable) {
+		t.Errorf("Expected ErrUnreliable for a clamped mass above the limit, but got %v", err)
+	}
+}
+
+func TestClampedMass(t *testing.T) {
+	probs := []float64{0.6, -0.1, 0.5}
+	if got := clampedMass(probs); !float64Equals(got, 0.1/1.1) {
+		t.Errorf("Expected clamped mass %f, but got %f", 0.1/1.1, got)
+	}
+	if got := clampedMass([]float64{0.5, 0.5}); got != 0 {
+		t.Errorf("Expected no clamped mass, but got %f", got)
+	}
+}
+
+func TestSolveSteadyStateScalesRatesByArcProbabilities(t *testing.T) {
+	// Tangible graph of a GSPN where T0 leaves marking 0 and a weighted immediate choice sends the
+	// token to marking 1 with probability 1/4 or to marking 2 with probability 3/4.
+	rg := &generation.ReachabilityGraph{
+		Vertices:         []int{1, 0, 0, 0, 1, 0, 0, 0, 1},
+		Edges:            []int{0, 1, 0, 2, 1, 0, 2, 0},
+		VerticesStride:   3,
+		EdgesStride:      2,
+		NumVertices:      3,
+		NumEdges:         4,
+		ArcTransitions:   []int{0, 0, 1, 2},
+		ArcProbabilities: []float64{0.25, 0.75, 1, 1},
+		IsBounded:        true,
+	}
+	lambdaValues := []float64{1.0, 2.0, 4.0}
+
+	// Balance equations: 2π1 = π0/4, 4π2 = 3π0/4.
+	expected := []float64{16.0 / 21.0, 2.0 / 21.0, 3.0 / 21.0}
+
+	for _, method := range []string{MethodDense, MethodGaussSeidel} {
+		probs, _, err := SolveSteadyState(rg, lambdaValues, SolverOptions{Method: method, Tolerance: 1e-12})
+		if err != nil {
+			t.Fatalf("Error solving with %s: %v", method, err)
+		}
+		for i, p := range expected {
+			if math.Abs(probs[i]-p) > 1e-9 {
+				t.Errorf("%s: expected probability %d to be %f, but got %f", method, i, p, probs[i])
+			}
+		}
+	}
+}
+
+func TestSolveSteadyStateWithWeightedArcs(t *testing.T) {
+	// T0 consumes two tokens from P1 and produces one in P2; T1 consumes one from P2 and returns
+	// two to P1. From (4,0) this is a birth-death chain over (4,0), (2,1), (0,2).
+	pn := petrinet.NewPetriNet(2, 2)
+	pn.Matrix = []int{
+		2, 0, 0, 2, 4,
+		0, 1, 1, 0, 0,
+	}
+	pn.InitialMarking = []int{4, 0}
+	rg, err := generation.GenerateReachabilityGraph(pn, 10, 100)
+	if err != nil {
+		t.Fatalf("Error generating reachability graph: %v", err)
+	}
+	lambdaValues := []float64{2.0, 1.0}
+
+	// π(k+1) = π(k)·λ0/λ1.
+	expected := []float64{1.0 / 7.0, 2.0 / 7.0, 4.0 / 7.0}
+	probs, _, err := SolveSteadyState(rg, lambdaValues, SolverOptions{Method: MethodDense})
+	if err != nil {
+		t.Fatalf("Error solving for steady state: %v", err)
+	}
+	for i, p := range expected {
+		if math.Abs(probs[i]-p) > 1e-9 {
+			t.Errorf("Expected probability %d to be %f, but got %f", i, p, probs[i])
+		}
+	}
+
+	avgMarkings, _ := ComputeAverageMarkings(rg, probs)
+	expectedAvg := []float64{(4*1 + 2*2) / 7.0, (1*2 + 2*4) / 7.0}
+	for i, m := range expectedAvg {
+		if math.Abs(avgMarkings[i]-m) > 1e-9 {
+			t.Errorf("Expected average marking %d to be %f, but got %f", i, m, avgMarkings[i])
+		}
+	}
+}
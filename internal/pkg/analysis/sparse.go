@@ -0,0 +1,124 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"spn-benchmark-ds/internal/pkg/generation"
+)
+
+// Generator is the infinitesimal generator Q of the CTMC induced by a reachability graph.
+// It is stored transposed in compressed sparse row (CSR) form, so row i holds the rates q(j, i)
+// into state i. This is the layout needed to evaluate πQ and to sweep over the balance equations.
+// The diagonal is kept separately in Diag.
+type Generator struct {
+	// N is the number of states.
+	N int
+	// RowPtr holds the offset of each row in ColIdx and Values; row i spans [RowPtr[i], RowPtr[i+1]).
+	RowPtr []int
+	// ColIdx holds the source state j of each off-diagonal entry.
+	ColIdx []int
+	// Values holds the off-diagonal rates q(j, i).
+	Values []float64
+	// Diag holds the diagonal entries q(i, i), i.e. minus the total exit rate of each state.
+	Diag []float64
+}
+
+// NewGenerator builds the sparse generator straight from the edges of a reachability graph.
+// Arc rates are scaled by the arc probabilities of tangible reachability graphs.
+// Parallel arcs between the same pair of markings are merged and self-loops are dropped, since
+// they leave the chain unchanged.
+func NewGenerator(rg *generation.ReachabilityGraph, lambdaValues []float64) *Generator {
+	n := rg.NumVertices
+	g := &Generator{
+		N:      n,
+		RowPtr: make([]int, n+1),
+		Diag:   make([]float64, n),
+	}
+
+	// Count entries per destination row first so the CSR arrays are allocated exactly once.
+	for i := 0; i < rg.NumEdges; i++ {
+		src, dest := rg.Edges[i*rg.EdgesStride], rg.Edges[i*rg.EdgesStride+1]
+		if src != dest {
+			g.RowPtr[dest+1]++
+		}
+	}
+	for i := 0; i < n; i++ {
+		g.RowPtr[i+1] += g.RowPtr[i]
+	}
+
+	nnz := g.RowPtr[n]
+	cols := make([]int, nnz)
+	vals := make([]float64, nnz)
+	next := make([]int, n)
+	copy(next, g.RowPtr[:n])
+	for i := 0; i < rg.NumEdges; i++ {
+		src, dest := rg.Edges[i*rg.EdgesStride], rg.Edges[i*rg.EdgesStride+1]
+		if src == dest {
+			continue
+		}
+		rate := lambdaValues[rg.ArcTransitions[i]] * rg.ArcProbability(i)
+		g.Diag[src] -= rate
+		cols[next[dest]] = src
+		vals[next[dest]] = rate
+		next[dest]++
+	}
+
+	// Sort each row by source state and merge duplicates in place.
+	write := 0
+	for i := 0; i < n; i++ {
+		start, end := g.RowPtr[i], g.RowPtr[i+1]
+		row := csrRow{cols: cols[start:end], vals: vals[start:end]}
+		sort.Sort(row)
+		g.RowPtr[i] = write
+		for k := start; k < end; k++ {
+			if write > g.RowPtr[i] && cols[write-1] == cols[k] {
+				vals[write-1] += vals[k]
+				continue
+			}
+			cols[write] = cols[k]
+			vals[write] = vals[k]
+			write++
+		}
+	}
+	g.RowPtr[n] = write
+	g.ColIdx = cols[:write]
+	g.Values = vals[:write]
+	return g
+}
+
+// MulVec computes dst = xQ, treating x as a row vector.
+func (g *Generator) MulVec(dst, x []float64) {
+	for i := 0; i < g.N; i++ {
+		sum := g.Diag[i] * x[i]
+		for k := g.RowPtr[i]; k < g.RowPtr[i+1]; k++ {
+			sum += g.Values[k] * x[g.ColIdx[k]]
+		}
+		dst[i] = sum
+	}
+}
+
+// Residual returns the infinity norm of πQ, which is zero for an exact stationary distribution.
+func (g *Generator) Residual(pi []float64) float64 {
+	residual := 0.0
+	for i := 0; i < g.N; i++ {
+		sum := g.Diag[i] * pi[i]
+		for k := g.RowPtr[i]; k < g.RowPtr[i+1]; k++ {
+			sum += g.Values[k] * pi[g.ColIdx[k]]
+		}
+		residual = math.Max(residual, math.Abs(sum))
+	}
+	return residual
+}
+
+// csrRow sorts the entries of a single CSR row by column.
+type csrRow struct {
+	cols []int
+	vals []float64
+}
+
+func (r csrRow) Len() int           { return len(r.cols) }
+func (r csrRow) Less(i, j int) bool { return r.cols[i] < r.cols[j] }
+func (r csrRow) Swap(i, j int) {
+	r.cols[i], r.cols[j] = r.cols[j], r.cols[i]
+	r.vals[i], r.vals[j] = r.vals[j], r.vals[i]
+}
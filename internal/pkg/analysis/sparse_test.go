@@ -0,0 +1,53 @@
+package analysis
+
+import (
+	"spn-benchmark-ds/internal/pkg/generation"
+	"testing"
+)
+
+func TestNewGenerator(t *testing.T) {
+	// Two parallel arcs 0 -> 1 (transitions 0 and 1), a self-loop on 1 and an arc 1 -> 0.
+	rg := &generation.ReachabilityGraph{
+		Vertices:       []int{1, 0, 0, 1},
+		Edges:          []int{0, 1, 0, 1, 1, 1, 1, 0},
+		VerticesStride: 2,
+		EdgesStride:    2,
+		NumVertices:    2,
+		NumEdges:       4,
+		ArcTransitions: []int{0, 1, 2, 3},
+		IsBounded:      true,
+	}
+	lambdaValues := []float64{1.0, 2.0, 5.0, 4.0}
+
+	g := NewGenerator(rg, lambdaValues)
+
+	expectedDiag := []float64{-3.0, -4.0}
+	for i, d := range expectedDiag {
+		if !float64Equals(g.Diag[i], d) {
+			t.Errorf("Expected Diag[%d] to be %f, but got %f", i, d, g.Diag[i])
+		}
+	}
+	// Row 0 holds the rate 1 -> 0, row 1 the merged rates 0 -> 1.
+	if len(g.Values) != 2 {
+		t.Fatalf("Expected 2 off-diagonal entries, but got %d", len(g.Values))
+	}
+	if g.ColIdx[0] != 1 || !float64Equals(g.Values[0], 4.0) {
+		t.Errorf("Expected entry (1, 4.0) in row 0, but got (%d, %f)", g.ColIdx[0], g.Values[0])
+	}
+	if g.ColIdx[1] != 0 || !float64Equals(g.Values[1], 3.0) {
+		t.Errorf("Expected entry (0, 3.0) in row 1, but got (%d, %f)", g.ColIdx[1], g.Values[1])
+	}
+
+	// The stationary distribution of this chain is (4/7, 3/7).
+	pi := []float64{4.0 / 7.0, 3.0 / 7.0}
+	piQ := make([]float64, 2)
+	g.MulVec(piQ, pi)
+	for i, v := range piQ {
+		if !float64Equals(v, 0) {
+			t.Errorf("Expected (πQ)[%d] to be 0, but got %f", i, v)
+		}
+	}
+	if residual := g.Residual(pi); !float64Equals(residual, 0) {
+		t.Errorf("Expected a zero residual, but got %g", residual)
+	}
+}
@@ -0,0 +1,194 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"spn-benchmark-ds/internal/pkg/generation"
+)
+
+// DefaultTransientEpsilon is the truncation error bound of the transient analysis used when none
+// is given.
+const DefaultTransientEpsilon = 1e-9
+
+// TransientResult holds the state of the CTMC at a set of time points.
+type TransientResult struct {
+	// Times holds the time points, in the order they were requested.
+	Times []float64 `json:"times"`
+	// Probs holds the probability of each marking at each time point.
+	Probs [][]float64 `json:"probs"`
+	// AverageMarkings holds the expected number of tokens in each place at each time point.
+	AverageMarkings [][]float64 `json:"average_markings"`
+}
+
+// PoissonWeights holds the Poisson probabilities e^{-λ}λ^k/k! for k in [Left, Right], the range
+// outside of which the remaining probability mass is below the requested error bound.
+type PoissonWeights struct {
+	// Left is the left truncation point.
+	Left int
+	// Right is the right truncation point.
+	Right int
+	// Weights holds the probability of k at index k-Left, normalized to sum to one.
+	Weights []float64
+}
+
+// FoxGlynn computes the Poisson weights for the rate lambda, truncated so that the probability
+// mass outside [Left, Right] is at most epsilon. Following Fox and Glynn, the weights are computed
+// from the mode outwards with the recurrences w(k-1) = w(k)·k/λ and w(k+1) = w(k)·λ/(k+1) and
+// normalized afterwards, so they neither underflow nor overflow for large λ. Each side stops once a
+// geometric bound on its remaining tail falls below epsilon/2.
+func FoxGlynn(lambda, epsilon float64) (*PoissonWeights, error) {
+	if lambda < 0 || math.IsNaN(lambda) || math.IsInf(lambda, 0) {
+		return nil, fmt.Errorf("Poisson rate must be finite and not negative, got %g", lambda)
+	}
+	if epsilon <= 0 || epsilon >= 1 {
+		return nil, fmt.Errorf("truncation error bound must be in (0, 1), got %g", epsilon)
+	}
+	if lambda == 0 {
+		return &PoissonWeights{Weights: []float64{1}}, nil
+	}
+
+	mode := int(math.Floor(lambda))
+	total := 1.0
+
+	// Below the mode the ratio w(j-1)/w(j) = j/λ only shrinks, so the tail below k is at most
+	// w(k)·r/(1-r) with r = k/λ.
+	var below []float64
+	left := mode
+	for w := 1.0; left > 0; {
+		w *= float64(left) / lambda
+		left--
+		below = append(below, w)
+		total += w
+		if r := float64(left) / lambda; w == 0 || w*r/(1-r) <= epsilon/2*total {
+			break
+		}
+	}
+
+	// Above the mode the ratio w(j+1)/w(j) = λ/(j+1) only shrinks, so the tail above k is at most
+	// w(k)·r/(1-r) with r = λ/(k+1), which is below one since k+1 > mode+1 > λ.
+	var above []float64
+	right := mode
+	for w := 1.0; ; {
+		w *= lambda / float64(right+1)
+		right++
+		above = append(above, w)
+		total += w
+		if r := lambda / float64(right+1); w == 0 || w*r/(1-r) <= epsilon/2*total {
+			break
+		}
+	}
+
+	weights := make([]float64, 0, right-left+1)
+	for i := len(below) - 1; i >= 0; i-- {
+		weights = append(weights, below[i]/total)
+	}
+	weights = append(weights, 1/total)
+	for _, w := range above {
+		weights = append(weights, w/total)
+	}
+	return &PoissonWeights{Left: left, Right: right, Weights: weights}, nil
+}
+
+// SolveTransient computes the probability of each marking at the given time points for the CTMC
+// induced by a reachability graph and a set of lambda values, by uniformization:
+// π(t) = Σ_k Poisson(k; Λt) π(0)Pᵏ with P = I + Q/Λ and Λ the largest exit rate.
+// The chain starts in the initial distribution of the graph, or in vertex 0 when it has none.
+// The time points may be given in any order; the chain is advanced between them in increasing
+// order, and each step gets an equal share of epsilon so that every returned distribution is within
+// epsilon of the exact one in the 1-norm. Zero or less selects DefaultTransientEpsilon.
+// The cost of each step grows linearly with Λ times its length.
+func SolveTransient(rg *generation.ReachabilityGraph, lambdaValues []float64, times []float64, epsilon float64) ([][]float64, error) {
+	if err := validateTransient(times, epsilon); err != nil {
+		return nil, err
+	}
+	if epsilon <= 0 {
+		epsilon = DefaultTransientEpsilon
+	}
+
+	g := NewGenerator(rg, lambdaValues)
+	pi := initialDistribution(rg, g.N)
+	rate := 0.0
+	for _, d := range g.Diag {
+		rate = math.Max(rate, -d)
+	}
+
+	order := make([]int, len(times))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return times[order[a]] < times[order[b]] })
+
+	result := make([][]float64, len(times))
+	now := 0.0
+	for _, i := range order {
+		if dt := times[i] - now; dt > 0 && rate > 0 {
+			next, err := uniformize(g, rate, pi, dt, epsilon/float64(len(times)))
+			if err != nil {
+				return nil, err
+			}
+			pi = next
+		}
+		now = times[i]
+		result[i] = append([]float64(nil), pi...)
+	}
+	return result, nil
+}
+
+// uniformize advances the distribution pi of the chain g, uniformized at rate, by dt.
+func uniformize(g *Generator, rate float64, pi []float64, dt, epsilon float64) ([]float64, error) {
+	poisson, err := FoxGlynn(rate*dt, epsilon)
+	if err != nil {
+		return nil, err
+	}
+	v := append([]float64(nil), pi...)
+	vQ := make([]float64, g.N)
+	result := make([]float64, g.N)
+	for k := 0; k <= poisson.Right; k++ {
+		if k >= poisson.Left {
+			w := poisson.Weights[k-poisson.Left]
+			for i := range result {
+				result[i] += w * v[i]
+			}
+		}
+		if k == poisson.Right {
+			break
+		}
+		g.MulVec(vQ, v)
+		for i := range v {
+			v[i] += vQ[i] / rate
+		}
+	}
+	return result, nil
+}
+
+// validateTransient checks the time points and error bound of a transient analysis.
+func validateTransient(times []float64, epsilon float64) error {
+	for _, t := range times {
+		if t < 0 || math.IsNaN(t) || math.IsInf(t, 0) {
+			return fmt.Errorf("transient time points must be finite and not negative, got %g", t)
+		}
+	}
+	if epsilon >= 1 || math.IsNaN(epsilon) {
+		return fmt.Errorf("transient error bound must be below 1, got %g", epsilon)
+	}
+	return nil
+}
+
+// ComputeTransient solves the transient distributions at the given time points and derives the
+// expected number of tokens in each place at each of them.
+func ComputeTransient(rg *generation.ReachabilityGraph, lambdaValues []float64, times []float64, epsilon float64) (*TransientResult, error) {
+	probs, err := SolveTransient(rg, lambdaValues, times, epsilon)
+	if err != nil {
+		return nil, err
+	}
+	result := &TransientResult{
+		Times:           append([]float64(nil), times...),
+		Probs:           probs,
+		AverageMarkings: make([][]float64, len(times)),
+	}
+	for i, p := range probs {
+		result.AverageMarkings[i], _ = ComputeAverageMarkings(rg, p)
+	}
+	return result, nil
+}
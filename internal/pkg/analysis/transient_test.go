@@ -0,0 +1,121 @@
+package analysis
+
+import (
+	"math"
+	"spn-benchmark-ds/internal/pkg/generation"
+	"testing"
+)
+
+// twoStateGraph returns the reachability graph of a chain that moves from state 0 to state 1 with
+// transition 0 and back with transition 1.
+func twoStateGraph() *generation.ReachabilityGraph {
+	return &generation.ReachabilityGraph{
+		Vertices:       []int{1, 0, 0, 1},
+		Edges:          []int{0, 1, 1, 0},
+		VerticesStride: 2,
+		EdgesStride:    2,
+		NumVertices:    2,
+		NumEdges:       2,
+		ArcTransitions: []int{0, 1},
+		IsBounded:      true,
+	}
+}
+
+func TestFoxGlynn(t *testing.T) {
+	for _, lambda := range []float64{0, 0.5, 3, 40, 1000, 1e5} {
+		for _, epsilon := range []float64{1e-3, 1e-9} {
+			poisson, err := FoxGlynn(lambda, epsilon)
+			if err != nil {
+				t.Fatalf("λ=%g: %v", lambda, err)
+			}
+			if len(poisson.Weights) != poisson.Right-poisson.Left+1 {
+				t.Fatalf("λ=%g: expected %d weights, got %d", lambda, poisson.Right-poisson.Left+1, len(poisson.Weights))
+			}
+			// Compare against the Poisson probabilities computed in log space; the mass outside
+			// the truncation points is at most epsilon.
+			mass := 0.0
+			for k := poisson.Left; k <= poisson.Right; k++ {
+				lgamma, _ := math.Lgamma(float64(k + 1))
+				p := math.Exp(-lambda + float64(k)*math.Log(lambda) - lgamma)
+				if lambda == 0 {
+					p = 1
+				}
+				mass += p
+				if w := poisson.Weights[k-poisson.Left]; math.Abs(w-p) > epsilon+1e-12 {
+					t.Errorf("λ=%g: weight of %d is %g, expected %g", lambda, k, w, p)
+				}
+			}
+			if mass < 1-epsilon-1e-9 {
+				t.Errorf("λ=%g, ε=%g: truncated mass %g is below 1-ε", lambda, epsilon, mass)
+			}
+		}
+	}
+
+	if _, err := FoxGlynn(-1, 1e-6); err == nil {
+		t.Errorf("Expected an error for a negative rate")
+	}
+	if _, err := FoxGlynn(1, 0); err == nil {
+		t.Errorf("Expected an error for a zero error bound")
+	}
+}
+
+func TestSolveTransientTwoStateChain(t *testing.T) {
+	lambda, mu := 2.0, 3.0
+	times := []float64{1, 0, 0.25, 5}
+	probs, err := SolveTransient(twoStateGraph(), []float64{lambda, mu}, times, 1e-12)
+	if err != nil {
+		t.Fatalf("Error solving transient: %v", err)
+	}
+	for i, tm := range times {
+		expected := mu/(lambda+mu) + lambda/(lambda+mu)*math.Exp(-(lambda+mu)*tm)
+		if math.Abs(probs[i][0]-expected) > 1e-10 || math.Abs(probs[i][1]-(1-expected)) > 1e-10 {
+			t.Errorf("t=%g: expected [%f %f], got %v", tm, expected, 1-expected, probs[i])
+		}
+	}
+
+	rg := twoStateGraph()
+	rg.InitialDistribution = []float64{0, 1}
+	probs, err = SolveTransient(rg, []float64{lambda, mu}, []float64{0.5}, 0)
+	if err != nil {
+		t.Fatalf("Error solving transient: %v", err)
+	}
+	expected := mu/(lambda+mu) - mu/(lambda+mu)*math.Exp(-(lambda+mu)*0.5)
+	if math.Abs(probs[0][0]-expected) > 1e-8 {
+		t.Errorf("Expected the chain to start in its initial distribution: p0(0.5) = %f, got %f", expected, probs[0][0])
+	}
+
+	if _, err := SolveTransient(rg, []float64{lambda, mu}, []float64{-1}, 0); err == nil {
+		t.Errorf("Expected an error for a negative time point")
+	}
+}
+
+func TestAnalyzeTransientConvergesToSteadyState(t *testing.T) {
+	rg := cyclicGraph()
+	lambdaValues := []float64{1.0, 2.0, 3.0, 4.0}
+	result, _, err := Analyze(rg, lambdaValues, Options{TransientTimes: []float64{0, 50}})
+	if err != nil {
+		t.Fatalf("Error analyzing: %v", err)
+	}
+	transient := result.Transient
+	if transient == nil || len(transient.Probs) != 2 || len(transient.AverageMarkings) != 2 {
+		t.Fatalf("Expected transient results for two time points, got %+v", transient)
+	}
+	if transient.Probs[0][0] != 1 || transient.AverageMarkings[0][0] != 2 {
+		t.Errorf("Expected the chain to start in vertex 0, got %v and %v", transient.Probs[0], transient.AverageMarkings[0])
+	}
+	for i, p := range result.SteadyStateProbs {
+		if math.Abs(transient.Probs[1][i]-p) > 1e-8 {
+			t.Errorf("Expected probability %d at t=50 to be the steady state %f, got %f", i, p, transient.Probs[1][i])
+		}
+	}
+	for p, m := range result.AverageMarkings {
+		if math.Abs(transient.AverageMarkings[1][p]-m) > 1e-8 {
+			t.Errorf("Expected average marking %d at t=50 to be %f, got %f", p, m, transient.AverageMarkings[1][p])
+		}
+	}
+
+	result, _, err = Analyze(rg, lambdaValues, Options{})
+	if err != nil || result.Transient != nil {
+		t.Errorf("Expected no transient results without time points, got %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,22 @@
+// Package arrowipc writes and reads protobuf messages as rows of Apache Arrow IPC files, the
+// columnar format read by pyarrow, polars and the other Arrow implementations. The files are
+// encoded and decoded by the Go implementation of Arrow.
+//
+// The Arrow schema is derived from the message descriptor: every field becomes a column named
+// after it. Repeated fields are lists, and message fields are nullable structs, except for
+// messages holding nothing but one repeated field, such as a marking, which become that list
+// directly. int32, int64, double, bool and string fields map to the Arrow types of the same names.
+package arrowipc
+
+import (
+	"errors"
+)
+
+// DefaultBatchSize is the number of rows of the record batches written by default.
+const DefaultBatchSize = 1024
+
+// ErrMalformed is returned when a file is not a well-formed Arrow IPC file.
+var ErrMalformed = errors.New("malformed Arrow IPC file")
+
+// ErrUnsupported is returned for message fields that have no column type.
+var ErrUnsupported = errors.New("unsupported Arrow IPC feature")
@@ -0,0 +1,204 @@
+package arrowipc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"spn-benchmark-ds/internal/pkg/spn"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"google.golang.org/protobuf/proto"
+)
+
+// testSamples returns samples with nested, repeated, unset and string fields.
+func testSamples() []*spn.SPNData {
+	return []*spn.SPNData{
+		{
+			PetriNet: &spn.PetriNet{
+				Matrix:      []int32{1, 0, -1, 0, 1, 1},
+				Places:      2,
+				Transitions: 1,
+				Immediate:   []bool{false},
+				Delays:      []*spn.Delay{{Kind: "erlang", Stages: 3, Scv: 1.0 / 3}},
+			},
+			ReachabilityGraph: &spn.ReachabilityGraph{
+				Vertices:       []*spn.Vertex{{Marking: []int32{1, 0}}, {Marking: []int32{0, 1}}},
+				Edges:          []*spn.Edge{{Src: 0, Dest: 1}, {Src: 1, Dest: 0}},
+				ArcTransitions: []int32{0, 0},
+			},
+			LambdaValues:     []float64{0.5},
+			MarkingDensities: []*spn.MarkingDensity{{Densities: []float64{0.25, 0.75}}, {}},
+			Simulation: &spn.Simulation{
+				MarkingDensities: []*spn.EstimateList{{Estimates: []*spn.Estimate{{Mean: 0.4, HalfWidth: 0.01}}}},
+				Firings:          1 << 40,
+			},
+		},
+		{},
+		{
+			LambdaValues: []float64{1, 2, 3},
+			Rewards:      []*spn.RewardValue{{Name: "utilization", SteadyState: 0.9}, {Name: ""}},
+			SolverStats:  &spn.SolverStats{Method: "gmres", Converged: true},
+		},
+	}
+}
+
+// writeFile writes the samples to an Arrow IPC file in batches of the given size.
+func writeFile(t *testing.T, samples []*spn.SPNData, batchSize int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, (&spn.SPNData{}).ProtoReflect().Descriptor(), batchSize)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	for _, sample := range samples {
+		if err := writer.Write(sample); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if writer.Count() != int64(len(samples)) {
+		t.Errorf("expected %d rows, got %d", len(samples), writer.Count())
+	}
+	return buf.Bytes()
+}
+
+// readFile reads every row of an Arrow IPC file.
+func readFile(t *testing.T, data []byte) []*spn.SPNData {
+	t.Helper()
+	reader, err := NewReader(bytes.NewReader(data), int64(len(data)), (&spn.SPNData{}).ProtoReflect().Descriptor())
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	var samples []*spn.SPNData
+	for {
+		sample := &spn.SPNData{}
+		err := reader.Next(sample)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		samples = append(samples, sample)
+	}
+	return samples
+}
+
+func TestWriterAndReaderRoundTrip(t *testing.T) {
+	for _, batchSize := range []int{1, 2, DefaultBatchSize} {
+		data := writeFile(t, testSamples(), batchSize)
+		if string(data[:6]) != "ARROW1" || string(data[len(data)-6:]) != "ARROW1" {
+			t.Fatalf("expected the file to start and end with the Arrow magic bytes")
+		}
+		samples := readFile(t, data)
+		if len(samples) != len(testSamples()) {
+			t.Fatalf("expected %d samples with batches of %d, got %d", len(testSamples()), batchSize, len(samples))
+		}
+		for i, expected := range testSamples() {
+			if !proto.Equal(samples[i], expected) {
+				t.Errorf("sample %d with batches of %d: expected %v, got %v", i, batchSize, expected, samples[i])
+			}
+		}
+	}
+	if samples := readFile(t, writeFile(t, nil, 4)); len(samples) != 0 {
+		t.Errorf("expected no samples in an empty file, got %d", len(samples))
+	}
+}
+
+func TestSchema(t *testing.T) {
+	// The file is read back by the Arrow library directly, without the message descriptor.
+	data := writeFile(t, testSamples(), 2)
+	reader, err := ipc.NewFileReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewFileReader failed: %v", err)
+	}
+	defer reader.Close()
+	if reader.NumRecords() != 2 {
+		t.Errorf("expected 2 record batches, got %d", reader.NumRecords())
+	}
+	schema := reader.Schema()
+	field := func(name string) arrow.Field {
+		fields, ok := schema.FieldsByName(name)
+		if !ok || len(fields) != 1 {
+			t.Fatalf("expected a field %s, got %v", name, schema)
+		}
+		return fields[0]
+	}
+	if f := field("lambda_values"); !arrow.TypeEqual(f.Type, arrow.ListOf(arrow.PrimitiveTypes.Float64)) || f.Nullable {
+		t.Errorf("expected lambda_values to be a list of doubles, got %v", f)
+	}
+	net := field("petri_net")
+	if st, ok := net.Type.(*arrow.StructType); !ok || !net.Nullable || st.Field(0).Name != "matrix" {
+		t.Errorf("expected petri_net to be a nullable struct, got %v", net)
+	}
+	// Vertices hold nothing but their marking, so they are lists themselves.
+	vertices := field("reachability_graph").Type.(*arrow.StructType).Field(0)
+	if vertices.Name != "vertices" || !arrow.TypeEqual(vertices.Type, arrow.ListOf(arrow.ListOf(arrow.PrimitiveTypes.Int32))) {
+		t.Errorf("expected vertices to be a list of int32 lists, got %v", vertices)
+	}
+	if f, _ := field("simulation").Type.(*arrow.StructType).FieldByName("firings"); f.Type.ID() != arrow.INT64 {
+		t.Errorf("expected firings to be int64, got %v", f)
+	}
+
+	record, err := reader.Record(0)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	lambdas := record.Column(schema.FieldIndices("lambda_values")[0]).(*array.List)
+	if values := lambdas.ListValues().(*array.Float64).Float64Values(); len(values) != 1 || values[0] != 0.5 {
+		t.Errorf("expected the lambda values [0.5] in the first record batch, got %v", values)
+	}
+	if nets := record.Column(schema.FieldIndices("petri_net")[0]); nets.IsNull(0) || !nets.IsNull(1) {
+		t.Errorf("expected the second sample to have a null net")
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	descriptor := (&spn.SPNData{}).ProtoReflect().Descriptor()
+	data := writeFile(t, testSamples(), 2)
+
+	for _, input := range [][]byte{nil, []byte("ARROW1\x00\x00ARROW1"), data[:len(data)-1]} {
+		if _, err := NewReader(bytes.NewReader(input), int64(len(input)), descriptor); !errors.Is(err, ErrMalformed) {
+			t.Errorf("expected ErrMalformed for %d bytes, got %v", len(input), err)
+		}
+	}
+
+	// A file of other messages does not match the schema.
+	if _, err := NewReader(bytes.NewReader(data), int64(len(data)), (&spn.Edge{}).ProtoReflect().Descriptor()); err == nil {
+		t.Errorf("expected an error for a file of another message")
+	}
+
+	// Damage the messages between the file header and the footer, which locates them.
+	damaged := append([]byte(nil), data...)
+	footerStart := len(damaged) - 10 - int(binary.LittleEndian.Uint32(damaged[len(damaged)-10:]))
+	for i := 8; i < footerStart; i++ {
+		damaged[i] = 0xff
+	}
+	reader, err := NewReader(bytes.NewReader(damaged), int64(len(damaged)), descriptor)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	if err := reader.Next(&spn.SPNData{}); !errors.Is(err, ErrMalformed) {
+		t.Errorf("expected ErrMalformed for a damaged record batch, got %v", err)
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	descriptor := (&spn.SPNData{}).ProtoReflect().Descriptor()
+	if _, err := NewWriter(io.Discard, descriptor, 0); err == nil {
+		t.Errorf("expected an error for an empty batch size")
+	}
+	writer, err := NewWriter(io.Discard, descriptor, 1)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if err := writer.Write(&spn.Edge{}); err == nil {
+		t.Errorf("expected an error for a message of another type")
+	}
+}
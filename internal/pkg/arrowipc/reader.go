@@ -0,0 +1,139 @@
+package arrowipc
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Reader reads the rows of an Arrow IPC file as messages, one record batch at a time.
+type Reader struct {
+	r    *ipc.FileReader
+	desc protoreflect.MessageDescriptor
+
+	// next is the index of the next record batch; record holds the current one, which is valid
+	// until the next one is read.
+	next   int
+	record array.Record
+	row    int
+}
+
+// NewReader reads the footer and the schema of the Arrow IPC file of the given size in r and
+// returns a reader for its rows as messages described by desc. The schema of the file must match
+// the one written for desc.
+func NewReader(r io.ReaderAt, size int64, desc protoreflect.MessageDescriptor) (*Reader, error) {
+	schema, err := schemaOf(desc)
+	if err != nil {
+		return nil, err
+	}
+	var fr *ipc.FileReader
+	err = guard(func() error {
+		var err error
+		fr, err = ipc.NewFileReader(io.NewSectionReader(r, 0, size), ipc.WithAllocator(memory.DefaultAllocator))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if err := matchFields(fr.Schema().Fields(), schema.Fields(), ""); err != nil {
+		return nil, fmt.Errorf("schema does not match %s: %w", desc.FullName(), err)
+	}
+	return &Reader{r: fr, desc: desc}, nil
+}
+
+// Next sets m to the next row, or returns io.EOF after the last one.
+func (r *Reader) Next(m proto.Message) error {
+	for r.record == nil || r.row == int(r.record.NumRows()) {
+		if r.next == r.r.NumRecords() {
+			return io.EOF
+		}
+		err := guard(func() error {
+			var err error
+			if r.record, err = r.r.Record(r.next); err != nil {
+				return fmt.Errorf("%w: %v", ErrMalformed, err)
+			}
+			return nil
+		})
+		if err != nil {
+			r.record = nil
+			return fmt.Errorf("failed to read record batch %d: %w", r.next, err)
+		}
+		r.next++
+		r.row = 0
+	}
+	proto.Reset(m)
+	msg := m.ProtoReflect()
+	err := guard(func() error {
+		fields := r.desc.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			fillField(msg, fields.Get(i), r.record.Column(i), r.row)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read row %d of record batch %d: %w", r.row, r.next-1, err)
+	}
+	r.row++
+	return nil
+}
+
+// fillField sets a field of a message to its value in row i of its column.
+func fillField(msg protoreflect.Message, fd protoreflect.FieldDescriptor, column array.Interface, i int) {
+	if column.IsNull(i) {
+		return
+	}
+	if fd.IsList() {
+		l := column.(*array.List)
+		offsets := l.Offsets()[l.Data().Offset():]
+		values := l.ListValues()
+		list := msg.Mutable(fd).List()
+		for j := offsets[i]; j < offsets[i+1]; j++ {
+			list.Append(value(fd, values, int(j), list.NewElement))
+		}
+		return
+	}
+	msg.Set(fd, value(fd, column, i, func() protoreflect.Value { return msg.NewField(fd) }))
+}
+
+// value returns a single value of a field at index i of a column. newMessage returns the message
+// to fill for message fields.
+func value(fd protoreflect.FieldDescriptor, column array.Interface, i int, newMessage func() protoreflect.Value) protoreflect.Value {
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return protoreflect.ValueOfInt32(column.(*array.Int32).Value(i))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return protoreflect.ValueOfInt64(column.(*array.Int64).Value(i))
+	case protoreflect.DoubleKind:
+		return protoreflect.ValueOfFloat64(column.(*array.Float64).Value(i))
+	case protoreflect.BoolKind:
+		return protoreflect.ValueOfBool(column.(*array.Boolean).Value(i))
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(column.(*array.String).Value(i))
+	}
+	v := newMessage()
+	md := fd.Message()
+	if inner := unwrapped(md); inner != nil {
+		fillField(v.Message(), inner, column, i)
+		return v
+	}
+	s := column.(*array.Struct)
+	for k := 0; k < md.Fields().Len(); k++ {
+		fillField(v.Message(), md.Fields().Get(k), s.Field(k), i)
+	}
+	return v
+}
+
+// guard runs fn, reporting a panic of the Arrow decoder on a damaged file as a malformed file.
+func guard(fn func() error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%w: %v", ErrMalformed, p)
+		}
+	}()
+	return fn()
+}
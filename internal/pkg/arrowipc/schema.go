@@ -0,0 +1,116 @@
+package arrowipc
+
+import (
+	"fmt"
+
+	"github.com/apache/arrow/go/arrow"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// schemaOf derives the Arrow schema of a message.
+func schemaOf(md protoreflect.MessageDescriptor) (*arrow.Schema, error) {
+	fields, err := structFields(md)
+	if err != nil {
+		return nil, err
+	}
+	return arrow.NewSchema(fields, nil), nil
+}
+
+// structFields returns the Arrow fields of the fields of a message.
+func structFields(md protoreflect.MessageDescriptor) ([]arrow.Field, error) {
+	fields := make([]arrow.Field, 0, md.Fields().Len())
+	for i := 0; i < md.Fields().Len(); i++ {
+		fd := md.Fields().Get(i)
+		t, err := fieldType(fd)
+		if err != nil {
+			return nil, err
+		}
+		// Unset message fields are null.
+		nullable := fd.Kind() == protoreflect.MessageKind && !fd.IsList()
+		fields = append(fields, arrow.Field{Name: string(fd.Name()), Type: t, Nullable: nullable})
+	}
+	return fields, nil
+}
+
+// fieldType returns the Arrow type of a protobuf field.
+func fieldType(fd protoreflect.FieldDescriptor) (arrow.DataType, error) {
+	if fd.IsMap() {
+		return nil, fmt.Errorf("%w: map field %s", ErrUnsupported, fd.FullName())
+	}
+	t, err := valueType(fd)
+	if err != nil {
+		return nil, err
+	}
+	if fd.IsList() {
+		return arrow.ListOf(t), nil
+	}
+	return t, nil
+}
+
+// valueType returns the Arrow type of a single value of a protobuf field.
+func valueType(fd protoreflect.FieldDescriptor) (arrow.DataType, error) {
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return arrow.PrimitiveTypes.Int32, nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return arrow.PrimitiveTypes.Int64, nil
+	case protoreflect.DoubleKind:
+		return arrow.PrimitiveTypes.Float64, nil
+	case protoreflect.BoolKind:
+		return arrow.FixedWidthTypes.Boolean, nil
+	case protoreflect.StringKind:
+		return arrow.BinaryTypes.String, nil
+	case protoreflect.MessageKind:
+		if inner := unwrapped(fd.Message()); inner != nil {
+			return fieldType(inner)
+		}
+		fields, err := structFields(fd.Message())
+		if err != nil {
+			return nil, err
+		}
+		return arrow.StructOf(fields...), nil
+	}
+	return nil, fmt.Errorf("%w: %s field %s", ErrUnsupported, fd.Kind(), fd.FullName())
+}
+
+// unwrapped returns the repeated field of a message that holds nothing but that field, whose
+// values are written as the list itself, or nil for other messages.
+func unwrapped(md protoreflect.MessageDescriptor) protoreflect.FieldDescriptor {
+	if md.Fields().Len() == 1 && md.Fields().Get(0).IsList() {
+		return md.Fields().Get(0)
+	}
+	return nil
+}
+
+// matchFields checks that the fields of a file have the names and types of the fields derived
+// from a message. Nullability and the names of list items vary between writers and are not
+// compared.
+func matchFields(file, derived []arrow.Field, path string) error {
+	if len(file) != len(derived) {
+		return fmt.Errorf("the file has %d fields under %q, expected %d", len(file), path, len(derived))
+	}
+	for i, f := range derived {
+		name := path + f.Name
+		if file[i].Name != f.Name {
+			return fmt.Errorf("field %s is named %q in the file", name, file[i].Name)
+		}
+		if err := matchType(file[i].Type, f.Type, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchType checks that a type of a file is the type derived from a message.
+func matchType(file, derived arrow.DataType, name string) error {
+	if file.ID() != derived.ID() {
+		return fmt.Errorf("field %s is %s in the file, expected %s", name, file.Name(), derived.Name())
+	}
+	switch t := derived.(type) {
+	case *arrow.ListType:
+		return matchType(file.(*arrow.ListType).Elem(), t.Elem(), name)
+	case *arrow.StructType:
+		return matchFields(file.(*arrow.StructType).Fields(), t.Fields(), name+".")
+	}
+	return nil
+}
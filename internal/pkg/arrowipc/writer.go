@@ -0,0 +1,164 @@
+package arrowipc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Writer writes messages as the rows of an Arrow IPC file. Rows are buffered and written as
+// record batches of a fixed number of rows; the file is complete once Close writes its footer.
+type Writer struct {
+	w         *ipc.FileWriter
+	desc      protoreflect.MessageDescriptor
+	builder   *array.RecordBuilder
+	batchSize int
+	rows      int
+	count     int64
+}
+
+// NewWriter returns a writer of an Arrow IPC file with the schema of the messages described by
+// desc to w, which writes record batches of batchSize rows.
+func NewWriter(w io.Writer, desc protoreflect.MessageDescriptor, batchSize int) (*Writer, error) {
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+	schema, err := schemaOf(desc)
+	if err != nil {
+		return nil, err
+	}
+	fw, err := ipc.NewFileWriter(&positionWriter{w: w}, ipc.WithSchema(schema), ipc.WithAllocator(memory.DefaultAllocator))
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{
+		w:         fw,
+		desc:      desc,
+		builder:   array.NewRecordBuilder(memory.DefaultAllocator, schema),
+		batchSize: batchSize,
+	}, nil
+}
+
+// Write appends a message as a row, writing a record batch when the batch is full.
+func (w *Writer) Write(m proto.Message) error {
+	msg := m.ProtoReflect()
+	if msg.Descriptor().FullName() != w.desc.FullName() {
+		return fmt.Errorf("cannot write %s to a file of %s", msg.Descriptor().FullName(), w.desc.FullName())
+	}
+	fields := w.desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		appendField(w.builder.Field(i), msg, fields.Get(i))
+	}
+	w.rows++
+	w.count++
+	if w.rows == w.batchSize {
+		return w.flush()
+	}
+	return nil
+}
+
+// Count returns the number of rows written so far.
+func (w *Writer) Count() int64 {
+	return w.count
+}
+
+// Close writes the last record batch and the footer of the file. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	defer w.builder.Release()
+	if w.rows > 0 {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+	if err := w.w.Close(); err != nil {
+		return fmt.Errorf("failed to write footer: %w", err)
+	}
+	return nil
+}
+
+// flush writes the buffered rows as a record batch.
+func (w *Writer) flush() error {
+	record := w.builder.NewRecord()
+	defer record.Release()
+	if err := w.w.Write(record); err != nil {
+		return fmt.Errorf("failed to write record batch: %w", err)
+	}
+	w.rows = 0
+	return nil
+}
+
+// appendField appends the value of a field of a message to the builder of its column.
+func appendField(b array.Builder, msg protoreflect.Message, fd protoreflect.FieldDescriptor) {
+	switch {
+	case fd.IsList():
+		lb := b.(*array.ListBuilder)
+		lb.Append(true)
+		list := msg.Get(fd).List()
+		for i := 0; i < list.Len(); i++ {
+			appendValue(lb.ValueBuilder(), fd, list.Get(i), true)
+		}
+	default:
+		appendValue(b, fd, msg.Get(fd), fd.Kind() != protoreflect.MessageKind || msg.Has(fd))
+	}
+}
+
+// appendValue appends a single value of a field, or a null for an unset message.
+func appendValue(b array.Builder, fd protoreflect.FieldDescriptor, v protoreflect.Value, valid bool) {
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		b.(*array.Int32Builder).Append(int32(v.Int()))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		b.(*array.Int64Builder).Append(v.Int())
+	case protoreflect.DoubleKind:
+		b.(*array.Float64Builder).Append(v.Float())
+	case protoreflect.BoolKind:
+		b.(*array.BooleanBuilder).Append(v.Bool())
+	case protoreflect.StringKind:
+		b.(*array.StringBuilder).Append(v.String())
+	case protoreflect.MessageKind:
+		// A null struct also appends a null to each of its children.
+		if !valid {
+			b.AppendNull()
+			return
+		}
+		md := fd.Message()
+		if inner := unwrapped(md); inner != nil {
+			appendField(b, v.Message(), inner)
+			return
+		}
+		sb := b.(*array.StructBuilder)
+		sb.Append(true)
+		for i := 0; i < md.Fields().Len(); i++ {
+			appendField(sb.FieldBuilder(i), v.Message(), md.Fields().Get(i))
+		}
+	}
+}
+
+// positionWriter tracks the position of an io.Writer for ipc.FileWriter, which asks for it with
+// Seek(0, io.SeekCurrent) but never moves it.
+type positionWriter struct {
+	w   io.Writer
+	pos int64
+}
+
+// Write writes p to the underlying writer.
+func (p *positionWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.pos += int64(n)
+	return n, err
+}
+
+// Seek returns the current position; the writer cannot move.
+func (p *positionWriter) Seek(offset int64, whence int) (int64, error) {
+	if offset != 0 || whence != io.SeekCurrent {
+		return 0, errors.New("arrowipc: cannot seek in the output")
+	}
+	return p.pos, nil
+}
@@ -5,30 +5,41 @@ import (
 	"spn-benchmark-ds/internal/pkg/analysis"
 	"spn-benchmark-ds/internal/pkg/generation"
 	"spn-benchmark-ds/internal/pkg/petrinet"
+	"spn-benchmark-ds/internal/pkg/rates"
 )
 
+// Variation is a Petri net derived from another one, together with its reachability graph, the
+// firing rates it was analyzed with and the analysis results.
+type Variation struct {
+	PetriNet          *petrinet.PetriNet
+	ReachabilityGraph *generation.ReachabilityGraph
+	LambdaValues      []float64
+	Analysis          *analysis.SPNAnalysisResult
+}
+
 // GeneratePetriNetVariations generates variations of a Petri net by adding or removing tokens.
-// It takes a Petri net and a set of parameters and returns a slice of SPN analysis results.
-func GeneratePetriNetVariations(pn *petrinet.PetriNet, placeUpperBound, marksLowerLimit, marksUpperLimit, numVariations, minFiringRate, maxFiringRate int) []*analysis.SPNAnalysisResult {
-	var variations []*analysis.SPNAnalysisResult
+// It takes a random source, a Petri net and a set of parameters and returns the variations.
+// Variations that cannot be analyzed with the given options are dropped.
+func GeneratePetriNetVariations(rng *rand.Rand, pn *petrinet.PetriNet, placeUpperBound, marksLowerLimit, marksUpperLimit, numVariations int, sampler rates.Sampler, opts analysis.Options) []*Variation {
+	var variations []*Variation
 
 	for i := 0; i < numVariations; i++ {
-		variationPN := deepCopyPetriNet(pn)
-		if rand.Float64() < 0.5 {
+		variationPN := pn.Clone()
+		if rng.Float64() < 0.5 {
 			// Add tokens
-			place := rand.Intn(variationPN.Places)
+			place := rng.Intn(variationPN.Places)
 			if variationPN.InitialMarking[place] < placeUpperBound {
 				variationPN.InitialMarking[place]++
 			}
 		} else {
 			// Remove tokens
-			place := rand.Intn(variationPN.Places)
+			place := rng.Intn(variationPN.Places)
 			if variationPN.InitialMarking[place] > 0 {
 				variationPN.InitialMarking[place]--
 			}
 		}
 
-		rg, err := generation.GenerateReachabilityGraph(variationPN, placeUpperBound, marksUpperLimit)
+		rg, err := generation.GenerateTangibleReachabilityGraph(variationPN, placeUpperBound, marksUpperLimit)
 		if err != nil {
 			continue
 		}
@@ -37,59 +48,32 @@ func GeneratePetriNetVariations(pn *petrinet.PetriNet, placeUpperBound, marksLow
 			continue
 		}
 
-		lambdaValues := make([]float64, variationPN.Transitions)
-		for i := range lambdaValues {
-			lambdaValues[i] = float64(minFiringRate + rand.Intn(maxFiringRate-minFiringRate+1))
-		}
+		lambdaValues := sampler.Sample(rng, variationPN.Transitions)
 
-		stateMatrix, targetVector := analysis.ComputeStateEquation(rg, lambdaValues)
-		steadyStateProbs, err := analysis.SolveForSteadyState(stateMatrix, targetVector)
+		result, _, err := analysis.Analyze(rg, lambdaValues, opts)
 		if err != nil {
 			continue
 		}
-
-		avgMarkings, markingDensities := analysis.ComputeAverageMarkings(rg, steadyStateProbs)
-		variations = append(variations, &analysis.SPNAnalysisResult{
-			SteadyStateProbs: steadyStateProbs,
-			AverageMarkings:  avgMarkings,
-			MarkingDensities: markingDensities,
-		})
+		variations = append(variations, &Variation{PetriNet: variationPN, ReachabilityGraph: rg, LambdaValues: lambdaValues, Analysis: result})
 	}
 
 	return variations
 }
 
-// deepCopyPetriNet creates a deep copy of a Petri net.
-func deepCopyPetriNet(pn *petrinet.PetriNet) *petrinet.PetriNet {
-	newPN := petrinet.NewPetriNet(pn.Places, pn.Transitions)
-	copy(newPN.Matrix, pn.Matrix)
-	copy(newPN.InitialMarking, pn.InitialMarking)
-	return newPN
-}
-
 // GenerateLambdaVariations generates variations of a Petri net by changing the lambda values.
-func GenerateLambdaVariations(pn *petrinet.PetriNet, rg *generation.ReachabilityGraph, numVariations, minFiringRate, maxFiringRate int) ([]*analysis.SPNAnalysisResult, [][]float64) {
+// The lambda values are drawn by sampler from rng.
+func GenerateLambdaVariations(rng *rand.Rand, pn *petrinet.PetriNet, rg *generation.ReachabilityGraph, numVariations int, sampler rates.Sampler, opts analysis.Options) ([]*analysis.SPNAnalysisResult, [][]float64) {
 	var variations []*analysis.SPNAnalysisResult
 	var lambdaValuesList [][]float64
 
 	for i := 0; i < numVariations; i++ {
-		lambdaValues := make([]float64, pn.Transitions)
-		for i := range lambdaValues {
-			lambdaValues[i] = float64(minFiringRate + rand.Intn(maxFiringRate-minFiringRate+1))
-		}
+		lambdaValues := sampler.Sample(rng, pn.Transitions)
 
-		stateMatrix, targetVector := analysis.ComputeStateEquation(rg, lambdaValues)
-		steadyStateProbs, err := analysis.SolveForSteadyState(stateMatrix, targetVector)
+		result, _, err := analysis.Analyze(rg, lambdaValues, opts)
 		if err != nil {
 			continue
 		}
-
-		avgMarkings, markingDensities := analysis.ComputeAverageMarkings(rg, steadyStateProbs)
-		variations = append(variations, &analysis.SPNAnalysisResult{
-			SteadyStateProbs: steadyStateProbs,
-			AverageMarkings:  avgMarkings,
-			MarkingDensities: markingDensities,
-		})
+		variations = append(variations, result)
 		lambdaValuesList = append(lambdaValuesList, lambdaValues)
 	}
 
@@ -1,8 +1,11 @@
 package augmentation
 
 import (
+	"math/rand"
+	"spn-benchmark-ds/internal/pkg/analysis"
 	"spn-benchmark-ds/internal/pkg/generation"
 	"spn-benchmark-ds/internal/pkg/petrinet"
+	"spn-benchmark-ds/internal/pkg/rates"
 	"testing"
 )
 
@@ -21,10 +24,9 @@ func TestGenerateLambdaVariations(t *testing.T) {
 		IsBounded:      true,
 	}
 	numVariations := 5
-	minFiringRate := 1
-	maxFiringRate := 10
+	sampler := rates.Sampler{Min: 1, Max: 10}
 
-	variations, lambdaValuesList := GenerateLambdaVariations(pn, rg, numVariations, minFiringRate, maxFiringRate)
+	variations, lambdaValuesList := GenerateLambdaVariations(rand.New(rand.NewSource(1)), pn, rg, numVariations, sampler, analysis.Options{})
 
 	if len(variations) != numVariations {
 		t.Errorf("GenerateLambdaVariations returned %d variations, expected %d", len(variations), numVariations)
@@ -41,12 +43,23 @@ func TestGeneratePetriNetVariations(t *testing.T) {
 	placeUpperBound := 10
 	marksLowerLimit := 1
 	marksUpperLimit := 100
-	minFiringRate := 1
-	maxFiringRate := 10
+	sampler := rates.Sampler{Min: 1, Max: 10}
 
-	variations := GeneratePetriNetVariations(pn, placeUpperBound, marksLowerLimit, marksUpperLimit, numVariations, minFiringRate, maxFiringRate)
+	variations := GeneratePetriNetVariations(rand.New(rand.NewSource(1)), pn, placeUpperBound, marksLowerLimit, marksUpperLimit, numVariations, sampler, analysis.Options{})
 
 	if len(variations) != numVariations {
 		t.Errorf("GeneratePetriNetVariations returned %d variations, expected %d", len(variations), numVariations)
 	}
+
+	for _, v := range variations {
+		if v.PetriNet == pn {
+			t.Errorf("GeneratePetriNetVariations returned the original net")
+		}
+		if len(v.LambdaValues) != pn.Transitions {
+			t.Errorf("GeneratePetriNetVariations returned %d lambda values, expected %d", len(v.LambdaValues), pn.Transitions)
+		}
+		if v.ReachabilityGraph.NumVertices != len(v.Analysis.SteadyStateProbs) {
+			t.Errorf("GeneratePetriNetVariations returned %d markings but %d steady-state probabilities", v.ReachabilityGraph.NumVertices, len(v.Analysis.SteadyStateProbs))
+		}
+	}
 }
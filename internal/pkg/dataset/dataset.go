@@ -0,0 +1,114 @@
+// Package dataset writes datasets as directories of sharded, optionally compressed files,
+// described by a manifest, and reads them, or single dataset files, back as typed samples.
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+)
+
+// Compression algorithms of the shards, accepted by Options.Compression.
+const (
+	// CompressionNone writes the shards uncompressed.
+	CompressionNone = "none"
+	// CompressionGzip compresses the shards with gzip.
+	CompressionGzip = "gzip"
+	// CompressionZstd compresses the shards with Zstandard.
+	CompressionZstd = "zstd"
+)
+
+// ManifestFile is the name of the manifest in a dataset directory.
+const ManifestFile = "manifest.json"
+
+// Manifest describes a dataset directory.
+type Manifest struct {
+	// GeneratorVersion identifies the build of the generator that wrote the dataset.
+	GeneratorVersion string `json:"generator_version"`
+	// Format is the format of the samples, such as FormatJSONL.
+	Format string `json:"format"`
+	// Compression is the compression of the shards.
+	Compression string `json:"compression"`
+	// Samples is the total number of samples.
+	Samples int `json:"samples"`
+	// Shards lists the shards in sample order.
+	Shards []Shard `json:"shards"`
+	// Config is the configuration the dataset was generated with.
+	Config json.RawMessage `json:"config,omitempty"`
+}
+
+// Shard describes one file of a dataset.
+type Shard struct {
+	// Path is the path of the shard, relative to the dataset directory.
+	Path string `json:"path"`
+	// Samples is the number of samples in the shard.
+	Samples int `json:"samples"`
+	// Bytes is the size of the shard file.
+	Bytes int64 `json:"bytes"`
+	// SHA256 is the hex-encoded SHA-256 checksum of the shard file.
+	SHA256 string `json:"sha256"`
+}
+
+// ReadManifest reads the manifest of a dataset directory.
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// GeneratorVersion returns the version of the running binary: its module version and, when built
+// from a version control checkout, the revision.
+func GeneratorVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	version := info.Main.Version
+	if version == "" {
+		version = "(devel)"
+	}
+	revision, modified := "", false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision != "" {
+		version += " " + revision
+		if modified {
+			version += "+dirty"
+		}
+	}
+	return version
+}
+
+// ValidateCompression checks a compression algorithm. Empty means CompressionNone.
+func ValidateCompression(compression string) error {
+	switch compression {
+	case "", CompressionNone, CompressionGzip, CompressionZstd:
+		return nil
+	}
+	return fmt.Errorf("unknown compression %q", compression)
+}
+
+// compressionSuffix returns the file name suffix of a compression algorithm.
+func compressionSuffix(compression string) string {
+	switch compression {
+	case CompressionGzip:
+		return ".gz"
+	case CompressionZstd:
+		return ".zst"
+	}
+	return ""
+}
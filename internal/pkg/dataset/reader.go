@@ -0,0 +1,262 @@
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"spn-benchmark-ds/internal/pkg/arrowipc"
+	"spn-benchmark-ds/internal/pkg/spn"
+	"spn-benchmark-ds/internal/pkg/utils"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Formats of the samples, as recorded in the manifest.
+const (
+	// FormatJSONL is one JSON object per line.
+	FormatJSONL = "jsonl"
+	// FormatProtobuf is a protobuf dataset of SPNData messages.
+	FormatProtobuf = "protobuf"
+	// FormatArrow is an Arrow IPC file with a row per SPNData message.
+	FormatArrow = "arrow"
+)
+
+// Magic bytes that identify the compression and the format of a file.
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	protoMagic = []byte("SPND")
+	arrowMagic = []byte("ARROW1")
+)
+
+// Reader reads the samples of a dataset in order: the shards of a dataset directory one after the
+// other, or a single file.
+type Reader struct {
+	dir    string
+	paths  []string
+	format string
+
+	// file is the open file, nil between files; source decodes its samples.
+	file   *os.File
+	closer io.Closer
+	source sampleSource
+	path   string
+	index  int
+}
+
+// sampleSource decodes the samples of one file.
+type sampleSource interface {
+	// next returns the next sample, or io.EOF after the last one.
+	next() (*Sample, error)
+}
+
+// Open opens a dataset for reading: a dataset directory with a manifest, or a single file in any
+// of the output formats. The format of a file and its compression, gzip or Zstandard, are
+// detected from its contents.
+func Open(path string) (*Reader, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	if !info.IsDir() {
+		r := &Reader{paths: []string{path}}
+		if err := r.openNext(); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+
+	manifest, err := ReadManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Reader{dir: path, format: manifest.Format}
+	for _, shard := range manifest.Shards {
+		r.paths = append(r.paths, filepath.Join(path, shard.Path))
+	}
+	return r, nil
+}
+
+// Format returns the format of the dataset, such as FormatJSONL. It is empty for a dataset
+// directory whose manifest does not record it.
+func (r *Reader) Format() string {
+	return r.format
+}
+
+// Next returns the next sample, or io.EOF after the last one.
+func (r *Reader) Next() (*Sample, error) {
+	for {
+		if r.source == nil {
+			if len(r.paths) == 0 {
+				return nil, io.EOF
+			}
+			if err := r.openNext(); err != nil {
+				return nil, err
+			}
+		}
+		s, err := r.source.next()
+		if err == io.EOF {
+			if err := r.closeFile(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sample %d from %s: %w", r.index, r.path, err)
+		}
+		r.index++
+		return s, nil
+	}
+}
+
+// Close closes the open file of the reader.
+func (r *Reader) Close() error {
+	r.paths = nil
+	return r.closeFile()
+}
+
+// openNext opens the next file and detects its compression and format.
+func (r *Reader) openNext() error {
+	path := r.paths[0]
+	r.paths = r.paths[1:]
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("failed to open dataset file: %w", err)
+	}
+	r.file, r.path = file, path
+	source, format, err := r.newSource(file)
+	if err != nil {
+		r.closeFile()
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if r.dir != "" && r.format != "" && format != r.format {
+		r.closeFile()
+		return fmt.Errorf("%s holds %s samples, but the manifest records %s", path, format, r.format)
+	}
+	r.source, r.format = source, format
+	return nil
+}
+
+// newSource returns the decoder of the samples of a file and their format.
+func (r *Reader) newSource(file *os.File) (sampleSource, string, error) {
+	in := bufio.NewReader(file)
+	head, _ := in.Peek(len(zstdMagic))
+	var decompressed io.Reader = in
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		gz, err := gzip.NewReader(in)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to decompress: %w", err)
+		}
+		r.closer = gz
+		decompressed = bufio.NewReader(gz)
+	case bytes.HasPrefix(head, zstdMagic):
+		decoder, err := zstd.NewReader(in, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to decompress: %w", err)
+		}
+		r.closer = decoder.IOReadCloser()
+		decompressed = bufio.NewReader(decoder)
+	}
+	buffered := decompressed.(*bufio.Reader)
+
+	head, _ = buffered.Peek(len(arrowMagic))
+	switch {
+	case bytes.HasPrefix(head, protoMagic):
+		reader, err := spn.NewReader(buffered)
+		if err != nil {
+			return nil, "", err
+		}
+		return protoSource{reader}, FormatProtobuf, nil
+	case bytes.HasPrefix(head, arrowMagic):
+		// Arrow IPC files are read from their footer, so compressed ones are read into memory.
+		var at io.ReaderAt = file
+		var size int64
+		if buffered == in {
+			info, err := file.Stat()
+			if err != nil {
+				return nil, "", err
+			}
+			size = info.Size()
+		} else {
+			data, err := io.ReadAll(buffered)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to decompress: %w", err)
+			}
+			at, size = bytes.NewReader(data), int64(len(data))
+		}
+		reader, err := arrowipc.NewReader(at, size, (&spn.SPNData{}).ProtoReflect().Descriptor())
+		if err != nil {
+			return nil, "", err
+		}
+		return arrowSource{reader}, FormatArrow, nil
+	}
+	return jsonlSource{utils.NewJSONLReader(buffered)}, FormatJSONL, nil
+}
+
+// closeFile closes the open file, if any.
+func (r *Reader) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+	if r.closer != nil {
+		r.closer.Close()
+		r.closer = nil
+	}
+	err := r.file.Close()
+	r.file, r.source = nil, nil
+	if err != nil {
+		return fmt.Errorf("failed to close %s: %w", r.path, err)
+	}
+	return nil
+}
+
+// jsonlSource decodes the records of a JSONL file.
+type jsonlSource struct {
+	r *utils.JSONLReader
+}
+
+// next decodes the next record.
+func (s jsonlSource) next() (*Sample, error) {
+	record, err := s.r.Next()
+	if err != nil {
+		return nil, err
+	}
+	sample, err := decodeJSONSample(record)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", s.r.Line(), err)
+	}
+	return sample, nil
+}
+
+// protoSource decodes the samples of a protobuf dataset.
+type protoSource struct {
+	r *spn.Reader
+}
+
+// next decodes the next sample.
+func (s protoSource) next() (*Sample, error) {
+	data, err := s.r.Next()
+	if err != nil {
+		return nil, err
+	}
+	return FromProto(data)
+}
+
+// arrowSource decodes the rows of an Arrow IPC file.
+type arrowSource struct {
+	r *arrowipc.Reader
+}
+
+// next decodes the next row.
+func (s arrowSource) next() (*Sample, error) {
+	data := &spn.SPNData{}
+	if err := s.r.Next(data); err != nil {
+		return nil, err
+	}
+	return FromProto(data)
+}
@@ -0,0 +1,259 @@
+package dataset
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"spn-benchmark-ds/internal/pkg/arrowipc"
+	"spn-benchmark-ds/internal/pkg/spn"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// protoSamples returns two samples of a net with two places and one transition: a solved one
+// and a simulated one.
+func protoSamples() []*spn.SPNData {
+	net := &spn.PetriNet{Matrix: []int32{1, 0, 2, 0, 1, 0}, Places: 2, Transitions: 1, InitialMarking: []int32{2, 0}}
+	return []*spn.SPNData{
+		{
+			PetriNet: net,
+			ReachabilityGraph: &spn.ReachabilityGraph{
+				Vertices:       []*spn.Vertex{{Marking: []int32{2, 0}}, {Marking: []int32{1, 1}}, {Marking: []int32{0, 2}}},
+				Edges:          []*spn.Edge{{Src: 0, Dest: 1}, {Src: 1, Dest: 2}},
+				ArcTransitions: []int32{0, 0},
+				IsBounded:      true,
+			},
+			LambdaValues:     []float64{0.5},
+			SteadyStateProbs: []float64{0, 0, 1},
+			AverageMarkings:  []float64{0, 2},
+			MarkingDensities: []*spn.MarkingDensity{{Densities: []float64{1, 0, 0}}, {Densities: []float64{0, 0, 1}}},
+			Throughputs:      []float64{0},
+			ChainStructure:   &spn.ChainStructure{NumComponents: 3, BottomComponents: []*spn.MarkingSet{{Markings: []int32{2}}}, DeadMarkings: []int32{2}},
+			SolverStats:      &spn.SolverStats{Method: "direct", Converged: true},
+		},
+		{
+			PetriNet:     net,
+			LambdaValues: []float64{2},
+			Simulation: &spn.Simulation{
+				AverageMarkings: []*spn.Estimate{{Mean: 0.1, HalfWidth: 0.01}, {Mean: 1.9, HalfWidth: 0.01}},
+				Firings:         100,
+			},
+		},
+	}
+}
+
+// jsonSamples returns the samples of protoSamples as JSONL records.
+func jsonSamples(t *testing.T) []byte {
+	t.Helper()
+	net := map[string]interface{}{"Places": 2, "Transitions": 1, "Matrix": []int{1, 0, 2, 0, 1, 0}, "InitialMarking": []int{2, 0}}
+	records := []map[string]interface{}{
+		{
+			"petri_net": net,
+			"reachability_graph": map[string]interface{}{
+				"Vertices": []int{2, 0, 1, 1, 0, 2}, "Edges": []int{0, 1, 1, 2}, "VerticesStride": 2, "EdgesStride": 2,
+				"NumVertices": 3, "NumEdges": 2, "ArcTransitions": []int{0, 0}, "IsBounded": true,
+			},
+			"lambda_values":      []float64{0.5},
+			"steady_state_probs": []float64{0, 0, 1},
+			"average_markings":   []float64{0, 2},
+			"marking_densities":  [][]float64{{1, 0, 0}, {0, 0, 1}},
+			"throughputs":        []float64{0},
+			"chain_structure":    map[string]interface{}{"num_components": 3, "bottom_components": [][]int{{2}}, "dead_markings": []int{2}},
+			"solver_stats":       map[string]interface{}{"method": "direct", "converged": true},
+		},
+		{
+			"petri_net":          net,
+			"reachability_graph": nil,
+			"lambda_values":      []float64{2},
+			"steady_state_probs": nil,
+			"simulation": map[string]interface{}{
+				"average_markings": []map[string]float64{{"mean": 0.1, "half_width": 0.01}, {"mean": 1.9, "half_width": 0.01}},
+				"firings":          100,
+			},
+		},
+	}
+	var buf bytes.Buffer
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		buf.Write(append(data, '\n'))
+	}
+	return buf.Bytes()
+}
+
+// readAll opens a dataset and reads all of its samples.
+func readAll(t *testing.T, path string) (string, []*Sample) {
+	t.Helper()
+	r, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer r.Close()
+	var samples []*Sample
+	for {
+		s, err := r.Next()
+		if err == io.EOF {
+			return r.Format(), samples
+		}
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		samples = append(samples, s)
+	}
+}
+
+// writeFile writes data to a new file, compressed with the given algorithm.
+func writeFile(t *testing.T, name string, data []byte, compression string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch compression {
+	case CompressionGzip:
+		w = gzip.NewWriter(&buf)
+	case CompressionZstd:
+		w, _ = zstd.NewWriter(&buf)
+	}
+	if w != nil {
+		w.Write(data)
+		w.Close()
+		data = buf.Bytes()
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	return path
+}
+
+// checkSamples checks samples read back from any format against protoSamples.
+func checkSamples(t *testing.T, source string, samples []*Sample) {
+	t.Helper()
+	if len(samples) != 2 {
+		t.Fatalf("%s: expected 2 samples, got %d", source, len(samples))
+	}
+	solved, simulated := samples[0], samples[1]
+	pn := solved.PetriNet
+	if pn.Places != 2 || pn.Transitions != 1 || pn.At(1, 1) != 1 || !reflect.DeepEqual(pn.InitialMarking, []int{2, 0}) {
+		t.Errorf("%s: unexpected net %+v", source, pn)
+	}
+	rg := solved.ReachabilityGraph
+	if rg == nil || rg.NumVertices != 3 || !reflect.DeepEqual(rg.Vertex(1), []int{1, 1}) || !reflect.DeepEqual(rg.Edge(1), []int{1, 2}) || !rg.IsBounded {
+		t.Errorf("%s: unexpected reachability graph %+v", source, rg)
+	}
+	if a := solved.Analysis; a == nil || !reflect.DeepEqual(a.AverageMarkings, []float64{0, 2}) || a.Chain == nil || a.Chain.DeadMarkings[0] != 2 || a.SolverStats.Method != "direct" {
+		t.Errorf("%s: unexpected analysis %+v", source, a)
+	}
+	if simulated.Analysis != nil || simulated.ReachabilityGraph != nil {
+		t.Errorf("%s: expected a simulated sample without analysis, got %+v", source, simulated)
+	}
+	if sim := simulated.Simulation; sim == nil || sim.Firings != 100 || sim.AverageMarkings[1].Mean != 1.9 {
+		t.Errorf("%s: unexpected simulation %+v", source, sim)
+	}
+}
+
+func TestOpenFiles(t *testing.T) {
+	var protoData bytes.Buffer
+	protoWriter, err := spn.NewWriter(&protoData)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	var arrowData bytes.Buffer
+	arrowWriter, err := arrowipc.NewWriter(&arrowData, (&spn.SPNData{}).ProtoReflect().Descriptor(), 1)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	for _, s := range protoSamples() {
+		if err := protoWriter.Write(s); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		if err := arrowWriter.Write(s); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	protoWriter.Close()
+	arrowWriter.Close()
+
+	for _, compression := range []string{CompressionNone, CompressionGzip, CompressionZstd} {
+		for format, data := range map[string][]byte{FormatJSONL: jsonSamples(t), FormatProtobuf: protoData.Bytes(), FormatArrow: arrowData.Bytes()} {
+			source := format + " " + compression
+			got, samples := readAll(t, writeFile(t, "samples", data, compression))
+			if got != format {
+				t.Errorf("%s: expected format %s, got %s", source, format, got)
+			}
+			checkSamples(t, source, samples)
+		}
+	}
+}
+
+func TestOpenReferenceZstd(t *testing.T) {
+	// testdata/samples.jsonl.zst holds jsonSamples compressed by the zstd command at level 19, so
+	// it uses Huffman-coded literals and compressed sequence tables.
+	format, samples := readAll(t, filepath.Join("testdata", "samples.jsonl.zst"))
+	if format != FormatJSONL {
+		t.Errorf("expected format %s, got %s", FormatJSONL, format)
+	}
+	checkSamples(t, "reference zstd", samples)
+}
+
+func TestOpenDirectory(t *testing.T) {
+	opts := Options{Format: FormatJSONL, Extension: "jsonl", Compression: CompressionGzip, MaxSamples: 1}
+	dir := filepath.Join(t.TempDir(), "dataset")
+	w, err := NewWriter(dir, opts)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	for _, record := range bytes.SplitAfter(jsonSamples(t), []byte("\n")) {
+		if len(record) > 0 {
+			if err := w.Write(record); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if len(w.Manifest().Shards) != 2 {
+		t.Fatalf("expected 2 shards, got %d", len(w.Manifest().Shards))
+	}
+
+	format, samples := readAll(t, dir)
+	if format != FormatJSONL {
+		t.Errorf("expected format %s, got %s", FormatJSONL, format)
+	}
+	checkSamples(t, "directory", samples)
+}
+
+func TestReaderErrors(t *testing.T) {
+	if _, err := Open(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+	if _, err := Open(t.TempDir()); err == nil {
+		t.Errorf("expected an error for a directory without a manifest")
+	}
+
+	for name, data := range map[string]string{
+		"malformed record": `{"petri_net": {"Places": 1, "Transitions": 0, "Matrix": [0]}}` + "\n{\n",
+		"wrong matrix":     `{"petri_net": {"Places": 2, "Transitions": 1, "Matrix": [1, 0, 2]}}` + "\n",
+		"missing net":      `{"lambda_values": [1]}` + "\n",
+	} {
+		r, err := Open(writeFile(t, "samples.jsonl", []byte(data), CompressionNone))
+		if err != nil {
+			t.Fatalf("%s: Open failed: %v", name, err)
+		}
+		for err == nil {
+			_, err = r.Next()
+		}
+		if err == io.EOF || !strings.Contains(err.Error(), "samples.jsonl") {
+			t.Errorf("%s: expected an error naming the file, got %v", name, err)
+		}
+		r.Close()
+	}
+}
@@ -0,0 +1,249 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"spn-benchmark-ds/internal/pkg/analysis"
+	"spn-benchmark-ds/internal/pkg/generation"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+	"spn-benchmark-ds/internal/pkg/simulation"
+	"spn-benchmark-ds/internal/pkg/spn"
+	"spn-benchmark-ds/internal/pkg/structural"
+)
+
+// Sample is a record of a dataset, decoded from any of its formats.
+type Sample struct {
+	PetriNet *petrinet.PetriNet
+	// ReachabilityGraph is the tangible reachability graph of the net, or nil for simulated
+	// records without one.
+	ReachabilityGraph *generation.ReachabilityGraph
+	LambdaValues      []float64
+	// Analysis holds the numeric analysis results, or nil for raw and simulated records, which
+	// have no steady-state distribution. Chain is only set for chains that are not irreducible.
+	Analysis *analysis.SPNAnalysisResult
+	// Simulation holds the simulation estimates, if the net was simulated.
+	Simulation *simulation.Result
+	// Invariants and SiphonsAndTraps hold the structural labels of the net, if they were computed.
+	Invariants      *structural.Invariants
+	SiphonsAndTraps *structural.SiphonsAndTraps
+}
+
+// jsonRecord is a record of the JSONL format.
+type jsonRecord struct {
+	PetriNet          *petrinet.PetriNet            `json:"petri_net"`
+	ReachabilityGraph *generation.ReachabilityGraph `json:"reachability_graph"`
+	LambdaValues      []float64                     `json:"lambda_values"`
+	SteadyStateProbs  []float64                     `json:"steady_state_probs"`
+	AverageMarkings   []float64                     `json:"average_markings"`
+	MarkingDensities  [][]float64                   `json:"marking_densities"`
+	Throughputs       []float64                     `json:"throughputs"`
+	TokenFlowRates    []float64                     `json:"token_flow_rates"`
+	SojournTimes      []float64                     `json:"sojourn_times"`
+	ResponseTimes     []analysis.ResponseTime       `json:"response_times"`
+	Transient         *analysis.TransientResult     `json:"transient"`
+	Rewards           []analysis.RewardValue        `json:"rewards"`
+	ChainStructure    *analysis.ChainStructure      `json:"chain_structure"`
+	SolverStats       *analysis.SolverStats         `json:"solver_stats"`
+	Simulation        *simulation.Result            `json:"simulation"`
+	Invariants        *structural.Invariants        `json:"invariants"`
+	SiphonsAndTraps   *structural.SiphonsAndTraps   `json:"siphons_and_traps"`
+}
+
+// decodeJSONSample decodes a record of the JSONL format.
+func decodeJSONSample(data []byte) (*Sample, error) {
+	var record jsonRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	if record.PetriNet == nil {
+		return nil, fmt.Errorf("record without a Petri net")
+	}
+	s := &Sample{
+		PetriNet:          record.PetriNet,
+		ReachabilityGraph: record.ReachabilityGraph,
+		LambdaValues:      record.LambdaValues,
+		Simulation:        record.Simulation,
+		Invariants:        record.Invariants,
+		SiphonsAndTraps:   record.SiphonsAndTraps,
+	}
+	if len(record.SteadyStateProbs) > 0 {
+		s.Analysis = &analysis.SPNAnalysisResult{
+			SteadyStateProbs: record.SteadyStateProbs,
+			AverageMarkings:  record.AverageMarkings,
+			MarkingDensities: record.MarkingDensities,
+			Throughputs:      record.Throughputs,
+			TokenFlowRates:   record.TokenFlowRates,
+			SojournTimes:     record.SojournTimes,
+			ResponseTimes:    record.ResponseTimes,
+			Transient:        record.Transient,
+			Rewards:          record.Rewards,
+			Chain:            record.ChainStructure,
+			SolverStats:      record.SolverStats,
+		}
+	}
+	return s, nil
+}
+
+// FromProto converts a sample of the protobuf format.
+func FromProto(data *spn.SPNData) (*Sample, error) {
+	pn, err := petrinet.FromProto(data.GetPetriNet())
+	if err != nil {
+		return nil, err
+	}
+	var rg *generation.ReachabilityGraph
+	if data.GetReachabilityGraph() != nil {
+		if rg, err = generation.ReachabilityGraphFromProto(data.GetReachabilityGraph()); err != nil {
+			return nil, err
+		}
+	}
+	s := &Sample{
+		PetriNet:          pn,
+		ReachabilityGraph: rg,
+		LambdaValues:      data.GetLambdaValues(),
+		Simulation:        fromProtoSimulation(data.GetSimulation()),
+		Invariants:        fromProtoInvariants(data.GetInvariants()),
+		SiphonsAndTraps:   fromProtoSiphonsAndTraps(data.GetSiphonsAndTraps()),
+	}
+	if len(data.GetSteadyStateProbs()) > 0 {
+		densities := make([][]float64, len(data.GetMarkingDensities()))
+		for i, d := range data.GetMarkingDensities() {
+			densities[i] = d.GetDensities()
+		}
+		var responseTimes []analysis.ResponseTime
+		for _, r := range data.GetResponseTimes() {
+			responseTimes = append(responseTimes, analysis.ResponseTime{Place: int(r.GetPlace()), Transition: int(r.GetTransition()), Time: r.GetResponseTime()})
+		}
+		var rewards []analysis.RewardValue
+		for _, v := range data.GetRewards() {
+			rewards = append(rewards, analysis.RewardValue{Name: v.GetName(), SteadyState: v.GetSteadyState(), Transient: v.GetTransient()})
+		}
+		s.Analysis = &analysis.SPNAnalysisResult{
+			SteadyStateProbs: data.GetSteadyStateProbs(),
+			AverageMarkings:  data.GetAverageMarkings(),
+			MarkingDensities: densities,
+			Throughputs:      data.GetThroughputs(),
+			TokenFlowRates:   data.GetTokenFlowRates(),
+			SojournTimes:     data.GetSojournTimes(),
+			ResponseTimes:    responseTimes,
+			Transient:        fromProtoTransient(data.GetTransient()),
+			Rewards:          rewards,
+			Chain:            fromProtoChainStructure(data.GetChainStructure()),
+			SolverStats:      fromProtoSolverStats(data.GetSolverStats()),
+		}
+	}
+	return s, nil
+}
+
+// fromProtoTransient converts the transient distributions of the protobuf format.
+func fromProtoTransient(transient *spn.Transient) *analysis.TransientResult {
+	if transient == nil {
+		return nil
+	}
+	result := &analysis.TransientResult{}
+	for _, point := range transient.GetPoints() {
+		result.Times = append(result.Times, point.GetTime())
+		result.Probs = append(result.Probs, point.GetProbs())
+		result.AverageMarkings = append(result.AverageMarkings, point.GetAverageMarkings())
+	}
+	return result
+}
+
+// fromProtoChainStructure converts the component decomposition of the protobuf format.
+func fromProtoChainStructure(chain *spn.ChainStructure) *analysis.ChainStructure {
+	if chain == nil {
+		return nil
+	}
+	bottom := make([][]int, len(chain.GetBottomComponents()))
+	for i, set := range chain.GetBottomComponents() {
+		bottom[i] = spn.ToIntSlice(set.GetMarkings())
+	}
+	return &analysis.ChainStructure{
+		NumComponents:           int(chain.GetNumComponents()),
+		BottomComponents:        bottom,
+		DeadMarkings:            spn.ToIntSlice(chain.GetDeadMarkings()),
+		AbsorptionProbabilities: chain.GetAbsorptionProbabilities(),
+	}
+}
+
+// fromProtoSolverStats converts the accuracy metrics of the protobuf format.
+func fromProtoSolverStats(stats *spn.SolverStats) *analysis.SolverStats {
+	if stats == nil {
+		return nil
+	}
+	return &analysis.SolverStats{
+		Method:          stats.GetMethod(),
+		Iterations:      int(stats.GetIterations()),
+		Residual:        stats.GetResidual(),
+		Converged:       stats.GetConverged(),
+		ClampedMass:     stats.GetClampedMass(),
+		ConditionNumber: stats.GetConditionNumber(),
+	}
+}
+
+// fromProtoSimulation converts the simulation estimates of the protobuf format.
+func fromProtoSimulation(sim *spn.Simulation) *simulation.Result {
+	if sim == nil {
+		return nil
+	}
+	toEstimates := func(estimates []*spn.Estimate) []simulation.Estimate {
+		converted := make([]simulation.Estimate, len(estimates))
+		for i, e := range estimates {
+			converted[i] = simulation.Estimate{Mean: e.GetMean(), HalfWidth: e.GetHalfWidth()}
+		}
+		return converted
+	}
+	densities := make([][]simulation.Estimate, len(sim.GetMarkingDensities()))
+	for p, d := range sim.GetMarkingDensities() {
+		densities[p] = toEstimates(d.GetEstimates())
+	}
+	return &simulation.Result{
+		AverageMarkings:  toEstimates(sim.GetAverageMarkings()),
+		Throughputs:      toEstimates(sim.GetThroughputs()),
+		MarkingDensities: densities,
+		Time:             sim.GetTime(),
+		Firings:          int(sim.GetFirings()),
+		Batches:          int(sim.GetBatches()),
+		Confidence:       sim.GetConfidence(),
+	}
+}
+
+// fromProtoInvariants converts the structural labels of the protobuf format.
+func fromProtoInvariants(invariants *spn.Invariants) *structural.Invariants {
+	if invariants == nil {
+		return nil
+	}
+	toSemiflows := func(semiflows []*spn.Semiflow) [][]int {
+		result := make([][]int, len(semiflows))
+		for i, s := range semiflows {
+			result[i] = spn.ToIntSlice(s.GetWeights())
+		}
+		return result
+	}
+	return &structural.Invariants{
+		PSemiflows:   toSemiflows(invariants.GetPSemiflows()),
+		TSemiflows:   toSemiflows(invariants.GetTSemiflows()),
+		Conservative: invariants.GetConservative(),
+		Consistent:   invariants.GetConsistent(),
+	}
+}
+
+// fromProtoSiphonsAndTraps converts the deadlock labels of the protobuf format.
+func fromProtoSiphonsAndTraps(st *spn.SiphonsAndTraps) *structural.SiphonsAndTraps {
+	if st == nil {
+		return nil
+	}
+	toPlaceSets := func(sets []*spn.PlaceSet) [][]int {
+		result := make([][]int, len(sets))
+		for i, s := range sets {
+			result[i] = spn.ToIntSlice(s.GetPlaces())
+		}
+		return result
+	}
+	return &structural.SiphonsAndTraps{
+		MinimalSiphons:   toPlaceSets(st.GetMinimalSiphons()),
+		MinimalTraps:     toPlaceSets(st.GetMinimalTraps()),
+		Ordinary:         st.GetOrdinary(),
+		FreeChoice:       st.GetFreeChoice(),
+		CommonerProperty: st.GetCommonerProperty(),
+	}
+}
@@ -0,0 +1,271 @@
+package dataset
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"spn-benchmark-ds/internal/pkg/utils"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Options configures the shards of a dataset.
+type Options struct {
+	// Format is the format of the samples, recorded in the manifest.
+	Format string
+	// Extension is the file extension of the shards before compression, such as "jsonl".
+	Extension string
+	// Compression is the compression of the shards; empty means CompressionNone.
+	Compression string
+	// MaxSamples starts a new shard after this many samples; zero disables the limit.
+	MaxSamples int
+	// MaxBytes starts a new shard before a sample would take it beyond this many bytes, before
+	// compression; zero disables the limit. A single larger sample gets a shard of its own.
+	// Encoded samples count once the encoder writes them out, so encoders that buffer samples can
+	// take a shard beyond the limit by their buffer.
+	MaxBytes int64
+	// Header, if set, returns the bytes every shard starts with, given its number of samples or
+	// -1 while that is unknown. Uncompressed shards get the header rewritten with their number of
+	// samples when they are complete, so it must not change in size.
+	Header func(samples int) []byte
+	// NewEncoder, if set, returns the encoder of a shard that writes to w, for formats that are
+	// not a sequence of encoded samples. The samples are then passed to Encode instead of Write.
+	NewEncoder func(w io.Writer) (Encoder, error)
+	// Config is the configuration the dataset is generated with, recorded in the manifest.
+	Config json.RawMessage
+}
+
+// Encoder encodes the samples of one shard, for formats such as Arrow IPC files that end with a
+// footer.
+type Encoder interface {
+	// Encode writes a sample to the shard.
+	Encode(sample interface{}) error
+	// Close completes the shard. It does not close the writer of the shard.
+	Close() error
+}
+
+// Writer writes encoded samples to the shards of a dataset directory and its manifest.
+type Writer struct {
+	dir      string
+	opts     Options
+	manifest Manifest
+
+	// file is the open shard, nil between shards; out buffers the writes to it, through the
+	// compressor if there is one, and encoder encodes the samples of the shard if the format
+	// needs one.
+	file       *os.File
+	compressor io.WriteCloser
+	out        *bufio.Writer
+	encoder    Encoder
+	shard      Shard
+	bytes      int64
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n *int64
+}
+
+// Write writes p to the underlying writer and counts the bytes written.
+func (cw countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	*cw.n += int64(n)
+	return n, err
+}
+
+// NewWriter creates the dataset directory, if needed, and returns a writer of its shards.
+func NewWriter(dir string, opts Options) (*Writer, error) {
+	if opts.Compression == "" {
+		opts.Compression = CompressionNone
+	}
+	if err := ValidateCompression(opts.Compression); err != nil {
+		return nil, err
+	}
+	if opts.MaxSamples < 0 || opts.MaxBytes < 0 {
+		return nil, fmt.Errorf("shard limits must not be negative, got %d samples and %d bytes", opts.MaxSamples, opts.MaxBytes)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create dataset directory: %w", err)
+	}
+	return &Writer{
+		dir:  dir,
+		opts: opts,
+		manifest: Manifest{
+			GeneratorVersion: GeneratorVersion(),
+			Format:           opts.Format,
+			Compression:      opts.Compression,
+			Shards:           []Shard{},
+			Config:           opts.Config,
+		},
+	}, nil
+}
+
+// Write appends an encoded sample to the dataset, starting a new shard when the current one is
+// full.
+func (w *Writer) Write(sample []byte) error {
+	if w.opts.NewEncoder != nil {
+		return errors.New("samples of a dataset with an encoder must be passed to Encode")
+	}
+	if err := w.nextShard(len(sample)); err != nil {
+		return err
+	}
+	if _, err := w.out.Write(sample); err != nil {
+		return fmt.Errorf("failed to write sample to %s: %w", w.shard.Path, err)
+	}
+	w.shard.Samples++
+	w.bytes += int64(len(sample))
+	return nil
+}
+
+// Encode passes a sample to the encoder of the shard, starting a new shard when the current one
+// is full.
+func (w *Writer) Encode(sample interface{}) error {
+	if w.opts.NewEncoder == nil {
+		return errors.New("dataset has no encoder")
+	}
+	if err := w.nextShard(0); err != nil {
+		return err
+	}
+	if err := w.encoder.Encode(sample); err != nil {
+		return fmt.Errorf("failed to write sample to %s: %w", w.shard.Path, err)
+	}
+	w.shard.Samples++
+	return nil
+}
+
+// nextShard makes sure a shard that can take a sample of the given size is open.
+func (w *Writer) nextShard(size int) error {
+	if w.file != nil && w.full(size) {
+		if err := w.finishShard(); err != nil {
+			return err
+		}
+	}
+	if w.file == nil {
+		return w.startShard()
+	}
+	return nil
+}
+
+// full reports whether the current shard cannot take a sample of the given size.
+func (w *Writer) full(size int) bool {
+	if w.opts.MaxSamples > 0 && w.shard.Samples >= w.opts.MaxSamples {
+		return true
+	}
+	return w.opts.MaxBytes > 0 && w.shard.Samples > 0 && w.bytes+int64(size) > w.opts.MaxBytes
+}
+
+// Close completes the last shard and writes the manifest.
+func (w *Writer) Close() error {
+	if w.file != nil {
+		if err := w.finishShard(); err != nil {
+			return err
+		}
+	}
+	if err := utils.SaveDataToJSONFile(filepath.Join(w.dir, ManifestFile), w.manifest); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// Manifest returns the manifest of the shards completed so far.
+func (w *Writer) Manifest() Manifest {
+	return w.manifest
+}
+
+// startShard creates the next shard file.
+func (w *Writer) startShard() error {
+	name := fmt.Sprintf("shard-%05d.%s%s", len(w.manifest.Shards), w.opts.Extension, compressionSuffix(w.opts.Compression))
+	file, err := os.Create(filepath.Join(w.dir, name))
+	if err != nil {
+		return fmt.Errorf("failed to create shard: %w", err)
+	}
+	w.file, w.shard, w.bytes = file, Shard{Path: name}, 0
+
+	var sink io.Writer = file
+	switch w.opts.Compression {
+	case CompressionGzip:
+		w.compressor = gzip.NewWriter(file)
+		sink = w.compressor
+	case CompressionZstd:
+		encoder, err := zstd.NewWriter(file)
+		if err != nil {
+			file.Close()
+			w.file = nil
+			return fmt.Errorf("failed to start %s: %w", name, err)
+		}
+		w.compressor = encoder
+		sink = encoder
+	}
+	w.out = bufio.NewWriter(sink)
+
+	if w.opts.Header != nil {
+		header := w.opts.Header(-1)
+		if _, err := w.out.Write(header); err != nil {
+			return fmt.Errorf("failed to write shard header: %w", err)
+		}
+		w.bytes += int64(len(header))
+	}
+	if w.opts.NewEncoder != nil {
+		encoder, err := w.opts.NewEncoder(countingWriter{w: w.out, n: &w.bytes})
+		if err != nil {
+			file.Close()
+			w.file = nil
+			return fmt.Errorf("failed to start %s: %w", name, err)
+		}
+		w.encoder = encoder
+	}
+	return nil
+}
+
+// finishShard completes the current shard and adds it to the manifest.
+func (w *Writer) finishShard() error {
+	file := w.file
+	w.file = nil
+	defer file.Close()
+
+	if w.encoder != nil {
+		err := w.encoder.Close()
+		w.encoder = nil
+		if err != nil {
+			return fmt.Errorf("failed to complete %s: %w", w.shard.Path, err)
+		}
+	}
+	if err := w.out.Flush(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", w.shard.Path, err)
+	}
+	if w.compressor != nil {
+		if err := w.compressor.Close(); err != nil {
+			return fmt.Errorf("failed to compress %s: %w", w.shard.Path, err)
+		}
+		w.compressor = nil
+	} else if w.opts.Header != nil {
+		if _, err := file.WriteAt(w.opts.Header(w.shard.Samples), 0); err != nil {
+			return fmt.Errorf("failed to write shard header: %w", err)
+		}
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to checksum %s: %w", w.shard.Path, err)
+	}
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return fmt.Errorf("failed to checksum %s: %w", w.shard.Path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", w.shard.Path, err)
+	}
+	w.shard.Bytes = size
+	w.shard.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	w.manifest.Shards = append(w.manifest.Shards, w.shard)
+	w.manifest.Samples += w.shard.Samples
+	return nil
+}
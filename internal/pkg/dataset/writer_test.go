@@ -0,0 +1,186 @@
+package dataset
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// writeDataset writes n ten-byte samples to a new dataset directory.
+func writeDataset(t *testing.T, n int, opts Options) (string, *Manifest) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "dataset")
+	w, err := NewWriter(dir, opts)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	for i := 0; i < n; i++ {
+		if err := w.Write([]byte(fmt.Sprintf("sample%03d\n", i))); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatalf("ReadManifest failed: %v", err)
+	}
+	return dir, manifest
+}
+
+// readShard returns the decompressed content of a shard and checks its size and checksum.
+func readShard(t *testing.T, dir, compression string, shard Shard) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, shard.Path))
+	if err != nil {
+		t.Fatalf("failed to read shard: %v", err)
+	}
+	sum := sha256.Sum256(data)
+	if int64(len(data)) != shard.Bytes || hex.EncodeToString(sum[:]) != shard.SHA256 {
+		t.Errorf("expected %s to match the size and checksum in the manifest", shard.Path)
+	}
+	var r io.Reader = bytes.NewReader(data)
+	switch compression {
+	case CompressionGzip:
+		if r, err = gzip.NewReader(r); err != nil {
+			t.Fatalf("failed to open gzip shard: %v", err)
+		}
+	case CompressionZstd:
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			t.Fatalf("failed to open zstd shard: %v", err)
+		}
+		defer decoder.Close()
+		r = decoder
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to decompress shard: %v", err)
+	}
+	return string(content)
+}
+
+func TestWriterShardsAndCompresses(t *testing.T) {
+	for _, compression := range []string{CompressionNone, CompressionGzip, CompressionZstd} {
+		dir, manifest := writeDataset(t, 10, Options{
+			Format:      "jsonl",
+			Extension:   "jsonl",
+			Compression: compression,
+			MaxSamples:  4,
+			Config:      json.RawMessage(`{"seed":42}`),
+		})
+		if manifest.Samples != 10 || len(manifest.Shards) != 3 || manifest.Compression != compression {
+			t.Fatalf("expected 10 %s samples in 3 shards, got %+v", compression, manifest)
+		}
+		var config struct{ Seed int }
+		if err := json.Unmarshal(manifest.Config, &config); err != nil || config.Seed != 42 || manifest.GeneratorVersion == "" {
+			t.Errorf("expected the manifest to record the config and generator version, got %+v", manifest)
+		}
+		var all strings.Builder
+		for i, shard := range manifest.Shards {
+			expectedName := fmt.Sprintf("shard-%05d.jsonl%s", i, compressionSuffix(compression))
+			if shard.Path != expectedName || shard.Samples != []int{4, 4, 2}[i] {
+				t.Errorf("expected shard %d to be %s with %d samples, got %+v", i, expectedName, []int{4, 4, 2}[i], shard)
+			}
+			all.WriteString(readShard(t, dir, compression, shard))
+		}
+		if !strings.HasPrefix(all.String(), "sample000\n") || !strings.HasSuffix(all.String(), "sample009\n") || all.Len() != 100 {
+			t.Errorf("expected the shards to hold the samples in order, got %q", all.String())
+		}
+	}
+}
+
+func TestWriterByteLimitAndHeaders(t *testing.T) {
+	header := func(samples int) []byte { return []byte(fmt.Sprintf("[%+03d]", samples)) }
+	dir, manifest := writeDataset(t, 7, Options{Extension: "txt", MaxBytes: 35, Header: header})
+	// Each shard holds a five-byte header and three ten-byte samples.
+	if len(manifest.Shards) != 3 {
+		t.Fatalf("expected 3 shards, got %+v", manifest.Shards)
+	}
+	for i, shard := range manifest.Shards {
+		content := readShard(t, dir, CompressionNone, shard)
+		if expected := fmt.Sprintf("[%+03d]", shard.Samples); !strings.HasPrefix(content, expected) {
+			t.Errorf("expected shard %d to start with %s, got %q", i, expected, content)
+		}
+	}
+
+	// Compressed shards cannot be rewritten, so their header keeps the unknown count.
+	dir, manifest = writeDataset(t, 2, Options{Extension: "txt", Compression: CompressionGzip, Header: header})
+	if content := readShard(t, dir, CompressionGzip, manifest.Shards[0]); !strings.HasPrefix(content, "[-01]") {
+		t.Errorf("expected an unknown count in a compressed shard, got %q", content)
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewWriter(dir, Options{Compression: "lz4"}); err == nil {
+		t.Errorf("expected an error for an unknown compression")
+	}
+	if _, err := NewWriter(dir, Options{MaxSamples: -1}); err == nil {
+		t.Errorf("expected an error for a negative shard limit")
+	}
+	if _, err := ReadManifest(dir); err == nil {
+		t.Errorf("expected an error for a directory without a manifest")
+	}
+}
+
+// bracketEncoder encodes the samples of a shard as a bracketed list.
+type bracketEncoder struct {
+	w io.Writer
+}
+
+// Encode writes a sample followed by a space.
+func (e bracketEncoder) Encode(sample interface{}) error {
+	_, err := fmt.Fprintf(e.w, "%v ", sample)
+	return err
+}
+
+// Close ends the list.
+func (e bracketEncoder) Close() error {
+	_, err := io.WriteString(e.w, "]")
+	return err
+}
+
+func TestWriterEncoder(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dataset")
+	w, err := NewWriter(dir, Options{Extension: "txt", MaxBytes: 6, NewEncoder: func(w io.Writer) (Encoder, error) {
+		_, err := io.WriteString(w, "[")
+		return bracketEncoder{w: w}, err
+	}})
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if err := w.Write([]byte("raw")); err == nil {
+		t.Errorf("expected an error for a raw sample to a dataset with an encoder")
+	}
+	for i := 10; i < 15; i++ {
+		if err := w.Encode(i); err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	// A shard is full once the opening bracket and two samples take it beyond six bytes.
+	expected := []string{"[10 11 ]", "[12 13 ]", "[14 ]"}
+	manifest := w.Manifest()
+	if manifest.Samples != 5 || len(manifest.Shards) != len(expected) {
+		t.Fatalf("expected 5 samples in %d shards, got %+v", len(expected), manifest)
+	}
+	for i, shard := range manifest.Shards {
+		if content := readShard(t, dir, CompressionNone, shard); content != expected[i] {
+			t.Errorf("expected shard %d to be %q, got %q", i, expected[i], content)
+		}
+	}
+}
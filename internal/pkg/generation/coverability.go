@@ -0,0 +1,163 @@
+package generation
+
+import (
+	"spn-benchmark-ds/internal/pkg/petrinet"
+	"unsafe"
+)
+
+// Omega is the token count of a place in an ω-marking that can hold arbitrarily many tokens.
+const Omega = -1
+
+// CoverabilityGraph is the Karp–Miller coverability graph of a Petri net. Its markings are
+// ω-markings: a place holding Omega can be marked with any number of tokens by repeating a firing
+// sequence of the net.
+type CoverabilityGraph struct {
+	// Graph holds the ω-markings and the arcs between them. Its IsBounded field is true when the
+	// net is bounded and the graph is complete, in which case Graph is the reachability graph.
+	Graph *ReachabilityGraph
+	// UnboundedPlaces lists, in increasing order, the places that are Omega in some marking.
+	UnboundedPlaces []int
+	// PlaceBounds holds the largest number of tokens of each place over all markings, or Omega for
+	// the unbounded places.
+	PlaceBounds []int
+	// Complete is false when the exploration limit was reached before the graph was finished. The
+	// unbounded places found so far are unbounded, but more may exist.
+	Complete bool
+	// Exact is false for nets with inhibitor arcs or immediate transitions. Their boundedness is
+	// undecidable in general, so the graph is built for the underlying place/transition net and
+	// over-approximates their reachable markings: a bounded verdict holds, but places reported as
+	// unbounded may not be.
+	Exact bool
+}
+
+// IsBounded reports whether the net is known to be bounded.
+func (cg *CoverabilityGraph) IsBounded() bool {
+	return cg.Complete && len(cg.UnboundedPlaces) == 0
+}
+
+// GenerateCoverabilityGraph builds the Karp–Miller coverability graph of a Petri net, exploring at
+// most maxMarkingsToExplore ω-markings. Unlike GenerateReachabilityGraph it needs no bound on the
+// tokens per place: whenever a new marking strictly covers one of its ancestors in the exploration
+// tree, the places that grew are set to Omega. The graph is finite for every net, so a complete
+// graph decides boundedness exactly.
+func GenerateCoverabilityGraph(pn *petrinet.PetriNet, maxMarkingsToExplore int) (*CoverabilityGraph, error) {
+	numTransitions := pn.Transitions
+
+	type sparseArc struct {
+		Place  int
+		Tokens int
+	}
+	preReqs := make([][]sparseArc, numTransitions)
+	changes := make([][]sparseArc, numTransitions)
+	for t := 0; t < numTransitions; t++ {
+		for p := 0; p < pn.Places; p++ {
+			pre := pn.At(p, t)
+			change := pn.At(p, t+numTransitions) - pre
+			if pre > 0 {
+				preReqs[t] = append(preReqs[t], sparseArc{Place: p, Tokens: pre})
+			}
+			if change != 0 {
+				changes[t] = append(changes[t], sparseArc{Place: p, Tokens: change})
+			}
+		}
+	}
+
+	cg := &CoverabilityGraph{
+		Graph: &ReachabilityGraph{
+			VerticesStride: pn.Places,
+			EdgesStride:    2,
+		},
+		Complete: true,
+		Exact:    !pn.HasInhibitorArcs() && !pn.HasImmediateTransitions(),
+	}
+	graph := cg.Graph
+	graph.AddVertex(pn.InitialMarking)
+
+	// parent holds the vertex each marking was discovered from; its chain up to the initial
+	// marking gives the ancestors that are checked for acceleration.
+	parent := []int{-1}
+	visited := map[string]int{hashMarking(pn.InitialMarking): 0}
+	byteScratch := make([]byte, pn.Places*int(unsafe.Sizeof(int(0))))
+	next := make([]int, pn.Places)
+
+	for current := 0; current < graph.NumVertices; current++ {
+		if graph.NumVertices >= maxMarkingsToExplore {
+			cg.Complete = false
+			break
+		}
+		for t := 0; t < numTransitions; t++ {
+			marking := graph.Vertex(current)
+			isEnabled := true
+			for _, req := range preReqs[t] {
+				if tokens := marking[req.Place]; tokens != Omega && tokens < req.Tokens {
+					isEnabled = false
+					break
+				}
+			}
+			if !isEnabled {
+				continue
+			}
+
+			copy(next, marking)
+			for _, chg := range changes[t] {
+				if next[chg.Place] != Omega {
+					next[chg.Place] += chg.Tokens
+				}
+			}
+			for a := current; a >= 0; a = parent[a] {
+				accelerate(next, graph.Vertex(a))
+			}
+
+			encodeMarkingSafe(next, byteScratch)
+			dest, ok := visited[string(byteScratch)]
+			if !ok {
+				dest = graph.NumVertices
+				visited[string(byteScratch)] = dest
+				graph.AddVertex(next)
+				parent = append(parent, current)
+			}
+			graph.AddEdge([2]int{current, dest})
+			graph.ArcTransitions = append(graph.ArcTransitions, t)
+		}
+	}
+
+	cg.PlaceBounds = make([]int, pn.Places)
+	for v := 0; v < graph.NumVertices; v++ {
+		for p, tokens := range graph.Vertex(v) {
+			if tokens == Omega || cg.PlaceBounds[p] == Omega {
+				cg.PlaceBounds[p] = Omega
+			} else if tokens > cg.PlaceBounds[p] {
+				cg.PlaceBounds[p] = tokens
+			}
+		}
+	}
+	for p, bound := range cg.PlaceBounds {
+		if bound == Omega {
+			cg.UnboundedPlaces = append(cg.UnboundedPlaces, p)
+		}
+	}
+	graph.IsBounded = cg.IsBounded()
+	if !cg.Complete {
+		graph.Truncation = MarkingLimitExceeded
+	}
+	return cg, nil
+}
+
+// accelerate sets to Omega the places in which marking exceeds ancestor, provided marking covers
+// ancestor. The firing sequence leading from ancestor to marking can then be repeated to put
+// arbitrarily many tokens in those places.
+func accelerate(marking, ancestor []int) {
+	for p, tokens := range ancestor {
+		if tokens != Omega && marking[p] != Omega && marking[p] < tokens {
+			return
+		}
+		if tokens == Omega && marking[p] != Omega {
+			return
+		}
+	}
+	for p, tokens := range ancestor {
+		if marking[p] != Omega && marking[p] > tokens {
+			marking[p] = Omega
+		}
+	}
+}
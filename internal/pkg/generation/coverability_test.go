@@ -0,0 +1,117 @@
+package generation
+
+import (
+	"slices"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+	"testing"
+)
+
+// newProducerNet returns a net where T1 keeps its token in P1 and adds one to P2 on every firing,
+// so P2 is unbounded.
+func newProducerNet() *petrinet.PetriNet {
+	pn := petrinet.NewPetriNet(2, 1)
+	pn.Matrix = []int{
+		1, 1, 1,
+		0, 1, 0,
+	}
+	pn.InitialMarking = []int{1, 0}
+	return pn
+}
+
+func TestGenerateCoverabilityGraphUnbounded(t *testing.T) {
+	cg, err := GenerateCoverabilityGraph(newProducerNet(), 100)
+	if err != nil {
+		t.Fatalf("Error generating coverability graph: %v", err)
+	}
+	if !cg.Complete || !cg.Exact {
+		t.Fatalf("Expected a complete and exact coverability graph")
+	}
+	if cg.IsBounded() || cg.Graph.IsBounded {
+		t.Errorf("Expected the net to be unbounded")
+	}
+	if !slices.Equal(cg.UnboundedPlaces, []int{1}) {
+		t.Errorf("Expected P2 to be the only unbounded place, got %v", cg.UnboundedPlaces)
+	}
+	if !slices.Equal(cg.PlaceBounds, []int{1, Omega}) {
+		t.Errorf("Expected place bounds [1 ω], got %v", cg.PlaceBounds)
+	}
+
+	// (1,0) -T1-> (1,ω) -T1-> (1,ω)
+	if cg.Graph.NumVertices != 2 || cg.Graph.NumEdges != 2 {
+		t.Fatalf("Expected 2 markings and 2 arcs, got %d and %d", cg.Graph.NumVertices, cg.Graph.NumEdges)
+	}
+	if v := cg.Graph.Vertex(1); v[0] != 1 || v[1] != Omega {
+		t.Errorf("Expected the second marking to be (1, ω), got %v", v)
+	}
+}
+
+func TestGenerateCoverabilityGraphBounded(t *testing.T) {
+	pn := newWeightedNet()
+	cg, err := GenerateCoverabilityGraph(pn, 100)
+	if err != nil {
+		t.Fatalf("Error generating coverability graph: %v", err)
+	}
+	if !cg.IsBounded() || !cg.Graph.IsBounded {
+		t.Fatalf("Expected the net to be bounded, unbounded places: %v", cg.UnboundedPlaces)
+	}
+	if !slices.Equal(cg.PlaceBounds, []int{4, 2}) {
+		t.Errorf("Expected place bounds [4 2], got %v", cg.PlaceBounds)
+	}
+
+	rg, err := GenerateReachabilityGraph(pn, 10, 100)
+	if err != nil {
+		t.Fatalf("Error generating reachability graph: %v", err)
+	}
+	if cg.Graph.NumVertices != rg.NumVertices || cg.Graph.NumEdges != rg.NumEdges {
+		t.Errorf("Expected the coverability graph of a bounded net to be its reachability graph")
+	}
+}
+
+func TestGenerateCoverabilityGraphLimits(t *testing.T) {
+	cg, err := GenerateCoverabilityGraph(newWeightedNet(), 2)
+	if err != nil {
+		t.Fatalf("Error generating coverability graph: %v", err)
+	}
+	if cg.Complete || cg.IsBounded() {
+		t.Errorf("Expected an incomplete graph that does not prove boundedness")
+	}
+	if cg.Graph.Truncation != MarkingLimitExceeded {
+		t.Errorf("Expected the graph to be truncated by the marking limit, got %v", cg.Graph.Truncation)
+	}
+
+	pn := newProducerNet()
+	pn.SetInhibitor(1, 0, 3)
+	cg, err = GenerateCoverabilityGraph(pn, 100)
+	if err != nil {
+		t.Fatalf("Error generating coverability graph: %v", err)
+	}
+	if cg.Exact {
+		t.Errorf("Expected the graph of a net with inhibitor arcs not to be exact")
+	}
+}
+
+func TestReachabilityGraphTruncation(t *testing.T) {
+	rg, err := GenerateReachabilityGraph(newProducerNet(), 3, 100)
+	if err != nil {
+		t.Fatalf("Error generating reachability graph: %v", err)
+	}
+	if rg.IsBounded || rg.Truncation != PlaceLimitExceeded {
+		t.Errorf("Expected the place limit to stop the exploration, got %v", rg.Truncation)
+	}
+
+	rg, err = GenerateReachabilityGraph(newWeightedNet(), 10, 2)
+	if err != nil {
+		t.Fatalf("Error generating reachability graph: %v", err)
+	}
+	if rg.IsBounded || rg.Truncation != MarkingLimitExceeded {
+		t.Errorf("Expected the marking limit to stop the exploration, got %v", rg.Truncation)
+	}
+
+	rg, err = GenerateReachabilityGraph(newWeightedNet(), 10, 100)
+	if err != nil {
+		t.Fatalf("Error generating reachability graph: %v", err)
+	}
+	if !rg.IsBounded || rg.Truncation != NotTruncated {
+		t.Errorf("Expected a complete graph, got %v", rg.Truncation)
+	}
+}
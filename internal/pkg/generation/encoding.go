@@ -0,0 +1,130 @@
+package generation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"spn-benchmark-ds/internal/pkg/spn"
+)
+
+// plainReachabilityGraph has the fields of ReachabilityGraph without its methods, for the default
+// JSON encoding.
+type plainReachabilityGraph ReachabilityGraph
+
+// Validate checks that the vertex, edge and per-arc and per-marking slices of the graph fit its
+// numbers of vertices and edges, and that its edges join vertices of the graph.
+func (rg *ReachabilityGraph) Validate() error {
+	if rg.NumVertices < 0 || rg.NumEdges < 0 || rg.VerticesStride < 0 {
+		return fmt.Errorf("invalid dimensions: %d vertices of %d places and %d edges", rg.NumVertices, rg.VerticesStride, rg.NumEdges)
+	}
+	if len(rg.Vertices) != rg.NumVertices*rg.VerticesStride {
+		return fmt.Errorf("%d vertices of %d places need %d entries, got %d", rg.NumVertices, rg.VerticesStride, rg.NumVertices*rg.VerticesStride, len(rg.Vertices))
+	}
+	if rg.NumEdges > 0 && rg.EdgesStride != 2 {
+		return fmt.Errorf("edge stride %d, expected 2", rg.EdgesStride)
+	}
+	if len(rg.Edges) != rg.NumEdges*rg.EdgesStride {
+		return fmt.Errorf("%d edges need %d entries, got %d", rg.NumEdges, rg.NumEdges*rg.EdgesStride, len(rg.Edges))
+	}
+	for _, v := range rg.Edges {
+		if v < 0 || v >= rg.NumVertices {
+			return fmt.Errorf("edge to vertex %d of a graph of %d vertices", v, rg.NumVertices)
+		}
+	}
+	if len(rg.ArcTransitions) != rg.NumEdges {
+		return fmt.Errorf("%d arc transitions for %d edges", len(rg.ArcTransitions), rg.NumEdges)
+	}
+	if rg.ArcProbabilities != nil && len(rg.ArcProbabilities) != rg.NumEdges {
+		return fmt.Errorf("%d arc probabilities for %d edges", len(rg.ArcProbabilities), rg.NumEdges)
+	}
+	if rg.ImmediateFirings != nil && len(rg.ImmediateFirings) != rg.NumEdges {
+		return fmt.Errorf("%d immediate firing counts for %d edges", len(rg.ImmediateFirings), rg.NumEdges)
+	}
+	if rg.Vanishing != nil && len(rg.Vanishing) != rg.NumVertices {
+		return fmt.Errorf("%d vanishing flags for %d vertices", len(rg.Vanishing), rg.NumVertices)
+	}
+	if rg.InitialDistribution != nil && len(rg.InitialDistribution) != rg.NumVertices {
+		return fmt.Errorf("initial distribution over %d of %d vertices", len(rg.InitialDistribution), rg.NumVertices)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the graph with its exported fields, except Truncation. It returns an error
+// for a graph whose slices do not fit its dimensions, since it could not be decoded.
+func (rg ReachabilityGraph) MarshalJSON() ([]byte, error) {
+	if err := rg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid reachability graph: %w", err)
+	}
+	return json.Marshal(plainReachabilityGraph(rg))
+}
+
+// UnmarshalJSON decodes a graph encoded by MarshalJSON. It returns an error for a graph whose
+// slices do not fit its dimensions.
+func (rg *ReachabilityGraph) UnmarshalJSON(data []byte) error {
+	var plain plainReachabilityGraph
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return err
+	}
+	decoded := ReachabilityGraph(plain)
+	if err := decoded.Validate(); err != nil {
+		return fmt.Errorf("invalid reachability graph: %w", err)
+	}
+	*rg = decoded
+	return nil
+}
+
+// ToProto converts the graph to the protobuf format.
+func (rg *ReachabilityGraph) ToProto() *spn.ReachabilityGraph {
+	var vertices []*spn.Vertex
+	for i := 0; i < rg.NumVertices; i++ {
+		vertices = append(vertices, &spn.Vertex{Marking: spn.ToInt32Slice(rg.Vertex(i))})
+	}
+	var edges []*spn.Edge
+	for i := 0; i < rg.NumEdges; i++ {
+		e := rg.Edge(i)
+		edges = append(edges, &spn.Edge{Src: int32(e[0]), Dest: int32(e[1])})
+	}
+	return &spn.ReachabilityGraph{
+		Vertices:            vertices,
+		Edges:               edges,
+		ArcTransitions:      spn.ToInt32Slice(rg.ArcTransitions),
+		ArcProbabilities:    rg.ArcProbabilities,
+		InitialDistribution: rg.InitialDistribution,
+		IsBounded:           rg.IsBounded,
+		Vanishing:           rg.Vanishing,
+	}
+}
+
+// ReachabilityGraphFromProto converts a graph of the protobuf format, or returns an error if its
+// markings differ in size or its slices do not fit its numbers of vertices and edges.
+func ReachabilityGraphFromProto(graph *spn.ReachabilityGraph) (*ReachabilityGraph, error) {
+	if graph == nil {
+		return nil, errors.New("missing reachability graph")
+	}
+	// Generated graphs hold empty rather than nil edge slices, so a graph without edges that is
+	// read back encodes to the same JSON.
+	rg := &ReachabilityGraph{
+		Edges:               make([]int, 0, 2*len(graph.GetEdges())),
+		EdgesStride:         2,
+		ArcTransitions:      append(make([]int, 0, len(graph.GetArcTransitions())), spn.ToIntSlice(graph.GetArcTransitions())...),
+		IsBounded:           graph.GetIsBounded(),
+		Vanishing:           graph.GetVanishing(),
+		ArcProbabilities:    graph.GetArcProbabilities(),
+		InitialDistribution: graph.GetInitialDistribution(),
+	}
+	for i, v := range graph.GetVertices() {
+		if i == 0 {
+			rg.VerticesStride = len(v.GetMarking())
+		} else if len(v.GetMarking()) != rg.VerticesStride {
+			return nil, fmt.Errorf("invalid reachability graph: marking %d has %d places, expected %d", i, len(v.GetMarking()), rg.VerticesStride)
+		}
+		rg.AddVertex(spn.ToIntSlice(v.GetMarking()))
+	}
+	for _, e := range graph.GetEdges() {
+		rg.AddEdge([2]int{int(e.GetSrc()), int(e.GetDest())})
+	}
+	if err := rg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid reachability graph: %w", err)
+	}
+	return rg, nil
+}
@@ -0,0 +1,83 @@
+package generation
+
+import (
+	"encoding/json"
+	"reflect"
+	"spn-benchmark-ds/internal/pkg/spn"
+	"testing"
+)
+
+// newEncodingGraph builds an extended reachability graph of three markings of two places, in
+// which the vanishing marking 1 switches to markings 0 and 2.
+func newEncodingGraph() *ReachabilityGraph {
+	rg := &ReachabilityGraph{VerticesStride: 2, EdgesStride: 2, IsBounded: true}
+	for _, marking := range [][]int{{1, 0}, {0, 1}, {0, 0}} {
+		rg.AddVertex(marking)
+	}
+	for _, edge := range [][2]int{{0, 1}, {1, 0}, {1, 2}} {
+		rg.AddEdge(edge)
+	}
+	rg.ArcTransitions = []int{0, 1, 2}
+	rg.ArcProbabilities = []float64{1, 0.25, 0.75}
+	rg.Vanishing = []bool{false, true, false}
+	rg.InitialDistribution = []float64{0.5, 0, 0.5}
+	return rg
+}
+
+func TestGraphJSONRoundTrip(t *testing.T) {
+	data, err := json.Marshal(newEncodingGraph())
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var rg ReachabilityGraph
+	if err := json.Unmarshal(data, &rg); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if want := newEncodingGraph(); !reflect.DeepEqual(&rg, want) {
+		t.Errorf("expected %+v, got %+v", want, &rg)
+	}
+}
+
+func TestGraphProtoRoundTrip(t *testing.T) {
+	graph := newEncodingGraph().ToProto()
+	if !graph.GetIsBounded() || !reflect.DeepEqual(graph.GetVanishing(), []bool{false, true, false}) {
+		t.Errorf("unexpected protobuf graph %+v", graph)
+	}
+	rg, err := ReachabilityGraphFromProto(graph)
+	if err != nil {
+		t.Fatalf("ReachabilityGraphFromProto failed: %v", err)
+	}
+	if want := newEncodingGraph(); !reflect.DeepEqual(rg, want) {
+		t.Errorf("expected %+v, got %+v", want, rg)
+	}
+}
+
+func TestDecodeInvalidGraphs(t *testing.T) {
+	for name, data := range map[string]string{
+		"short vertices":     `{"Vertices": [1, 0, 0], "VerticesStride": 2, "NumVertices": 2}`,
+		"wrong edge stride":  `{"Vertices": [1, 0, 0, 1], "VerticesStride": 2, "NumVertices": 2, "Edges": [0, 1], "EdgesStride": 1, "NumEdges": 2, "ArcTransitions": [0, 0]}`,
+		"dangling edge":      `{"Vertices": [1, 0, 0, 1], "VerticesStride": 2, "NumVertices": 2, "Edges": [0, 2], "EdgesStride": 2, "NumEdges": 1, "ArcTransitions": [0]}`,
+		"missing transition": `{"Vertices": [1, 0, 0, 1], "VerticesStride": 2, "NumVertices": 2, "Edges": [0, 1], "EdgesStride": 2, "NumEdges": 1}`,
+		"wrong vanishing":    `{"Vertices": [1, 0, 0, 1], "VerticesStride": 2, "NumVertices": 2, "Vanishing": [true]}`,
+	} {
+		var rg ReachabilityGraph
+		if err := json.Unmarshal([]byte(data), &rg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	uneven := &spn.ReachabilityGraph{Vertices: []*spn.Vertex{{Marking: []int32{1, 0}}, {Marking: []int32{1}}}}
+	if _, err := ReachabilityGraphFromProto(uneven); err == nil {
+		t.Errorf("expected an error for markings of different sizes")
+	}
+	dangling := &spn.ReachabilityGraph{Vertices: []*spn.Vertex{{Marking: []int32{1}}}, Edges: []*spn.Edge{{Src: 0, Dest: 1}}, ArcTransitions: []int32{0}}
+	if _, err := ReachabilityGraphFromProto(dangling); err == nil {
+		t.Errorf("expected an error for an edge to a missing marking")
+	}
+
+	rg := newEncodingGraph()
+	rg.ArcProbabilities = rg.ArcProbabilities[:1]
+	if _, err := json.Marshal(rg); err == nil {
+		t.Errorf("expected an error marshalling an invalid graph")
+	}
+}
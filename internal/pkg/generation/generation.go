@@ -24,12 +24,67 @@ type ReachabilityGraph struct {
 	ArcTransitions []int
 	// IsBounded is true if the graph is bounded.
 	IsBounded bool
+	// Truncation tells which exploration limit stopped the generation of a graph that is not
+	// bounded.
+	Truncation Truncation `json:"-"`
+	// Vanishing marks the vanishing markings, in which at least one immediate transition is enabled.
+	// It is nil for nets without immediate transitions.
+	Vanishing []bool `json:",omitempty"`
+	// ArcProbabilities holds, for each arc, the probability by which its transition's rate is
+	// scaled. Arcs leaving vanishing markings carry switching probabilities, and arcs of a tangible
+	// reachability graph carry the probability of reaching their target through vanishing markings.
+	// It is nil when every arc has probability one.
+	ArcProbabilities []float64 `json:",omitempty"`
+	// InitialDistribution holds the probability of starting in each marking. It is only set for a
+	// tangible reachability graph whose net starts in a vanishing marking; otherwise the initial
+	// marking is vertex 0.
+	InitialDistribution []float64 `json:",omitempty"`
+	// ImmediateFirings holds, for each arc of a tangible reachability graph, the mean number of
+	// times each immediate transition fires in the vanishing markings the arc passes through, given
+	// that the arc is taken. It is nil unless vanishing markings were eliminated, and it is not
+	// encoded, so graphs read back from a dataset have none.
+	ImmediateFirings [][]Firing `json:"-"`
 	// verticesCapacity is the capacity of the vertices slice.
 	verticesCapacity int
 	// edgesCapacity is the capacity of the edges slice.
 	edgesCapacity int
 }
 
+// Firing is the mean number of times a transition fires.
+type Firing struct {
+	// Transition is the index of the transition.
+	Transition int
+	// Count is the mean number of firings.
+	Count float64
+}
+
+// Truncation is the reason the generation of a reachability graph was stopped early.
+type Truncation int
+
+const (
+	// NotTruncated means the graph holds every reachable marking.
+	NotTruncated Truncation = iota
+	// PlaceLimitExceeded means a reachable marking puts more tokens in a place than the place
+	// upper limit allows.
+	PlaceLimitExceeded
+	// MarkingLimitExceeded means the net has more reachable markings than the exploration limit.
+	MarkingLimitExceeded
+)
+
+// String returns a description of the truncation reason.
+func (t Truncation) String() string {
+	switch t {
+	case NotTruncated:
+		return "not truncated"
+	case PlaceLimitExceeded:
+		return "place upper limit exceeded"
+	case MarkingLimitExceeded:
+		return "marking limit exceeded"
+	default:
+		return "Truncation(" + strconv.Itoa(int(t)) + ")"
+	}
+}
+
 // Vertex returns the vertex at the given index.
 func (rg *ReachabilityGraph) Vertex(index int) []int {
 	return rg.Vertices[index*rg.VerticesStride : (index+1)*rg.VerticesStride]
@@ -53,8 +108,18 @@ func (rg *ReachabilityGraph) AddEdge(edge [2]int) {
 	rg.NumEdges++
 }
 
+// ArcProbability returns the probability by which the rate of arc i is scaled.
+func (rg *ReachabilityGraph) ArcProbability(i int) float64 {
+	if rg.ArcProbabilities == nil {
+		return 1
+	}
+	return rg.ArcProbabilities[i]
+}
+
 // GenerateReachabilityGraph generates the reachability graph of a Petri net using BFS.
 // It takes a Petri net and a set of parameters and returns a reachability graph.
+// For nets with immediate transitions it returns the extended reachability graph, which includes
+// the vanishing markings; see EliminateVanishing.
 func GenerateReachabilityGraph(pn *petrinet.PetriNet, placeUpperLimit int, maxMarkingsToExplore int) (*ReachabilityGraph, error) {
 	numTransitions := pn.Transitions
 
@@ -73,6 +138,8 @@ func GenerateReachabilityGraph(pn *petrinet.PetriNet, placeUpperLimit int, maxMa
 
 	preReqs := make([][]SparseReq, numTransitions)
 	changes := make([][]SparseChange, numTransitions)
+	// inhibitors[t] lists the places that disable t once they hold at least Tokens tokens.
+	inhibitors := make([][]SparseReq, numTransitions)
 
 	for t := 0; t < numTransitions; t++ {
 		for p := 0; p < pn.Places; p++ {
@@ -86,6 +153,23 @@ func GenerateReachabilityGraph(pn *petrinet.PetriNet, placeUpperLimit int, maxMa
 			if change != 0 {
 				changes[t] = append(changes[t], SparseChange{Place: p, Delta: change})
 			}
+			if threshold := pn.Inhibitor(p, t); threshold > 0 {
+				inhibitors[t] = append(inhibitors[t], SparseReq{Place: p, Tokens: threshold})
+			}
+		}
+	}
+
+	// Immediate transitions take precedence over timed ones, so the transitions allowed to fire in a
+	// marking are the enabled ones of the highest enabled priority (zero when only timed ones are).
+	hasImmediate := pn.HasImmediateTransitions()
+	priorities := make([]int, numTransitions)
+	weights := make([]float64, numTransitions)
+	var immediateTransitions []int
+	for t := 0; t < numTransitions; t++ {
+		priorities[t] = pn.Priority(t)
+		weights[t] = pn.Weight(t)
+		if pn.IsImmediate(t) {
+			immediateTransitions = append(immediateTransitions, t)
 		}
 	}
 
@@ -125,6 +209,10 @@ func GenerateReachabilityGraph(pn *petrinet.PetriNet, placeUpperLimit int, maxMa
 	// Add initial vertex without using dynamic capacity expansion when possible
 	graph.Vertices = append(graph.Vertices, initialMarking...)
 	graph.NumVertices = 1
+	if hasImmediate {
+		graph.Vanishing = make([]bool, 1, initialCapVertices)
+		graph.ArcProbabilities = make([]float64, 0, initialCapVertices*2)
+	}
 
 	for head < len(queue) {
 
@@ -134,13 +222,49 @@ func GenerateReachabilityGraph(pn *petrinet.PetriNet, placeUpperLimit int, maxMa
 
 		if graph.NumVertices >= maxMarkingsToExplore {
 			graph.IsBounded = false
+			graph.Truncation = MarkingLimitExceeded
 			break
 		}
 
+		firePriority := 0
+		totalWeight := 0.0
+		if hasImmediate {
+			for _, t := range immediateTransitions {
+				if priorities[t] < firePriority {
+					continue
+				}
+				isEnabled := true
+				for _, req := range preReqs[t] {
+					if currentMarking[req.Place] < req.Tokens {
+						isEnabled = false
+						break
+					}
+				}
+				for _, inh := range inhibitors[t] {
+					if currentMarking[inh.Place] >= inh.Tokens {
+						isEnabled = false
+						break
+					}
+				}
+				if !isEnabled {
+					continue
+				}
+				if priorities[t] > firePriority {
+					firePriority = priorities[t]
+					totalWeight = 0
+				}
+				totalWeight += weights[t]
+			}
+			graph.Vanishing[currentMarkingIndex] = firePriority > 0
+		}
+
 		// ⚡ Bolt: Inlined getEnabledTransitions to eliminate allocations.
 		// Instead of returning new slices, we evaluate transitions directly
 		// and use a scratch slice to hash/check bounds before copying.
 		for t := 0; t < numTransitions; t++ {
+			if priorities[t] != firePriority {
+				continue
+			}
 			isEnabled := true
 			for _, req := range preReqs[t] {
 				if currentMarking[req.Place] < req.Tokens {
@@ -148,6 +272,12 @@ func GenerateReachabilityGraph(pn *petrinet.PetriNet, placeUpperLimit int, maxMa
 					break
 				}
 			}
+			for _, inh := range inhibitors[t] {
+				if currentMarking[inh.Place] >= inh.Tokens {
+					isEnabled = false
+					break
+				}
+			}
 
 			if isEnabled {
 				isOutOfBounds := false
@@ -163,6 +293,7 @@ func GenerateReachabilityGraph(pn *petrinet.PetriNet, placeUpperLimit int, maxMa
 
 				if isOutOfBounds {
 					graph.IsBounded = false
+					graph.Truncation = PlaceLimitExceeded
 					break
 				}
 
@@ -178,10 +309,20 @@ func GenerateReachabilityGraph(pn *petrinet.PetriNet, placeUpperLimit int, maxMa
 					graph.AddEdge([2]int{currentMarkingIndex, graph.NumVertices})
 					graph.AddVertex(scratchMarking)
 					queue = append(queue, graph.NumVertices-1)
+					if hasImmediate {
+						graph.Vanishing = append(graph.Vanishing, false)
+					}
 				} else {
 					graph.AddEdge([2]int{currentMarkingIndex, val})
 				}
 				graph.ArcTransitions = append(graph.ArcTransitions, t)
+				if hasImmediate {
+					probability := 1.0
+					if firePriority > 0 {
+						probability = weights[t] / totalWeight
+					}
+					graph.ArcProbabilities = append(graph.ArcProbabilities, probability)
+				}
 			}
 		}
 		if !graph.IsBounded {
@@ -46,3 +46,77 @@ func TestGenerateReachabilityGraph(t *testing.T) {
 		t.Errorf("Expected arc transition to be 0, but got %d", rg.ArcTransitions[0])
 	}
 }
+
+// newWeightedNet returns a net where T0 consumes two tokens from P1 and produces one in P2, while
+// T1 consumes one token from P2 and returns two to P1. It starts with four tokens in P1.
+func newWeightedNet() *petrinet.PetriNet {
+	pn := petrinet.NewPetriNet(2, 2)
+	pn.Matrix = []int{
+		2, 0, 0, 2, 4,
+		0, 1, 1, 0, 0,
+	}
+	pn.InitialMarking = []int{4, 0}
+	return pn
+}
+
+func TestGenerateReachabilityGraphWithWeightedArcs(t *testing.T) {
+	rg, err := GenerateReachabilityGraph(newWeightedNet(), 10, 100)
+	if err != nil {
+		t.Fatalf("Error generating reachability graph: %v", err)
+	}
+
+	expectedVertices := [][]int{{4, 0}, {2, 1}, {0, 2}}
+	if rg.NumVertices != len(expectedVertices) {
+		t.Fatalf("Expected %d vertices, but got %d", len(expectedVertices), rg.NumVertices)
+	}
+	for i, expected := range expectedVertices {
+		vertex := rg.Vertex(i)
+		if vertex[0] != expected[0] || vertex[1] != expected[1] {
+			t.Errorf("Expected vertex %d to be %v, but got %v", i, expected, vertex)
+		}
+	}
+
+	// (4,0) -T0-> (2,1), (2,1) -T0-> (0,2), (2,1) -T1-> (4,0), (0,2) -T1-> (2,1)
+	if rg.NumEdges != 4 {
+		t.Errorf("Expected 4 edges, but got %d", rg.NumEdges)
+	}
+}
+
+func TestGenerateReachabilityGraphWithInhibitorArcs(t *testing.T) {
+	// T0 moves a token from P1 to P2 and T1 moves it back. Without inhibitor arcs both tokens can
+	// sit in P2 at once.
+	pn := petrinet.NewPetriNet(2, 2)
+	pn.Matrix = []int{
+		1, 0, 0, 1, 2,
+		0, 1, 1, 0, 0,
+	}
+	pn.InitialMarking = []int{2, 0}
+
+	rg, err := GenerateReachabilityGraph(pn, 10, 100)
+	if err != nil {
+		t.Fatalf("Error generating reachability graph: %v", err)
+	}
+	if rg.NumVertices != 3 {
+		t.Fatalf("Expected 3 vertices without inhibitor arcs, but got %d", rg.NumVertices)
+	}
+
+	// An inhibitor arc from P2 to T0 with threshold 1 keeps at most one token in P2.
+	pn.SetInhibitor(1, 0, 1)
+	rg, err = GenerateReachabilityGraph(pn, 10, 100)
+	if err != nil {
+		t.Fatalf("Error generating reachability graph: %v", err)
+	}
+	if rg.NumVertices != 2 {
+		t.Fatalf("Expected 2 vertices with the inhibitor arc, but got %d", rg.NumVertices)
+	}
+	for i := 0; i < rg.NumVertices; i++ {
+		if rg.Vertex(i)[1] > 1 {
+			t.Errorf("Expected P2 to hold at most one token, but vertex %d is %v", i, rg.Vertex(i))
+		}
+	}
+	for i := 0; i < rg.NumEdges; i++ {
+		if edge := rg.Edge(i); edge[0] == 1 && rg.ArcTransitions[i] == 0 {
+			t.Errorf("Expected T0 to be inhibited in marking %v", rg.Vertex(1))
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package generation
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+)
+
+// ErrVanishingLoop is returned when vanishing markings form a cycle. The time spent in such a
+// loop is zero but the number of firings is unbounded, so the nets are rejected.
+var ErrVanishingLoop = errors.New("vanishing markings form a loop")
+
+// exit is the probability of leaving a vanishing marking into a tangible one, with the mean
+// number of firings of each immediate transition on the way given that this exit is taken.
+type exit struct {
+	vertex      int
+	probability float64
+	firings     []Firing
+}
+
+// firingKey indexes the joint mean firings of an immediate transition and an exit.
+type firingKey struct {
+	vertex, transition int
+}
+
+// GenerateTangibleReachabilityGraph generates the reachability graph of a Petri net and, if the
+// net has immediate transitions, eliminates its vanishing markings.
+// Graphs cut short by the exploration limits are returned as explored.
+func GenerateTangibleReachabilityGraph(pn *petrinet.PetriNet, placeUpperLimit int, maxMarkingsToExplore int) (*ReachabilityGraph, error) {
+	rg, err := GenerateReachabilityGraph(pn, placeUpperLimit, maxMarkingsToExplore)
+	if err != nil || !rg.IsBounded {
+		return rg, err
+	}
+	return rg.EliminateVanishing()
+}
+
+// EliminateVanishing returns the tangible reachability graph of an extended reachability graph.
+// Vanishing markings are removed and every timed arc into one is replaced by arcs to the tangible
+// markings eventually reached from it, weighted in ArcProbabilities by the probability of reaching
+// each of them. The rate of an arc of the result is therefore λ(t)·p, and the CTMC it induces is
+// the reduced tangible chain of the GSPN. The immediate firings on the way are recorded in
+// ImmediateFirings, so they can be credited to the throughput of immediate transitions.
+// Tangible markings keep their relative order.
+// Graphs without vanishing markings are returned unchanged.
+func (rg *ReachabilityGraph) EliminateVanishing() (*ReachabilityGraph, error) {
+	if rg.Vanishing == nil {
+		return rg, nil
+	}
+	n := rg.NumVertices
+
+	tangible := &ReachabilityGraph{
+		VerticesStride: rg.VerticesStride,
+		EdgesStride:    2,
+		IsBounded:      rg.IsBounded,
+	}
+	tangibleIndex := make([]int, n)
+	for v := 0; v < n; v++ {
+		if rg.Vanishing[v] {
+			tangibleIndex[v] = -1
+			continue
+		}
+		tangibleIndex[v] = tangible.NumVertices
+		tangible.AddVertex(rg.Vertex(v))
+	}
+
+	// Group the arcs by source marking.
+	outStart := make([]int, n+1)
+	for i := 0; i < rg.NumEdges; i++ {
+		outStart[rg.Edges[i*rg.EdgesStride]+1]++
+	}
+	for v := 0; v < n; v++ {
+		outStart[v+1] += outStart[v]
+	}
+	outArcs := make([]int, rg.NumEdges)
+	fill := make([]int, n)
+	copy(fill, outStart[:n])
+	for i := 0; i < rg.NumEdges; i++ {
+		src := rg.Edges[i*rg.EdgesStride]
+		outArcs[fill[src]] = i
+		fill[src]++
+	}
+
+	// Resolve the exit distribution of every vanishing marking in DFS post-order, so the exits of
+	// its vanishing successors are known when it is finished. Reaching a marking that is still on
+	// the stack means the vanishing markings form a loop.
+	const (
+		unvisited = iota
+		onStack
+		done
+	)
+	state := make([]uint8, n)
+	next := make([]int, n)
+	exits := make([][]exit, n)
+	acc := make([]float64, tangible.NumVertices)
+	var touched []int
+	// joint holds E[firings of t · 1{exit = v}] for the marking being finished, and fired the
+	// transitions it counts.
+	joint := make(map[firingKey]float64)
+	var fired []int
+	var stack []int
+
+	for root := 0; root < n; root++ {
+		if !rg.Vanishing[root] || state[root] != unvisited {
+			continue
+		}
+		state[root] = onStack
+		next[root] = outStart[root]
+		stack = append(stack[:0], root)
+		for len(stack) > 0 {
+			u := stack[len(stack)-1]
+			if next[u] < outStart[u+1] {
+				w := rg.Edges[outArcs[next[u]]*rg.EdgesStride+1]
+				next[u]++
+				if !rg.Vanishing[w] {
+					continue
+				}
+				switch state[w] {
+				case onStack:
+					return nil, ErrVanishingLoop
+				case unvisited:
+					state[w] = onStack
+					next[w] = outStart[w]
+					stack = append(stack, w)
+				}
+				continue
+			}
+
+			touched, fired = touched[:0], fired[:0]
+			count := func(v, t int, p float64) {
+				if !slices.Contains(fired, t) {
+					fired = append(fired, t)
+				}
+				joint[firingKey{v, t}] += p
+			}
+			for k := outStart[u]; k < outStart[u+1]; k++ {
+				arc := outArcs[k]
+				p := rg.ArcProbability(arc)
+				t := rg.ArcTransitions[arc]
+				w := rg.Edges[arc*rg.EdgesStride+1]
+				if !rg.Vanishing[w] {
+					touched = accumulate(acc, touched, tangibleIndex[w], p)
+					count(tangibleIndex[w], t, p)
+					continue
+				}
+				for _, e := range exits[w] {
+					touched = accumulate(acc, touched, e.vertex, p*e.probability)
+					count(e.vertex, t, p*e.probability)
+					for _, f := range e.firings {
+						count(e.vertex, f.Transition, p*e.probability*f.Count)
+					}
+				}
+			}
+			sort.Ints(touched)
+			sort.Ints(fired)
+			exits[u] = make([]exit, len(touched))
+			for j, v := range touched {
+				e := exit{vertex: v, probability: acc[v]}
+				for _, t := range fired {
+					if c, ok := joint[firingKey{v, t}]; ok {
+						e.firings = append(e.firings, Firing{Transition: t, Count: c / acc[v]})
+					}
+				}
+				exits[u][j] = e
+				acc[v] = 0
+			}
+			clear(joint)
+			state[u] = done
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	tangible.Edges = make([]int, 0, rg.NumEdges*2)
+	tangible.ArcTransitions = make([]int, 0, rg.NumEdges)
+	tangible.ArcProbabilities = make([]float64, 0, rg.NumEdges)
+	tangible.ImmediateFirings = make([][]Firing, 0, rg.NumEdges)
+	for i := 0; i < rg.NumEdges; i++ {
+		src, dest := rg.Edges[i*rg.EdgesStride], rg.Edges[i*rg.EdgesStride+1]
+		if rg.Vanishing[src] {
+			continue
+		}
+		t := rg.ArcTransitions[i]
+		p := rg.ArcProbability(i)
+		if !rg.Vanishing[dest] {
+			tangible.AddEdge([2]int{tangibleIndex[src], tangibleIndex[dest]})
+			tangible.ArcTransitions = append(tangible.ArcTransitions, t)
+			tangible.ArcProbabilities = append(tangible.ArcProbabilities, p)
+			tangible.ImmediateFirings = append(tangible.ImmediateFirings, nil)
+			continue
+		}
+		for _, e := range exits[dest] {
+			tangible.AddEdge([2]int{tangibleIndex[src], e.vertex})
+			tangible.ArcTransitions = append(tangible.ArcTransitions, t)
+			tangible.ArcProbabilities = append(tangible.ArcProbabilities, p*e.probability)
+			tangible.ImmediateFirings = append(tangible.ImmediateFirings, e.firings)
+		}
+	}
+
+	if rg.Vanishing[0] {
+		tangible.InitialDistribution = make([]float64, tangible.NumVertices)
+		for _, e := range exits[0] {
+			tangible.InitialDistribution[e.vertex] = e.probability
+		}
+	}
+	return tangible, nil
+}
+
+// accumulate adds p to acc[v], recording v in touched the first time it becomes non-zero.
+func accumulate(acc []float64, touched []int, v int, p float64) []int {
+	if acc[v] == 0 {
+		touched = append(touched, v)
+	}
+	acc[v] += p
+	return touched
+}
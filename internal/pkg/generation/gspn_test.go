@@ -0,0 +1,170 @@
+package generation
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+	"spn-benchmark-ds/internal/pkg/testnets"
+	"testing"
+)
+
+func TestExtendedReachabilityGraph(t *testing.T) {
+	rg, err := GenerateReachabilityGraph(testnets.ChoiceNet(0), 10, 100)
+	if err != nil {
+		t.Fatalf("Error generating reachability graph: %v", err)
+	}
+	if rg.NumVertices != 4 {
+		t.Fatalf("Expected 4 markings, but got %d", rg.NumVertices)
+	}
+	expectedVanishing := []bool{false, true, false, false}
+	for i, v := range expectedVanishing {
+		if rg.Vanishing[i] != v {
+			t.Errorf("Expected vanishing[%d] to be %v, but got %v", i, v, rg.Vanishing[i])
+		}
+	}
+	for i := 0; i < rg.NumEdges; i++ {
+		var expected float64
+		switch rg.ArcTransitions[i] {
+		case 1:
+			expected = 0.25
+		case 2:
+			expected = 0.75
+		default:
+			expected = 1
+		}
+		if rg.ArcProbability(i) != expected {
+			t.Errorf("Expected arc %d of transition %d to have probability %v, but got %v",
+				i, rg.ArcTransitions[i], expected, rg.ArcProbability(i))
+		}
+	}
+}
+
+func TestGenerateTangibleReachabilityGraph(t *testing.T) {
+	rg, err := GenerateTangibleReachabilityGraph(testnets.ChoiceNet(0), 10, 100)
+	if err != nil {
+		t.Fatalf("Error generating tangible reachability graph: %v", err)
+	}
+	if rg.NumVertices != 3 {
+		t.Fatalf("Expected 3 tangible markings, but got %d", rg.NumVertices)
+	}
+	if rg.Vanishing != nil {
+		t.Errorf("Expected no vanishing markings, but got %v", rg.Vanishing)
+	}
+	if rg.InitialDistribution != nil {
+		t.Errorf("Expected no initial distribution, but got %v", rg.InitialDistribution)
+	}
+
+	// Tangible markings keep their order: (1,0,0,0), (0,0,1,0), (0,0,0,1).
+	type arc struct{ src, dest, transition int }
+	expected := map[arc]float64{
+		{0, 1, 0}: 0.25,
+		{0, 2, 0}: 0.75,
+		{1, 0, 3}: 1,
+		{2, 0, 4}: 1,
+	}
+	if rg.NumEdges != len(expected) {
+		t.Fatalf("Expected %d arcs, but got %d", len(expected), rg.NumEdges)
+	}
+	for i := 0; i < rg.NumEdges; i++ {
+		edge := rg.Edge(i)
+		a := arc{edge[0], edge[1], rg.ArcTransitions[i]}
+		p, ok := expected[a]
+		if !ok {
+			t.Errorf("Unexpected arc %v", a)
+			continue
+		}
+		if math.Abs(rg.ArcProbability(i)-p) > 1e-12 {
+			t.Errorf("Expected arc %v to have probability %v, but got %v", a, p, rg.ArcProbability(i))
+		}
+		// The arcs of T0 pass through P1, from which t1 leads to marking 1 and t2 to marking 2.
+		var firings []Firing
+		if a.transition == 0 {
+			firings = []Firing{{Transition: a.dest, Count: 1}}
+		}
+		if !reflect.DeepEqual(rg.ImmediateFirings[i], firings) {
+			t.Errorf("Expected arc %v to have immediate firings %v, but got %v", a, firings, rg.ImmediateFirings[i])
+		}
+	}
+}
+
+func TestEliminateVanishingCountsImmediateFirings(t *testing.T) {
+	// T0 moves the token from P0 to P1, and the immediate transitions t1 and t2 move it on to P2
+	// and back to P0, so the tangible graph has a single marking and a loop through both.
+	pn := petrinet.NewPetriNet(3, 3)
+	pn.Matrix = []int{
+		1, 0, 0, 0, 0, 1, 1,
+		0, 1, 0, 1, 0, 0, 0,
+		0, 0, 1, 0, 1, 0, 0,
+	}
+	pn.InitialMarking = []int{1, 0, 0}
+	pn.SetImmediate(1, 1, 1)
+	pn.SetImmediate(2, 1, 1)
+
+	rg, err := GenerateTangibleReachabilityGraph(pn, 10, 100)
+	if err != nil {
+		t.Fatalf("Error generating tangible reachability graph: %v", err)
+	}
+	if rg.NumVertices != 1 || rg.NumEdges != 1 {
+		t.Fatalf("Expected 1 marking and 1 arc, but got %d and %d", rg.NumVertices, rg.NumEdges)
+	}
+	expected := []Firing{{Transition: 1, Count: 1}, {Transition: 2, Count: 1}}
+	if !reflect.DeepEqual(rg.ImmediateFirings[0], expected) {
+		t.Errorf("Expected immediate firings %v, but got %v", expected, rg.ImmediateFirings[0])
+	}
+}
+
+func TestTangibleReachabilityGraphRespectsPriorities(t *testing.T) {
+	pn := testnets.ChoiceNet(0)
+	pn.SetImmediate(2, 3, 2)
+
+	rg, err := GenerateTangibleReachabilityGraph(pn, 10, 100)
+	if err != nil {
+		t.Fatalf("Error generating tangible reachability graph: %v", err)
+	}
+	// t2 has the higher priority, so P2 is never marked.
+	if rg.NumVertices != 2 {
+		t.Fatalf("Expected 2 tangible markings, but got %d", rg.NumVertices)
+	}
+	for i := 0; i < rg.NumEdges; i++ {
+		if rg.ArcProbability(i) != 1 {
+			t.Errorf("Expected arc %d to have probability 1, but got %v", i, rg.ArcProbability(i))
+		}
+	}
+}
+
+func TestTangibleReachabilityGraphWithVanishingInitialMarking(t *testing.T) {
+	rg, err := GenerateTangibleReachabilityGraph(testnets.ChoiceNet(1), 10, 100)
+	if err != nil {
+		t.Fatalf("Error generating tangible reachability graph: %v", err)
+	}
+	if rg.NumVertices != 3 {
+		t.Fatalf("Expected 3 tangible markings, but got %d", rg.NumVertices)
+	}
+	// The tangible markings are (0,0,1,0), (0,0,0,1) and (1,0,0,0).
+	expected := []float64{0.25, 0.75, 0}
+	for i, p := range expected {
+		if math.Abs(rg.InitialDistribution[i]-p) > 1e-12 {
+			t.Errorf("Expected initial probability %v for marking %d, but got %v", p, i, rg.InitialDistribution[i])
+		}
+	}
+}
+
+func TestEliminateVanishingRejectsLoops(t *testing.T) {
+	// t0 and t1 move a token back and forth between P0 and P1 in zero time.
+	// T2 is timed and never enabled.
+	pn := petrinet.NewPetriNet(3, 3)
+	pn.Matrix = []int{
+		1, 0, 0, 0, 1, 0, 1,
+		0, 1, 0, 1, 0, 0, 0,
+		0, 0, 1, 0, 0, 1, 0,
+	}
+	pn.InitialMarking = []int{1, 0, 0}
+	pn.SetImmediate(0, 1, 1)
+	pn.SetImmediate(1, 1, 1)
+
+	_, err := GenerateTangibleReachabilityGraph(pn, 10, 100)
+	if !errors.Is(err, ErrVanishingLoop) {
+		t.Errorf("Expected ErrVanishingLoop, but got %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+// Package graphdata converts stochastic Petri nets into the bipartite place/transition graphs that
+// graph neural networks learn from, laid out as the edge_index and feature arrays of PyTorch
+// Geometric and DGL.
+package graphdata
+
+import (
+	"fmt"
+	"io"
+	"spn-benchmark-ds/internal/pkg/npy"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+)
+
+// Edge types of Graph.EdgeType.
+const (
+	// EdgeInput is an input arc from a place to a transition, weighted by its multiplicity.
+	EdgeInput int64 = iota
+	// EdgeOutput is an output arc from a transition to a place, weighted by its multiplicity.
+	EdgeOutput
+	// EdgeInhibitor is an inhibitor arc from a place to a transition, weighted by its threshold.
+	EdgeInhibitor
+)
+
+// Node types of the node_type array.
+const (
+	// NodePlace is a place.
+	NodePlace int64 = iota
+	// NodeTransition is a transition.
+	NodeTransition
+)
+
+// PlaceFeatures names the columns of Graph.PlaceFeatures. The degrees count input and output
+// arcs, not inhibitor arcs.
+var PlaceFeatures = []string{"initial_tokens", "in_degree", "out_degree"}
+
+// TransitionFeatures names the columns of Graph.TransitionFeatures. Immediate is one for
+// immediate transitions and zero for timed ones.
+var TransitionFeatures = []string{"rate", "in_degree", "out_degree", "immediate"}
+
+// Graph is the bipartite graph of a Petri net. Places are the nodes 0 to NumPlaces-1 and
+// transitions the nodes NumPlaces to NumPlaces+NumTransitions-1. Arrays of more than one
+// dimension are flattened in row-major order.
+type Graph struct {
+	NumPlaces      int
+	NumTransitions int
+	// EdgeIndex holds the source node of every edge followed by the target node of every edge, a
+	// 2 × edges array.
+	EdgeIndex []int64
+	// EdgeType holds the type of every edge, such as EdgeInput.
+	EdgeType []int64
+	// EdgeWeight holds the weight of every edge.
+	EdgeWeight []float64
+	// PlaceFeatures is the places × len(PlaceFeatures) feature matrix of the places.
+	PlaceFeatures []float64
+	// TransitionFeatures is the transitions × len(TransitionFeatures) feature matrix of the
+	// transitions.
+	TransitionFeatures []float64
+	// PlaceLabels holds the average number of tokens in each place, or nil without labels.
+	PlaceLabels []float64
+	// TransitionLabels holds the throughput of each transition, or nil without labels.
+	TransitionLabels []float64
+}
+
+// New returns the graph of a net with the given firing rates. Missing rates are zero.
+func New(pn *petrinet.PetriNet, rates []float64) *Graph {
+	places, transitions := pn.Places, pn.Transitions
+	g := &Graph{
+		NumPlaces:          places,
+		NumTransitions:     transitions,
+		PlaceFeatures:      make([]float64, places*len(PlaceFeatures)),
+		TransitionFeatures: make([]float64, transitions*len(TransitionFeatures)),
+	}
+	var sources, targets []int64
+	addEdge := func(source, target int, edgeType int64, weight int) {
+		sources = append(sources, int64(source))
+		targets = append(targets, int64(target))
+		g.EdgeType = append(g.EdgeType, edgeType)
+		g.EdgeWeight = append(g.EdgeWeight, float64(weight))
+	}
+
+	placeFeature := func(p, column int) *float64 { return &g.PlaceFeatures[p*len(PlaceFeatures)+column] }
+	transitionFeature := func(t, column int) *float64 {
+		return &g.TransitionFeatures[t*len(TransitionFeatures)+column]
+	}
+	for p := 0; p < places; p++ {
+		if p < len(pn.InitialMarking) {
+			*placeFeature(p, 0) = float64(pn.InitialMarking[p])
+		}
+		for t := 0; t < transitions; t++ {
+			if weight := pn.At(p, t); weight > 0 {
+				addEdge(p, places+t, EdgeInput, weight)
+				*placeFeature(p, 2)++
+				*transitionFeature(t, 1)++
+			}
+			if weight := pn.At(p, transitions+t); weight > 0 {
+				addEdge(places+t, p, EdgeOutput, weight)
+				*placeFeature(p, 1)++
+				*transitionFeature(t, 2)++
+			}
+			if threshold := pn.Inhibitor(p, t); threshold > 0 {
+				addEdge(p, places+t, EdgeInhibitor, threshold)
+			}
+		}
+	}
+	for t := 0; t < transitions; t++ {
+		if t < len(rates) {
+			*transitionFeature(t, 0) = rates[t]
+		}
+		if pn.IsImmediate(t) {
+			*transitionFeature(t, 3) = 1
+		}
+	}
+	g.EdgeIndex = append(sources, targets...)
+	if g.EdgeIndex == nil {
+		g.EdgeIndex, g.EdgeType, g.EdgeWeight = []int64{}, []int64{}, []float64{}
+	}
+	return g
+}
+
+// NumEdges returns the number of edges of the graph.
+func (g *Graph) NumEdges() int {
+	return len(g.EdgeType)
+}
+
+// SetLabels sets the node labels of the graph: the average number of tokens in each place and
+// the throughput of each transition.
+func (g *Graph) SetLabels(averageMarkings, throughputs []float64) error {
+	if len(averageMarkings) != g.NumPlaces || len(throughputs) != g.NumTransitions {
+		return fmt.Errorf("expected labels for %d places and %d transitions, got %d and %d", g.NumPlaces, g.NumTransitions, len(averageMarkings), len(throughputs))
+	}
+	g.PlaceLabels, g.TransitionLabels = averageMarkings, throughputs
+	return nil
+}
+
+// Arrays returns the arrays of the graph by name: edge_index, edge_type, edge_weight, node_type,
+// place_features, transition_features and, if the graph has labels, place_labels and
+// transition_labels.
+func (g *Graph) Arrays() map[string]npy.Array {
+	nodeTypes := make([]int64, g.NumPlaces+g.NumTransitions)
+	for i := g.NumPlaces; i < len(nodeTypes); i++ {
+		nodeTypes[i] = NodeTransition
+	}
+	arrays := map[string]npy.Array{
+		"edge_index":          {Shape: []int{2, g.NumEdges()}, Data: g.EdgeIndex},
+		"edge_type":           {Shape: []int{g.NumEdges()}, Data: g.EdgeType},
+		"edge_weight":         {Shape: []int{g.NumEdges()}, Data: g.EdgeWeight},
+		"node_type":           {Shape: []int{len(nodeTypes)}, Data: nodeTypes},
+		"place_features":      {Shape: []int{g.NumPlaces, len(PlaceFeatures)}, Data: g.PlaceFeatures},
+		"transition_features": {Shape: []int{g.NumTransitions, len(TransitionFeatures)}, Data: g.TransitionFeatures},
+	}
+	if g.PlaceLabels != nil {
+		arrays["place_labels"] = npy.Array{Shape: []int{g.NumPlaces}, Data: g.PlaceLabels}
+		arrays["transition_labels"] = npy.Array{Shape: []int{g.NumTransitions}, Data: g.TransitionLabels}
+	}
+	return arrays
+}
+
+// arrayOrder is the order of the arrays in a .npz archive.
+var arrayOrder = []string{"edge_index", "edge_type", "edge_weight", "node_type", "place_features", "transition_features", "place_labels", "transition_labels"}
+
+// WriteNPZ writes the arrays of the graph to w as a .npz archive.
+func (g *Graph) WriteNPZ(w io.Writer) error {
+	arrays := g.Arrays()
+	archive := npy.NewNPZWriter(w)
+	for _, name := range arrayOrder {
+		if a, ok := arrays[name]; ok {
+			if err := archive.Add(name, a); err != nil {
+				return err
+			}
+		}
+	}
+	return archive.Close()
+}
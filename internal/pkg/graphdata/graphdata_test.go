@@ -0,0 +1,92 @@
+package graphdata
+
+import (
+	"bytes"
+	"reflect"
+	"spn-benchmark-ds/internal/pkg/npy"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+	"testing"
+)
+
+// testNet returns a net with two places and two transitions: t0 moves two tokens from p0 to p1,
+// the immediate t1 moves one back unless p0 holds three tokens.
+func testNet() *petrinet.PetriNet {
+	pn := petrinet.NewPetriNet(2, 2)
+	pn.Set(0, 0, 2)
+	pn.Set(1, 2, 2)
+	pn.Set(1, 1, 1)
+	pn.Set(0, 3, 1)
+	pn.InitialMarking = []int{4, 0}
+	pn.SetInhibitor(0, 1, 3)
+	pn.SetImmediate(1, 1, 1)
+	return pn
+}
+
+func TestNew(t *testing.T) {
+	g := New(testNet(), []float64{0.5})
+
+	if g.NumEdges() != 5 {
+		t.Fatalf("expected 5 edges, got %d", g.NumEdges())
+	}
+	// Edges are ordered by place, then transition: input, output and inhibitor arcs.
+	if expected := []int64{0, 3, 0, 2, 1, 2, 0, 3, 1, 3}; !reflect.DeepEqual(g.EdgeIndex, expected) {
+		t.Errorf("expected edge index %v, got %v", expected, g.EdgeIndex)
+	}
+	if expected := []int64{EdgeInput, EdgeOutput, EdgeInhibitor, EdgeOutput, EdgeInput}; !reflect.DeepEqual(g.EdgeType, expected) {
+		t.Errorf("expected edge types %v, got %v", expected, g.EdgeType)
+	}
+	if expected := []float64{2, 1, 3, 2, 1}; !reflect.DeepEqual(g.EdgeWeight, expected) {
+		t.Errorf("expected edge weights %v, got %v", expected, g.EdgeWeight)
+	}
+	if expected := []float64{4, 1, 1, 0, 1, 1}; !reflect.DeepEqual(g.PlaceFeatures, expected) {
+		t.Errorf("expected place features %v, got %v", expected, g.PlaceFeatures)
+	}
+	if expected := []float64{0.5, 1, 1, 0, 0, 1, 1, 1}; !reflect.DeepEqual(g.TransitionFeatures, expected) {
+		t.Errorf("expected transition features %v, got %v", expected, g.TransitionFeatures)
+	}
+}
+
+func TestLabelsAndNPZ(t *testing.T) {
+	g := New(testNet(), []float64{0.5, 0})
+	if _, ok := g.Arrays()["place_labels"]; ok {
+		t.Errorf("expected no labels before SetLabels")
+	}
+	if err := g.SetLabels([]float64{1}, []float64{0.1, 0.2}); err == nil {
+		t.Errorf("expected an error for too few place labels")
+	}
+	if err := g.SetLabels([]float64{1.5, 2.5}, []float64{0.1, 0.2}); err != nil {
+		t.Fatalf("SetLabels failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := g.WriteNPZ(&buf); err != nil {
+		t.Fatalf("WriteNPZ failed: %v", err)
+	}
+	arrays, err := npy.ReadNPZ(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadNPZ failed: %v", err)
+	}
+	if !reflect.DeepEqual(arrays, g.Arrays()) {
+		t.Errorf("expected %v, got %v", g.Arrays(), arrays)
+	}
+	if shape := arrays["edge_index"].Shape; !reflect.DeepEqual(shape, []int{2, 5}) {
+		t.Errorf("expected edge_index of shape [2 5], got %v", shape)
+	}
+	if nodeTypes := arrays["node_type"].Data; !reflect.DeepEqual(nodeTypes, []int64{NodePlace, NodePlace, NodeTransition, NodeTransition}) {
+		t.Errorf("expected two places and two transitions, got %v", nodeTypes)
+	}
+}
+
+func TestEmptyNet(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New(petrinet.NewPetriNet(0, 0), nil).WriteNPZ(&buf); err != nil {
+		t.Fatalf("WriteNPZ failed: %v", err)
+	}
+	arrays, err := npy.ReadNPZ(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadNPZ failed: %v", err)
+	}
+	if shape := arrays["edge_index"].Shape; !reflect.DeepEqual(shape, []int{2, 0}) {
+		t.Errorf("expected edge_index of shape [2 0], got %v", shape)
+	}
+}
@@ -9,9 +9,17 @@ import (
 	"spn-benchmark-ds/internal/pkg/augmentation"
 	"spn-benchmark-ds/internal/pkg/generation"
 	"spn-benchmark-ds/internal/pkg/petrinet"
+	"spn-benchmark-ds/internal/pkg/rates"
 	"spn-benchmark-ds/internal/pkg/utils"
 )
 
+// Random streams used by SampleAndTransformData. Each stream is further keyed by the
+// grid cell or sample index, so sampling is reproducible for a given seed.
+const (
+	cellSamplingStream int64 = iota + 1
+	lambdaVariationStream
+)
+
 type TransformedSample struct {
 	PetriNet          *petrinet.PetriNet
 	ReachabilityGraph *generation.ReachabilityGraph
@@ -32,12 +40,8 @@ func PartitionDataIntoGrid(gridDir string, accumulateData bool, rawDataPath stri
 		return fmt.Errorf("failed to initialize grid: %w", err)
 	}
 
-	allData, err := utils.LoadJSONLFile(rawDataPath)
-	if err != nil {
-		return fmt.Errorf("failed to load raw data: %w", err)
-	}
-
-	for _, data := range allData {
+	// The raw data is streamed record by record, so its size is not limited by memory.
+	err = utils.ForEachJSONLRecord(rawDataPath, func(data []byte) error {
 		var sample GridSample
 		if err := json.Unmarshal(data, &sample); err != nil {
 			return fmt.Errorf("failed to unmarshal grid sample: %w", err)
@@ -51,6 +55,10 @@ func PartitionDataIntoGrid(gridDir string, accumulateData bool, rawDataPath stri
 		if err := utils.SaveDataToJSONFile(savePath, sample); err != nil {
 			return fmt.Errorf("failed to save data to JSON file: %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to partition raw data: %w", err)
 	}
 
 	if err := utils.SaveDataToJSONFile(filepath.Join(gridDirPath, "config.json"), gridConfig); err != nil {
@@ -61,7 +69,8 @@ func PartitionDataIntoGrid(gridDir string, accumulateData bool, rawDataPath stri
 }
 
 // SampleAndTransformData samples data from the grid and applies transformations.
-func SampleAndTransformData(gridDir string, samplesPerGrid int, lambdaVariationsPerSample int, minFiringRate, maxFiringRate int) ([]*TransformedSample, error) {
+// Every grid cell and every sampled net draws from its own stream derived from seed.
+func SampleAndTransformData(gridDir string, samplesPerGrid int, lambdaVariationsPerSample int, sampler rates.Sampler, seed int64, opts analysis.Options) ([]*TransformedSample, error) {
 	gridDataLoc := filepath.Clean(gridDir)
 	gridConfigData, err := os.ReadFile(filepath.Join(gridDataLoc, "config.json"))
 	if err != nil {
@@ -80,7 +89,8 @@ func SampleAndTransformData(gridDir string, samplesPerGrid int, lambdaVariations
 	for i := 0; i < numPlaceBins; i++ {
 		for j := 0; j < numMarkingBins; j++ {
 			directoryPath := filepath.Join(gridDataLoc, fmt.Sprintf("p%d", i+1), fmt.Sprintf("m%d", j+1))
-			sampledList, err := utils.SampleJSONFilesFromDirectory(samplesPerGrid, directoryPath)
+			rng := utils.NewRand(seed, cellSamplingStream, int64(i*numMarkingBins+j))
+			sampledList, err := utils.SampleJSONFilesFromDirectory(rng, samplesPerGrid, directoryPath)
 			if err != nil {
 				return nil, fmt.Errorf("failed to sample JSON files: %w", err)
 			}
@@ -95,8 +105,9 @@ func SampleAndTransformData(gridDir string, samplesPerGrid int, lambdaVariations
 	}
 
 	var transformedData []*TransformedSample
-	for _, data := range allData {
-		variations, lambdaValuesList := augmentation.GenerateLambdaVariations(&data.PetriNet, &data.ReachabilityGraph, lambdaVariationsPerSample, minFiringRate, maxFiringRate)
+	for idx, data := range allData {
+		rng := utils.NewRand(seed, lambdaVariationStream, int64(idx))
+		variations, lambdaValuesList := augmentation.GenerateLambdaVariations(rng, &data.PetriNet, &data.ReachabilityGraph, lambdaVariationsPerSample, sampler, opts)
 		for i, variation := range variations {
 			transformedData = append(transformedData, &TransformedSample{
 				PetriNet:          &data.PetriNet,
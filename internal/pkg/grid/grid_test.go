@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"spn-benchmark-ds/internal/pkg/analysis"
+	"spn-benchmark-ds/internal/pkg/rates"
 	"testing"
 )
 
@@ -28,6 +30,29 @@ func TestGetGridIndex(t *testing.T) {
 	}
 }
 
+// netWithoutArcs returns the JSON object of a net of the given size without arcs or tokens.
+func netWithoutArcs(places, transitions int) map[string]interface{} {
+	return map[string]interface{}{
+		"Places":         places,
+		"Transitions":    transitions,
+		"Matrix":         make([]int, places*(2*transitions+1)),
+		"InitialMarking": make([]int, places),
+	}
+}
+
+// graphWithoutEdges returns the JSON object of a bounded reachability graph of the given number of
+// empty markings of the given number of places, without edges.
+func graphWithoutEdges(markings, places int) map[string]interface{} {
+	return map[string]interface{}{
+		"Vertices":       make([]int, markings*places),
+		"VerticesStride": places,
+		"EdgesStride":    2,
+		"NumVertices":    markings,
+		"ArcTransitions": []int{},
+		"IsBounded":      true,
+	}
+}
+
 func TestPartitionDataIntoGrid(t *testing.T) {
 	// Create a temporary directory for the grid
 	gridDir, err := os.MkdirTemp("", "test_grid")
@@ -39,24 +64,12 @@ func TestPartitionDataIntoGrid(t *testing.T) {
 	// Create a dummy raw data file
 	rawData := []map[string]interface{}{
 		{
-			"petri_net": map[string]interface{}{
-				"Places":      5,
-				"Transitions": 3,
-			},
-			"reachability_graph": map[string]interface{}{
-				"NumVertices": 15,
-				"IsBounded":   true,
-			},
+			"petri_net":          netWithoutArcs(5, 3),
+			"reachability_graph": graphWithoutEdges(15, 5),
 		},
 		{
-			"petri_net": map[string]interface{}{
-				"Places":      15,
-				"Transitions": 3,
-			},
-			"reachability_graph": map[string]interface{}{
-				"NumVertices": 25,
-				"IsBounded":   true,
-			},
+			"petri_net":          netWithoutArcs(15, 3),
+			"reachability_graph": graphWithoutEdges(25, 15),
 		},
 	}
 	rawDataPath := filepath.Join(gridDir, "raw_data.jsonl")
@@ -135,8 +148,10 @@ func TestSampleAndTransformData(t *testing.T) {
 	}
 	data1 := map[string]interface{}{
 		"petri_net": map[string]interface{}{
-			"Places":      2,
-			"Transitions": 1,
+			"Places":         2,
+			"Transitions":    1,
+			"Matrix":         []int{1, 0, 1, 0, 1, 0},
+			"InitialMarking": []int{1, 0},
 		},
 		"reachability_graph": map[string]interface{}{
 			"Vertices":       []int{1, 0, 0, 1},
@@ -159,7 +174,7 @@ func TestSampleAndTransformData(t *testing.T) {
 	}
 
 	// Sample and transform the data
-	samples, err := SampleAndTransformData(gridDir, 1, 1, 1, 10)
+	samples, err := SampleAndTransformData(gridDir, 1, 1, rates.Sampler{Min: 1, Max: 10}, 1, analysis.Options{})
 	if err != nil {
 		t.Fatalf("SampleAndTransformData failed: %v", err)
 	}
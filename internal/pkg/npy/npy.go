@@ -0,0 +1,212 @@
+// Package npy writes and reads NumPy arrays in the .npy format and archives of them in the .npz
+// format of numpy.savez, which numpy.load, PyTorch and JAX read directly.
+package npy
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// magic starts every .npy file.
+const magic = "\x93NUMPY"
+
+// headerAlignment is the alignment of the data that follows the header, as numpy writes it.
+const headerAlignment = 64
+
+// ErrFormat is returned when a file is not a .npy file of a supported data type.
+var ErrFormat = errors.New("not a supported .npy file")
+
+// Array is an n-dimensional array with its elements in row-major order.
+type Array struct {
+	// Shape holds the size of every dimension; a scalar has none.
+	Shape []int
+	// Data holds the elements: []float64, []float32, []int64, []int32, []uint8 or []bool.
+	Data interface{}
+}
+
+// dtype returns the numpy type descriptor and the number of elements of the data.
+func dtype(data interface{}) (string, int, error) {
+	switch data := data.(type) {
+	case []float64:
+		return "<f8", len(data), nil
+	case []float32:
+		return "<f4", len(data), nil
+	case []int64:
+		return "<i8", len(data), nil
+	case []int32:
+		return "<i4", len(data), nil
+	case []uint8:
+		return "|u1", len(data), nil
+	case []bool:
+		return "|b1", len(data), nil
+	}
+	return "", 0, fmt.Errorf("unsupported array data %T", data)
+}
+
+// newData returns a slice of n elements of the type with the given numpy type descriptor.
+func newData(descr string, n int) (interface{}, error) {
+	switch descr {
+	case "<f8":
+		return make([]float64, n), nil
+	case "<f4":
+		return make([]float32, n), nil
+	case "<i8":
+		return make([]int64, n), nil
+	case "<i4":
+		return make([]int32, n), nil
+	case "|u1":
+		return make([]uint8, n), nil
+	case "|b1":
+		return make([]bool, n), nil
+	}
+	return nil, fmt.Errorf("%w: data type %s", ErrFormat, descr)
+}
+
+// size returns the number of elements of an array of the given shape.
+func size(shape []int) int {
+	n := 1
+	for _, d := range shape {
+		n *= d
+	}
+	return n
+}
+
+// Write writes an array to w in the .npy format, version 1.0.
+func Write(w io.Writer, a Array) error {
+	descr, n, err := dtype(a.Data)
+	if err != nil {
+		return err
+	}
+	for _, d := range a.Shape {
+		if d < 0 {
+			return fmt.Errorf("invalid shape %v", a.Shape)
+		}
+	}
+	if n != size(a.Shape) {
+		return fmt.Errorf("array of shape %v cannot hold %d elements", a.Shape, n)
+	}
+
+	dims := make([]string, len(a.Shape))
+	for i, d := range a.Shape {
+		dims[i] = strconv.Itoa(d)
+	}
+	shape := "(" + strings.Join(dims, ", ") + ")"
+	if len(dims) == 1 {
+		shape = "(" + dims[0] + ",)"
+	}
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': %s, }", descr, shape)
+	// The header ends with a newline and is padded with spaces to align the data.
+	prefix := len(magic) + 4
+	padding := headerAlignment - (prefix+len(header)+1)%headerAlignment
+	header += strings.Repeat(" ", padding%headerAlignment) + "\n"
+
+	buf := bufio.NewWriter(w)
+	buf.WriteString(magic)
+	buf.Write([]byte{1, 0})
+	binary.Write(buf, binary.LittleEndian, uint16(len(header)))
+	buf.WriteString(header)
+	if err := binary.Write(buf, binary.LittleEndian, a.Data); err != nil {
+		return fmt.Errorf("failed to write array data: %w", err)
+	}
+	return buf.Flush()
+}
+
+// Read reads an array in the .npy format from r. It reads little-endian arrays of the data types
+// of Array in C order.
+func Read(r io.Reader) (Array, error) {
+	prefix := make([]byte, len(magic)+2)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return Array{}, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	if string(prefix[:len(magic)]) != magic {
+		return Array{}, fmt.Errorf("%w: missing magic bytes", ErrFormat)
+	}
+	var headerLength int
+	switch major := prefix[len(magic)]; major {
+	case 1:
+		var length uint16
+		if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+			return Array{}, fmt.Errorf("%w: %v", ErrFormat, err)
+		}
+		headerLength = int(length)
+	case 2, 3:
+		var length uint32
+		if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+			return Array{}, fmt.Errorf("%w: %v", ErrFormat, err)
+		}
+		headerLength = int(length)
+	default:
+		return Array{}, fmt.Errorf("%w: version %d", ErrFormat, major)
+	}
+	header := make([]byte, headerLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return Array{}, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+
+	descr, fortran, shape, err := parseHeader(string(header))
+	if err != nil {
+		return Array{}, err
+	}
+	if fortran && len(shape) > 1 {
+		return Array{}, fmt.Errorf("%w: Fortran order", ErrFormat)
+	}
+	data, err := newData(descr, size(shape))
+	if err != nil {
+		return Array{}, err
+	}
+	if err := binary.Read(r, binary.LittleEndian, data); err != nil {
+		return Array{}, fmt.Errorf("failed to read array data: %w", err)
+	}
+	return Array{Shape: shape, Data: data}, nil
+}
+
+// parseHeader parses the dictionary of a .npy header.
+func parseHeader(header string) (string, bool, []int, error) {
+	value := func(key string) (string, error) {
+		i := strings.Index(header, "'"+key+"':")
+		if i < 0 {
+			return "", fmt.Errorf("%w: header without %s", ErrFormat, key)
+		}
+		return strings.TrimSpace(header[i+len(key)+3:]), nil
+	}
+
+	descr, err := value("descr")
+	if err != nil {
+		return "", false, nil, err
+	}
+	if len(descr) < 2 || descr[0] != '\'' || !strings.Contains(descr[1:], "'") {
+		return "", false, nil, fmt.Errorf("%w: invalid descr", ErrFormat)
+	}
+	descr = descr[1 : 1+strings.Index(descr[1:], "'")]
+
+	fortran, err := value("fortran_order")
+	if err != nil {
+		return "", false, nil, err
+	}
+
+	shapeText, err := value("shape")
+	if err != nil {
+		return "", false, nil, err
+	}
+	end := strings.Index(shapeText, ")")
+	if !strings.HasPrefix(shapeText, "(") || end < 0 {
+		return "", false, nil, fmt.Errorf("%w: invalid shape", ErrFormat)
+	}
+	var shape []int
+	for _, dim := range strings.Split(shapeText[1:end], ",") {
+		if dim = strings.TrimSpace(dim); dim == "" {
+			continue
+		}
+		d, err := strconv.Atoi(dim)
+		if err != nil || d < 0 {
+			return "", false, nil, fmt.Errorf("%w: invalid shape %s", ErrFormat, shapeText[:end+1])
+		}
+		shape = append(shape, d)
+	}
+	return descr, strings.HasPrefix(fortran, "True"), shape, nil
+}
@@ -0,0 +1,92 @@
+package npy
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteMatchesNumPyHeader(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Array{Shape: []int{2, 3}, Data: []float64{1, 2, 3, 4, 5, 6}}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	data := buf.Bytes()
+	// numpy.save writes the same header, padded so the data starts at a multiple of 64 bytes.
+	expected := "\x93NUMPY\x01\x00\x76\x00{'descr': '<f8', 'fortran_order': False, 'shape': (2, 3), }"
+	if !strings.HasPrefix(string(data), expected) {
+		t.Errorf("expected the file to start with %q, got %q", expected, data[:len(expected)])
+	}
+	if len(data) != 128+6*8 || data[127] != '\n' {
+		t.Errorf("expected a 128-byte header ending with a newline and 48 bytes of data, got %d bytes", len(data))
+	}
+}
+
+func TestWriteAndRead(t *testing.T) {
+	arrays := []Array{
+		{Shape: []int{2, 2}, Data: []float64{0.5, -1, 3, 1e-300}},
+		{Shape: []int{3}, Data: []int64{-1, 0, 1 << 40}},
+		{Shape: []int{1, 2, 1}, Data: []int32{7, -7}},
+		{Shape: []int{2}, Data: []float32{1.5, 2.5}},
+		{Shape: []int{4}, Data: []bool{true, false, false, true}},
+		{Shape: []int{0}, Data: []uint8{}},
+		{Shape: nil, Data: []float64{42}},
+	}
+	for _, a := range arrays {
+		var buf bytes.Buffer
+		if err := Write(&buf, a); err != nil {
+			t.Fatalf("Write failed for %v: %v", a, err)
+		}
+		if headerLength := 10 + int(buf.Bytes()[8]) + int(buf.Bytes()[9])<<8; headerLength%64 != 0 || buf.Bytes()[headerLength-1] != '\n' {
+			t.Errorf("expected the data of %v to start at a multiple of 64 bytes after a newline, got %d", a, headerLength)
+		}
+		got, err := Read(&buf)
+		if err != nil {
+			t.Fatalf("Read failed for %v: %v", a, err)
+		}
+		if len(got.Shape) != len(a.Shape) || (len(a.Shape) > 0 && !reflect.DeepEqual(got.Shape, a.Shape)) || !reflect.DeepEqual(got.Data, a.Data) {
+			t.Errorf("expected %v, got %v", a, got)
+		}
+	}
+}
+
+func TestWriteAndReadErrors(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, Array{Shape: []int{2, 2}, Data: []float64{1}}); err == nil {
+		t.Errorf("expected an error for data that does not fill the shape")
+	}
+	if err := Write(&bytes.Buffer{}, Array{Shape: []int{1}, Data: []string{"a"}}); err == nil {
+		t.Errorf("expected an error for unsupported data")
+	}
+	for _, input := range []string{"", "NUMPY", "\x93NUMPY\x01\x00\x10\x00{'descr': '<c16'}", "\x93NUMPY\x01\x00\x30\x00{'descr': '>f8', 'fortran_order': False, 'shape': (1,), }"} {
+		if _, err := Read(strings.NewReader(input)); !errors.Is(err, ErrFormat) {
+			t.Errorf("expected ErrFormat for %q, got %v", input, err)
+		}
+	}
+}
+
+func TestNPZ(t *testing.T) {
+	var buf bytes.Buffer
+	archive := NewNPZWriter(&buf)
+	if err := archive.Add("edge_index", Array{Shape: []int{2, 1}, Data: []int64{0, 1}}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := archive.Add("labels", Array{Shape: []int{1}, Data: []float64{0.25}}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := archive.Add("labels", Array{Shape: []int{1}, Data: []float64{0.5}}); err == nil {
+		t.Errorf("expected an error for a repeated name")
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	arrays, err := ReadNPZ(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadNPZ failed: %v", err)
+	}
+	if len(arrays) != 2 || !reflect.DeepEqual(arrays["edge_index"].Data, []int64{0, 1}) || !reflect.DeepEqual(arrays["labels"].Shape, []int{1}) {
+		t.Errorf("expected the arrays back, got %v", arrays)
+	}
+}
@@ -0,0 +1,68 @@
+package npy
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NPZWriter writes arrays to a .npz archive: a zip file holding one .npy file per array, named
+// after the array.
+type NPZWriter struct {
+	zip   *zip.Writer
+	names map[string]bool
+}
+
+// NewNPZWriter returns a writer of a .npz archive to w.
+func NewNPZWriter(w io.Writer) *NPZWriter {
+	return &NPZWriter{zip: zip.NewWriter(w), names: map[string]bool{}}
+}
+
+// Add writes an array to the archive under the given name.
+func (z *NPZWriter) Add(name string, a Array) error {
+	if name == "" || strings.ContainsAny(name, "/\\") || z.names[name] {
+		return fmt.Errorf("invalid or repeated array name %q", name)
+	}
+	z.names[name] = true
+	// Like numpy.savez, the arrays are stored uncompressed.
+	file, err := z.zip.CreateHeader(&zip.FileHeader{Name: name + ".npy", Method: zip.Store})
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if err := Write(file, a); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// Close writes the directory of the archive. It does not close the underlying writer.
+func (z *NPZWriter) Close() error {
+	return z.zip.Close()
+}
+
+// ReadNPZ reads every array of the .npz archive of the given size in r, by name.
+func ReadNPZ(r io.ReaderAt, size int64) (map[string]Array, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	arrays := make(map[string]Array, len(archive.File))
+	for _, file := range archive.File {
+		name, ok := strings.CutSuffix(file.Name, ".npy")
+		if !ok {
+			continue
+		}
+		content, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", file.Name, err)
+		}
+		a, err := Read(content)
+		content.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		arrays[name] = a
+	}
+	return arrays, nil
+}
@@ -0,0 +1,117 @@
+package petrinet
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Firing delay distributions of timed transitions, accepted by Delay.Kind.
+const (
+	// DelayExponential is the exponential distribution of stochastic Petri nets.
+	DelayExponential = "exponential"
+	// DelayDeterministic always takes the mean.
+	DelayDeterministic = "deterministic"
+	// DelayErlang is the sum of Stages exponential phases, with squared coefficient of variation
+	// 1/Stages.
+	DelayErlang = "erlang"
+	// DelayHyperexponential is a two-phase hyperexponential distribution with balanced means and
+	// squared coefficient of variation SCV.
+	DelayHyperexponential = "hyperexponential"
+	// DelayUniform is uniform on [mean·(1-Spread), mean·(1+Spread)].
+	DelayUniform = "uniform"
+	// DelayLognormal is the lognormal distribution with squared coefficient of variation SCV.
+	DelayLognormal = "lognormal"
+)
+
+// Delay is the firing delay distribution of a timed transition. The mean delay of transition t
+// is always 1/λ_t, so the firing rates keep their meaning; the distribution only sets its shape.
+// The zero value is exponential.
+type Delay struct {
+	// Kind is the distribution; empty means DelayExponential.
+	Kind string
+	// Stages is the number of phases of an Erlang distribution.
+	Stages int `json:",omitempty"`
+	// SCV is the squared coefficient of variation of a hyperexponential or lognormal distribution.
+	SCV float64 `json:",omitempty"`
+	// Spread is the half-width of a uniform distribution relative to its mean, in [0, 1].
+	Spread float64 `json:",omitempty"`
+}
+
+// IsExponential reports whether the delay is exponential.
+func (d Delay) IsExponential() bool {
+	return d.Kind == "" || d.Kind == DelayExponential
+}
+
+// Validate checks the kind and parameters of the distribution.
+func (d Delay) Validate() error {
+	switch d.Kind {
+	case "", DelayExponential, DelayDeterministic:
+		return nil
+	case DelayErlang:
+		if d.Stages < 1 {
+			return fmt.Errorf("Erlang delay needs at least 1 stage, got %d", d.Stages)
+		}
+	case DelayHyperexponential:
+		if d.SCV < 1 {
+			return fmt.Errorf("hyperexponential delay needs a squared coefficient of variation of at least 1, got %g", d.SCV)
+		}
+	case DelayUniform:
+		if d.Spread < 0 || d.Spread > 1 {
+			return fmt.Errorf("uniform delay needs a spread in [0, 1], got %g", d.Spread)
+		}
+	case DelayLognormal:
+		if d.SCV <= 0 {
+			return fmt.Errorf("lognormal delay needs a positive squared coefficient of variation, got %g", d.SCV)
+		}
+	default:
+		return fmt.Errorf("unknown delay distribution %q", d.Kind)
+	}
+	return nil
+}
+
+// Delay returns the firing delay distribution of transition t.
+func (pn *PetriNet) Delay(t int) Delay {
+	if t < len(pn.Delays) {
+		return pn.Delays[t]
+	}
+	return Delay{}
+}
+
+// SetDelay sets the firing delay distribution of transition t.
+func (pn *PetriNet) SetDelay(t int, d Delay) {
+	if pn.Delays == nil {
+		if d.IsExponential() {
+			return
+		}
+		pn.Delays = make([]Delay, pn.Transitions)
+	}
+	pn.Delays[t] = d
+}
+
+// HasNonExponentialDelays reports whether a timed transition of the net has a delay that is not
+// exponential, in which case the net is not Markovian and can only be simulated.
+func (pn *PetriNet) HasNonExponentialDelays() bool {
+	for t, d := range pn.Delays {
+		if !d.IsExponential() && !pn.IsImmediate(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// AssignDelays gives every timed transition, with the given probability, a delay distribution
+// drawn uniformly from delays. rng is left untouched when the probability is zero or there are no
+// delays to draw from.
+func (pn *PetriNet) AssignDelays(rng *rand.Rand, probability float64, delays []Delay) {
+	if probability <= 0 || len(delays) == 0 {
+		return
+	}
+	for t := 0; t < pn.Transitions; t++ {
+		if pn.IsImmediate(t) {
+			continue
+		}
+		if rng.Float64() < probability {
+			pn.SetDelay(t, delays[rng.Intn(len(delays))])
+		}
+	}
+}
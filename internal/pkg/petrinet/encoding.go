@@ -0,0 +1,138 @@
+package petrinet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"spn-benchmark-ds/internal/pkg/spn"
+)
+
+// plainPetriNet has the fields of PetriNet without its methods, for the default JSON encoding.
+type plainPetriNet PetriNet
+
+// FromMatrix returns a net of the given dimensions with the given flattened places ×
+// (2·transitions+1) matrix and initial marking, or an error if they do not fit the dimensions.
+// A nil initial marking is read from the last column of the matrix. The net keeps both slices.
+func FromMatrix(places, transitions int, matrix, initialMarking []int) (*PetriNet, error) {
+	if places < 0 || transitions < 0 {
+		return nil, fmt.Errorf("invalid dimensions: %d places and %d transitions", places, transitions)
+	}
+	pn := &PetriNet{
+		Places:         places,
+		Transitions:    transitions,
+		Matrix:         matrix,
+		stride:         2*transitions + 1,
+		InitialMarking: initialMarking,
+	}
+	if initialMarking == nil && len(matrix) == places*pn.stride {
+		pn.InitialMarking = make([]int, places)
+		pn.updateInitialMarking()
+	}
+	if err := pn.Validate(); err != nil {
+		return nil, err
+	}
+	return pn, nil
+}
+
+// Validate checks that the matrix, the initial marking and the optional per-transition and
+// inhibitor slices of the net fit its dimensions.
+func (pn *PetriNet) Validate() error {
+	if pn.Places < 0 || pn.Transitions < 0 {
+		return fmt.Errorf("invalid dimensions: %d places and %d transitions", pn.Places, pn.Transitions)
+	}
+	if expected := pn.Places * (2*pn.Transitions + 1); len(pn.Matrix) != expected {
+		return fmt.Errorf("matrix of %d places and %d transitions has %d entries, expected %d", pn.Places, pn.Transitions, len(pn.Matrix), expected)
+	}
+	if len(pn.InitialMarking) != pn.Places {
+		return fmt.Errorf("initial marking has %d places, expected %d", len(pn.InitialMarking), pn.Places)
+	}
+	for _, s := range []struct {
+		name   string
+		length int
+	}{
+		{"immediate flags", len(pn.Immediate)},
+		{"weights", len(pn.Weights)},
+		{"priorities", len(pn.Priorities)},
+		{"delays", len(pn.Delays)},
+	} {
+		if s.length != 0 && s.length != pn.Transitions {
+			return fmt.Errorf("%d %s for %d transitions", s.length, s.name, pn.Transitions)
+		}
+	}
+	if len(pn.Inhibitors) != 0 && len(pn.Inhibitors) != pn.Places*pn.Transitions {
+		return fmt.Errorf("inhibitor matrix has %d entries, expected %d", len(pn.Inhibitors), pn.Places*pn.Transitions)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the net with its exported fields. It returns an error for a net that does
+// not fit its dimensions, since it could not be decoded.
+func (pn PetriNet) MarshalJSON() ([]byte, error) {
+	if err := pn.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid Petri net: %w", err)
+	}
+	return json.Marshal(plainPetriNet(pn))
+}
+
+// UnmarshalJSON decodes a net encoded by MarshalJSON, restoring the stride of its matrix. It
+// returns an error for a net that does not fit its dimensions.
+func (pn *PetriNet) UnmarshalJSON(data []byte) error {
+	var plain plainPetriNet
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return err
+	}
+	decoded, err := FromMatrix(plain.Places, plain.Transitions, plain.Matrix, plain.InitialMarking)
+	if err != nil {
+		return fmt.Errorf("invalid Petri net: %w", err)
+	}
+	decoded.Immediate, decoded.Weights, decoded.Priorities = plain.Immediate, plain.Weights, plain.Priorities
+	decoded.Inhibitors, decoded.Delays = plain.Inhibitors, plain.Delays
+	if err := decoded.Validate(); err != nil {
+		return fmt.Errorf("invalid Petri net: %w", err)
+	}
+	*pn = *decoded
+	return nil
+}
+
+// ToProto converts the net to the protobuf format.
+func (pn *PetriNet) ToProto() *spn.PetriNet {
+	var delays []*spn.Delay
+	for _, d := range pn.Delays {
+		delays = append(delays, &spn.Delay{Kind: d.Kind, Stages: int32(d.Stages), Scv: d.SCV, Spread: d.Spread})
+	}
+	return &spn.PetriNet{
+		Places:         int32(pn.Places),
+		Transitions:    int32(pn.Transitions),
+		Matrix:         spn.ToInt32Slice(pn.Matrix),
+		Immediate:      pn.Immediate,
+		Weights:        pn.Weights,
+		Priorities:     spn.ToInt32Slice(pn.Priorities),
+		Inhibitors:     spn.ToInt32Slice(pn.Inhibitors),
+		Delays:         delays,
+		InitialMarking: spn.ToInt32Slice(pn.InitialMarking),
+	}
+}
+
+// FromProto converts a net of the protobuf format, or returns an error if it does not fit its
+// dimensions. Nets written before the initial marking was recorded get it from the last column of
+// the matrix.
+func FromProto(net *spn.PetriNet) (*PetriNet, error) {
+	if net == nil {
+		return nil, errors.New("missing Petri net")
+	}
+	pn, err := FromMatrix(int(net.GetPlaces()), int(net.GetTransitions()), spn.ToIntSlice(net.GetMatrix()), spn.ToIntSlice(net.GetInitialMarking()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Petri net: %w", err)
+	}
+	pn.Immediate = net.GetImmediate()
+	pn.Weights = net.GetWeights()
+	pn.Priorities = spn.ToIntSlice(net.GetPriorities())
+	pn.Inhibitors = spn.ToIntSlice(net.GetInhibitors())
+	for _, d := range net.GetDelays() {
+		pn.Delays = append(pn.Delays, Delay{Kind: d.GetKind(), Stages: int(d.GetStages()), SCV: d.GetScv(), Spread: d.GetSpread()})
+	}
+	if err := pn.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid Petri net: %w", err)
+	}
+	return pn, nil
+}
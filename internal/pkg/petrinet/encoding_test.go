@@ -0,0 +1,136 @@
+package petrinet
+
+import (
+	"encoding/json"
+	"reflect"
+	"spn-benchmark-ds/internal/pkg/spn"
+	"testing"
+)
+
+// newEncodingNet builds a net in which t0 moves a token from P0 to P1 and t1 moves it back. It
+// uses every optional field: t1 is immediate, an inhibitor arc from P1 guards t0, and t0 has an
+// Erlang delay.
+func newEncodingNet() *PetriNet {
+	pn := NewPetriNet(2, 2)
+	pn.Set(0, 0, 1)
+	pn.Set(1, 2, 1)
+	pn.Set(1, 1, 1)
+	pn.Set(0, 3, 1)
+	pn.Set(0, 4, 3)
+	pn.Set(1, 4, 2)
+	pn.updateInitialMarking()
+	pn.SetImmediate(1, 2.5, 1)
+	pn.SetInhibitor(1, 0, 4)
+	pn.Delays = []Delay{{Kind: DelayErlang, Stages: 3}, {}}
+	return pn
+}
+
+// checkEncodingNet checks that a decoded net equals newEncodingNet and can be indexed.
+func checkEncodingNet(t *testing.T, source string, pn *PetriNet) {
+	t.Helper()
+	want := newEncodingNet()
+	if !reflect.DeepEqual(pn, want) {
+		t.Errorf("%s: expected %+v, got %+v", source, want, pn)
+	}
+	for p := 0; p < want.Places; p++ {
+		for col := 0; col <= 2*want.Transitions; col++ {
+			if pn.At(p, col) != want.At(p, col) {
+				t.Errorf("%s: expected %d at (%d, %d), got %d", source, want.At(p, col), p, col, pn.At(p, col))
+			}
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	data, err := json.Marshal(newEncodingNet())
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var pn PetriNet
+	if err := json.Unmarshal(data, &pn); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	checkEncodingNet(t, "JSON", &pn)
+
+	// A net embedded by value is encoded the same way.
+	embedded, err := json.Marshal(struct{ Net PetriNet }{*newEncodingNet()})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded struct{ Net *PetriNet }
+	if err := json.Unmarshal(embedded, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	checkEncodingNet(t, "embedded JSON", decoded.Net)
+}
+
+func TestProtoRoundTrip(t *testing.T) {
+	net := newEncodingNet().ToProto()
+	if !reflect.DeepEqual(net.GetInitialMarking(), []int32{3, 2}) {
+		t.Errorf("expected initial marking [3 2], got %v", net.GetInitialMarking())
+	}
+	pn, err := FromProto(net)
+	if err != nil {
+		t.Fatalf("FromProto failed: %v", err)
+	}
+	checkEncodingNet(t, "protobuf", pn)
+
+	// Nets written before the initial marking was recorded take it from the matrix.
+	net.InitialMarking = nil
+	if pn, err = FromProto(net); err != nil {
+		t.Fatalf("FromProto failed: %v", err)
+	}
+	checkEncodingNet(t, "protobuf without initial marking", pn)
+}
+
+func TestFromMatrix(t *testing.T) {
+	pn, err := FromMatrix(2, 1, []int{1, 0, 2, 0, 1, 0}, nil)
+	if err != nil {
+		t.Fatalf("FromMatrix failed: %v", err)
+	}
+	if pn.At(1, 1) != 1 || !reflect.DeepEqual(pn.InitialMarking, []int{2, 0}) {
+		t.Errorf("unexpected net %+v", pn)
+	}
+
+	for name, tc := range map[string]struct {
+		places, transitions int
+		matrix, marking     []int
+	}{
+		"negative dimensions": {-1, 1, nil, nil},
+		"short matrix":        {2, 1, []int{1, 0, 2}, nil},
+		"transposed matrix":   {1, 2, []int{1, 0, 2, 0, 1, 0}, nil},
+		"wrong marking":       {2, 1, []int{1, 0, 2, 0, 1, 0}, []int{2}},
+	} {
+		if _, err := FromMatrix(tc.places, tc.transitions, tc.matrix, tc.marking); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestDecodeInvalidNets(t *testing.T) {
+	for name, data := range map[string]string{
+		"short matrix":      `{"Places": 2, "Transitions": 1, "Matrix": [1, 0, 2], "InitialMarking": [2, 0]}`,
+		"wrong marking":     `{"Places": 2, "Transitions": 1, "Matrix": [1, 0, 2, 0, 1, 0], "InitialMarking": [2]}`,
+		"wrong immediate":   `{"Places": 2, "Transitions": 1, "Matrix": [1, 0, 2, 0, 1, 0], "Immediate": [true, false]}`,
+		"wrong inhibitors":  `{"Places": 2, "Transitions": 1, "Matrix": [1, 0, 2, 0, 1, 0], "Inhibitors": [1]}`,
+		"wrong delay count": `{"Places": 2, "Transitions": 1, "Matrix": [1, 0, 2, 0, 1, 0], "Delays": [{}, {}]}`,
+	} {
+		var pn PetriNet
+		if err := json.Unmarshal([]byte(data), &pn); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if _, err := FromProto(&spn.PetriNet{Places: 2, Transitions: 1, Matrix: []int32{1, 0, 2}}); err == nil {
+		t.Errorf("expected an error for a short protobuf matrix")
+	}
+	if _, err := FromProto(nil); err == nil {
+		t.Errorf("expected an error for a missing protobuf net")
+	}
+
+	pn := newEncodingNet()
+	pn.InitialMarking = pn.InitialMarking[:1]
+	if _, err := json.Marshal(pn); err == nil {
+		t.Errorf("expected an error marshalling an invalid net")
+	}
+}
@@ -1,8 +1,17 @@
 package petrinet
 
 import (
+	"fmt"
 	"math/rand"
-	"time"
+)
+
+// Arc weight distributions accepted by AssignArcWeights.
+const (
+	// ArcWeightUniform draws arc weights uniformly from [1, maxWeight].
+	ArcWeightUniform = "uniform"
+	// ArcWeightGeometric draws arc weight w < maxWeight with probability 2^-w, and maxWeight with
+	// the remaining probability 2^-(maxWeight-1), so half of the arcs keep weight one.
+	ArcWeightGeometric = "geometric"
 )
 
 // PetriNet represents a Petri Net.
@@ -17,6 +26,22 @@ type PetriNet struct {
 	stride int
 	// InitialMarking is the initial marking of the Petri net.
 	InitialMarking []int
+	// Immediate marks the immediate transitions, which fire in zero time as soon as they are enabled.
+	// All transitions are exponentially timed when it is nil.
+	Immediate []bool `json:",omitempty"`
+	// Weights holds the firing weight of each immediate transition. Conflicts between enabled
+	// immediate transitions of equal priority are resolved with probability proportional to weight.
+	Weights []float64 `json:",omitempty"`
+	// Priorities holds the priority of each immediate transition. Only the enabled immediate
+	// transitions with the highest priority may fire. Timed transitions have priority zero.
+	Priorities []int `json:",omitempty"`
+	// Inhibitors is the flattened places × transitions matrix of inhibitor arc thresholds. A value
+	// k > 0 at (p, t) disables t whenever place p holds at least k tokens.
+	// The net has no inhibitor arcs when it is nil.
+	Inhibitors []int `json:",omitempty"`
+	// Delays holds the firing delay distribution of each timed transition. All delays are
+	// exponential when it is nil.
+	Delays []Delay `json:",omitempty"`
 }
 
 // At returns the value of the matrix at the given row and column.
@@ -42,10 +67,160 @@ func NewPetriNet(places, transitions int) *PetriNet {
 	}
 }
 
+// Clone returns a deep copy of the Petri net.
+func (pn *PetriNet) Clone() *PetriNet {
+	clone := NewPetriNet(pn.Places, pn.Transitions)
+	copy(clone.Matrix, pn.Matrix)
+	copy(clone.InitialMarking, pn.InitialMarking)
+	if pn.Immediate != nil {
+		clone.Immediate = append([]bool(nil), pn.Immediate...)
+		clone.Weights = append([]float64(nil), pn.Weights...)
+		clone.Priorities = append([]int(nil), pn.Priorities...)
+	}
+	if pn.Inhibitors != nil {
+		clone.Inhibitors = append([]int(nil), pn.Inhibitors...)
+	}
+	if pn.Delays != nil {
+		clone.Delays = append([]Delay(nil), pn.Delays...)
+	}
+	return clone
+}
+
+// Inhibitor returns the threshold of the inhibitor arc from place p to transition t, or zero if
+// there is none.
+func (pn *PetriNet) Inhibitor(p, t int) int {
+	if pn.Inhibitors == nil {
+		return 0
+	}
+	return pn.Inhibitors[p*pn.Transitions+t]
+}
+
+// SetInhibitor sets the threshold of the inhibitor arc from place p to transition t.
+// A threshold of zero removes the arc.
+func (pn *PetriNet) SetInhibitor(p, t, threshold int) {
+	if pn.Inhibitors == nil {
+		if threshold == 0 {
+			return
+		}
+		pn.Inhibitors = make([]int, pn.Places*pn.Transitions)
+	}
+	pn.Inhibitors[p*pn.Transitions+t] = threshold
+}
+
+// HasInhibitorArcs reports whether the net has at least one inhibitor arc.
+func (pn *PetriNet) HasInhibitorArcs() bool {
+	for _, threshold := range pn.Inhibitors {
+		if threshold > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// AddInhibitorArcsRandomly adds an inhibitor arc with the given probability between every place
+// and transition not already joined by an input arc, drawing thresholds in [1, maxThreshold].
+// Input places are skipped because an inhibitor threshold at or below the input weight would make
+// the transition dead.
+func (pn *PetriNet) AddInhibitorArcsRandomly(rng *rand.Rand, probability float64, maxThreshold int) {
+	if maxThreshold < 1 {
+		maxThreshold = 1
+	}
+	for p := 0; p < pn.Places; p++ {
+		for t := 0; t < pn.Transitions; t++ {
+			if pn.At(p, t) != 0 {
+				continue
+			}
+			if rng.Float64() < probability {
+				pn.SetInhibitor(p, t, 1+rng.Intn(maxThreshold))
+			}
+		}
+	}
+}
+
+// IsImmediate reports whether transition t is immediate.
+func (pn *PetriNet) IsImmediate(t int) bool {
+	return t < len(pn.Immediate) && pn.Immediate[t]
+}
+
+// HasImmediateTransitions reports whether the net has at least one immediate transition,
+// i.e. whether it is a generalized SPN.
+func (pn *PetriNet) HasImmediateTransitions() bool {
+	for _, immediate := range pn.Immediate {
+		if immediate {
+			return true
+		}
+	}
+	return false
+}
+
+// Weight returns the firing weight of transition t. Transitions without a weight have weight one.
+func (pn *PetriNet) Weight(t int) float64 {
+	if t < len(pn.Weights) && pn.Weights[t] > 0 {
+		return pn.Weights[t]
+	}
+	return 1
+}
+
+// Priority returns the priority of transition t, which is zero for timed transitions.
+func (pn *PetriNet) Priority(t int) int {
+	if !pn.IsImmediate(t) {
+		return 0
+	}
+	if t < len(pn.Priorities) && pn.Priorities[t] > 0 {
+		return pn.Priorities[t]
+	}
+	return 1
+}
+
+// SetImmediate makes transition t immediate with the given weight and priority.
+// Priorities below one are raised to one, so immediate transitions always preempt timed ones.
+func (pn *PetriNet) SetImmediate(t int, weight float64, priority int) {
+	if pn.Immediate == nil {
+		pn.Immediate = make([]bool, pn.Transitions)
+		pn.Weights = make([]float64, pn.Transitions)
+		pn.Priorities = make([]int, pn.Transitions)
+		for i := range pn.Weights {
+			pn.Weights[i] = 1
+		}
+	}
+	if priority < 1 {
+		priority = 1
+	}
+	pn.Immediate[t] = true
+	pn.Weights[t] = weight
+	pn.Priorities[t] = priority
+}
+
+// AssignImmediateTransitions turns each transition into an immediate transition with the given
+// probability, drawing integer weights in [1, maxWeight] and priorities in [1, maxPriority].
+// At least one transition is always left timed so the net can make timed progress.
+func (pn *PetriNet) AssignImmediateTransitions(rng *rand.Rand, probability float64, maxWeight, maxPriority int) {
+	if maxWeight < 1 {
+		maxWeight = 1
+	}
+	if maxPriority < 1 {
+		maxPriority = 1
+	}
+	numImmediate := 0
+	for t := 0; t < pn.Transitions; t++ {
+		if rng.Float64() < probability {
+			pn.SetImmediate(t, float64(1+rng.Intn(maxWeight)), 1+rng.Intn(maxPriority))
+			numImmediate++
+		}
+	}
+	if numImmediate == pn.Transitions {
+		t := rng.Intn(pn.Transitions)
+		pn.Immediate[t] = false
+		pn.Weights[t] = 1
+		pn.Priorities[t] = 0
+	}
+}
+
 // GenerateRandomPetriNet generates a random Petri net matrix.
-// It takes the number of places and transitions and returns a new Petri net.
-func GenerateRandomPetriNet(numPlaces, numTransitions int) *PetriNet {
-	rand.Seed(time.Now().UnixNano())
+// It takes a random source and the number of places and transitions and returns a new Petri net.
+// Every arc has weight one; see AssignArcWeights.
+// The same source state always yields the same net.
+func GenerateRandomPetriNet(rng *rand.Rand, numPlaces, numTransitions int) *PetriNet {
 	pn := NewPetriNet(numPlaces, numTransitions)
 
 	remainingNodes := make([]int, numPlaces+numTransitions)
@@ -53,13 +228,13 @@ func GenerateRandomPetriNet(numPlaces, numTransitions int) *PetriNet {
 		remainingNodes[i] = i + 1
 	}
 
-	firstPlace := rand.Intn(numPlaces) + 1
-	firstTransition := rand.Intn(numTransitions) + numPlaces + 1
+	firstPlace := rng.Intn(numPlaces) + 1
+	firstTransition := rng.Intn(numTransitions) + numPlaces + 1
 
 	removeNode(remainingNodes, firstPlace)
 	removeNode(remainingNodes, firstTransition)
 
-	if rand.Float64() <= 0.5 {
+	if rng.Float64() <= 0.5 {
 		pn.Set(firstPlace-1, firstTransition-numPlaces-1, 1)
 	} else {
 		pn.Set(firstPlace-1, firstTransition-numPlaces-1+numTransitions, 1)
@@ -69,7 +244,7 @@ func GenerateRandomPetriNet(numPlaces, numTransitions int) *PetriNet {
 	subGraph := make([]int, 0, numPlaces+numTransitions)
 	subGraph = append(subGraph, firstPlace, firstTransition)
 
-	rand.Shuffle(len(remainingNodes), func(i, j int) {
+	rng.Shuffle(len(remainingNodes), func(i, j int) {
 		remainingNodes[i], remainingNodes[j] = remainingNodes[j], remainingNodes[i]
 	})
 
@@ -93,13 +268,13 @@ func GenerateRandomPetriNet(numPlaces, numTransitions int) *PetriNet {
 		var place, transition int
 		if node <= numPlaces {
 			place = node
-			transition = subTransitions[rand.Intn(len(subTransitions))]
+			transition = subTransitions[rng.Intn(len(subTransitions))]
 		} else {
-			place = subPlaces[rand.Intn(len(subPlaces))]
+			place = subPlaces[rng.Intn(len(subPlaces))]
 			transition = node
 		}
 
-		if rand.Float64() <= 0.5 {
+		if rng.Float64() <= 0.5 {
 			pn.Set(place-1, transition-numPlaces-1, 1)
 		} else {
 			pn.Set(place-1, transition-numPlaces-1+numTransitions, 1)
@@ -107,7 +282,7 @@ func GenerateRandomPetriNet(numPlaces, numTransitions int) *PetriNet {
 		subGraph = append(subGraph, node)
 	}
 
-	randomPlace := rand.Intn(numPlaces)
+	randomPlace := rng.Intn(numPlaces)
 	pn.Set(randomPlace, 2*numTransitions, 1)
 	pn.InitialMarking = make([]int, numPlaces)
 	for i := 0; i < numPlaces; i++ {
@@ -141,34 +316,32 @@ func filter(nodes []int, condition func(int) bool) []int {
 }
 
 // Prune prunes the Petri net by deleting excess edges and adding missing connections.
-func (pn *PetriNet) Prune() {
-	pn.deleteExcessEdges()
-	pn.addMissingConnections()
+// All random choices are drawn from rng.
+func (pn *PetriNet) Prune(rng *rand.Rand) {
+	pn.deleteExcessEdges(rng)
+	pn.addMissingConnections(rng)
 }
 
 // deleteExcessEdges deletes excess edges from the Petri net.
-func (pn *PetriNet) deleteExcessEdges() {
+func (pn *PetriNet) deleteExcessEdges(rng *rand.Rand) {
 	// Delete excess edges from places
 	for i := 0; i < pn.Places; i++ {
-		rowSum := 0
-		for j := 0; j < 2*pn.Transitions; j++ {
-			rowSum += pn.At(i, j)
-		}
-		if rowSum >= 3 {
+		if pn.placeArcs(i) >= 3 {
 			var edgeIndices []int
 			for j := 0; j < 2*pn.Transitions; j++ {
-				if pn.At(i, j) == 1 {
+				if pn.At(i, j) != 0 {
 					edgeIndices = append(edgeIndices, j)
 				}
 			}
-			rand.Shuffle(len(edgeIndices), func(k, l int) {
+			rng.Shuffle(len(edgeIndices), func(k, l int) {
 				edgeIndices[k], edgeIndices[l] = edgeIndices[l], edgeIndices[k]
 			})
 			for k := 0; k < len(edgeIndices)-2; k++ {
 				// Only remove the edge if it doesn't disconnect the graph
+				weight := pn.At(i, edgeIndices[k])
 				pn.Set(i, edgeIndices[k], 0)
 				if !pn.isConnected() {
-					pn.Set(i, edgeIndices[k], 1)
+					pn.Set(i, edgeIndices[k], weight)
 				}
 			}
 		}
@@ -176,25 +349,22 @@ func (pn *PetriNet) deleteExcessEdges() {
 
 	// Delete excess edges from transitions
 	for j := 0; j < 2*pn.Transitions; j++ {
-		colSum := 0
-		for i := 0; i < pn.Places; i++ {
-			colSum += pn.At(i, j)
-		}
-		if colSum >= 3 {
+		if pn.columnArcs(j) >= 3 {
 			var edgeIndices []int
 			for i := 0; i < pn.Places; i++ {
-				if pn.At(i, j) == 1 {
+				if pn.At(i, j) != 0 {
 					edgeIndices = append(edgeIndices, i)
 				}
 			}
-			rand.Shuffle(len(edgeIndices), func(k, l int) {
+			rng.Shuffle(len(edgeIndices), func(k, l int) {
 				edgeIndices[k], edgeIndices[l] = edgeIndices[l], edgeIndices[k]
 			})
 			for k := 0; k < len(edgeIndices)-2; k++ {
 				// Only remove the edge if it doesn't disconnect the graph
+				weight := pn.At(edgeIndices[k], j)
 				pn.Set(edgeIndices[k], j, 0)
 				if !pn.isConnected() {
-					pn.Set(edgeIndices[k], j, 1)
+					pn.Set(edgeIndices[k], j, weight)
 				}
 			}
 		}
@@ -205,22 +375,14 @@ func (pn *PetriNet) deleteExcessEdges() {
 func (pn *PetriNet) isConnected() bool {
 	// Check for isolated places
 	for i := 0; i < pn.Places; i++ {
-		rowSum := 0
-		for j := 0; j < 2*pn.Transitions; j++ {
-			rowSum += pn.At(i, j)
-		}
-		if rowSum == 0 {
+		if pn.placeArcs(i) == 0 {
 			return false
 		}
 	}
 
 	// Check for isolated transitions
 	for j := 0; j < 2*pn.Transitions; j++ {
-		colSum := 0
-		for i := 0; i < pn.Places; i++ {
-			colSum += pn.At(i, j)
-		}
-		if colSum == 0 {
+		if pn.columnArcs(j) == 0 {
 			return false
 		}
 	}
@@ -228,16 +390,35 @@ func (pn *PetriNet) isConnected() bool {
 	return true
 }
 
+// placeArcs returns the number of arcs connected to place i, regardless of their weights.
+func (pn *PetriNet) placeArcs(i int) int {
+	arcs := 0
+	for j := 0; j < 2*pn.Transitions; j++ {
+		if pn.At(i, j) != 0 {
+			arcs++
+		}
+	}
+	return arcs
+}
+
+// columnArcs returns the number of arcs in column j of the matrix, i.e. the input arcs of
+// transition j or, for j >= Transitions, the output arcs of transition j-Transitions.
+func (pn *PetriNet) columnArcs(j int) int {
+	arcs := 0
+	for i := 0; i < pn.Places; i++ {
+		if pn.At(i, j) != 0 {
+			arcs++
+		}
+	}
+	return arcs
+}
+
 // addMissingConnections adds missing connections to the Petri net.
-func (pn *PetriNet) addMissingConnections() {
+func (pn *PetriNet) addMissingConnections(rng *rand.Rand) {
 	// Ensure each transition has at least one connection
 	for j := 0; j < 2*pn.Transitions; j++ {
-		colSum := 0
-		for i := 0; i < pn.Places; i++ {
-			colSum += pn.At(i, j)
-		}
-		if colSum == 0 {
-			randomRow := rand.Intn(pn.Places)
+		if pn.columnArcs(j) == 0 {
+			randomRow := rng.Intn(pn.Places)
 			pn.Set(randomRow, j, 1)
 		}
 	}
@@ -251,20 +432,60 @@ func (pn *PetriNet) addMissingConnections() {
 			postSum += pn.At(i, j+pn.Transitions)
 		}
 		if preSum == 0 {
-			randomCol := rand.Intn(pn.Transitions)
+			randomCol := rng.Intn(pn.Transitions)
 			pn.Set(i, randomCol, 1)
 		}
 		if postSum == 0 {
-			randomCol := rand.Intn(pn.Transitions) + pn.Transitions
+			randomCol := rng.Intn(pn.Transitions) + pn.Transitions
 			pn.Set(i, randomCol, 1)
 		}
 	}
 }
 
-// AddTokensRandomly adds tokens to random places in the Petri net.
-func (pn *PetriNet) AddTokensRandomly() {
+// ValidateArcWeightDistribution returns an error if distribution is not a known arc weight
+// distribution. The empty string selects ArcWeightUniform.
+func ValidateArcWeightDistribution(distribution string) error {
+	switch distribution {
+	case "", ArcWeightUniform, ArcWeightGeometric:
+		return nil
+	default:
+		return fmt.Errorf("unknown arc weight distribution %q", distribution)
+	}
+}
+
+// AssignArcWeights draws a weight in [1, maxWeight] for every arc of the net from the given
+// distribution. Arcs keep weight one, and rng is left untouched, when maxWeight is at most one.
+// Input arcs of weight w require w tokens to enable their transition and consume them all.
+func (pn *PetriNet) AssignArcWeights(rng *rand.Rand, maxWeight int, distribution string) error {
+	if err := ValidateArcWeightDistribution(distribution); err != nil {
+		return err
+	}
+	if maxWeight <= 1 {
+		return nil
+	}
+	for i := 0; i < pn.Places; i++ {
+		for j := 0; j < 2*pn.Transitions; j++ {
+			if pn.At(i, j) == 0 {
+				continue
+			}
+			weight := 1
+			if distribution == ArcWeightGeometric {
+				for weight < maxWeight && rng.Float64() < 0.5 {
+					weight++
+				}
+			} else {
+				weight += rng.Intn(maxWeight)
+			}
+			pn.Set(i, j, weight)
+		}
+	}
+	return nil
+}
+
+// AddTokensRandomly adds tokens to random places in the Petri net, drawing from rng.
+func (pn *PetriNet) AddTokensRandomly(rng *rand.Rand) {
 	for i := 0; i < pn.Places; i++ {
-		if rand.Intn(10) <= 2 {
+		if rng.Intn(10) <= 2 {
 			pn.Set(i, 2*pn.Transitions, pn.At(i, 2*pn.Transitions)+1)
 		}
 	}
@@ -1,13 +1,15 @@
 package petrinet
 
 import (
+	"math/rand"
+	"slices"
 	"testing"
 )
 
 func TestGenerateRandomPetriNet(t *testing.T) {
 	numPlaces := 5
 	numTransitions := 3
-	pn := GenerateRandomPetriNet(numPlaces, numTransitions)
+	pn := GenerateRandomPetriNet(rand.New(rand.NewSource(1)), numPlaces, numTransitions)
 
 	if pn.Places != numPlaces {
 		t.Errorf("Expected %d places, but got %d", numPlaces, pn.Places)
@@ -51,7 +53,7 @@ func TestPrune(t *testing.T) {
 		}
 	}
 
-	pn.Prune()
+	pn.Prune(rand.New(rand.NewSource(1)))
 
 	// Check that the number of edges has been reduced
 	finalEdgeCount := 0
@@ -72,7 +74,7 @@ func TestPrune(t *testing.T) {
 		0, 0, 0, 0, 0,
 	}
 
-	pn.Prune()
+	pn.Prune(rand.New(rand.NewSource(1)))
 
 	// Check that connections have been added
 	for j := 0; j < 2*pn.Transitions; j++ {
@@ -88,8 +90,9 @@ func TestPrune(t *testing.T) {
 
 func TestAddTokensRandomly(t *testing.T) {
 	pn := NewPetriNet(10, 5)
+	rng := rand.New(rand.NewSource(1))
 
-	pn.AddTokensRandomly()
+	pn.AddTokensRandomly(rng)
 
 	// Check that some tokens have been added
 	tokenSum := 0
@@ -99,7 +102,7 @@ func TestAddTokensRandomly(t *testing.T) {
 	if tokenSum == 0 {
 		// It's possible, but unlikely, that no tokens are added.
 		// Run it again to be sure.
-		pn.AddTokensRandomly()
+		pn.AddTokensRandomly(rng)
 		for _, marking := range pn.InitialMarking {
 			tokenSum += marking
 		}
@@ -108,3 +111,195 @@ func TestAddTokensRandomly(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerationIsDeterministicForSeed(t *testing.T) {
+	generate := func(seed int64) *PetriNet {
+		rng := rand.New(rand.NewSource(seed))
+		pn := GenerateRandomPetriNet(rng, 8, 6)
+		pn.Prune(rng)
+		pn.AddTokensRandomly(rng)
+		return pn
+	}
+
+	first := generate(42)
+	second := generate(42)
+	if !slices.Equal(first.Matrix, second.Matrix) {
+		t.Errorf("Expected identical matrices for the same seed, got %v and %v", first.Matrix, second.Matrix)
+	}
+	if !slices.Equal(first.InitialMarking, second.InitialMarking) {
+		t.Errorf("Expected identical initial markings for the same seed, got %v and %v", first.InitialMarking, second.InitialMarking)
+	}
+}
+
+func TestAssignImmediateTransitions(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	pn := NewPetriNet(4, 6)
+
+	pn.AssignImmediateTransitions(rng, 1, 3, 2)
+
+	numImmediate := 0
+	for tr := 0; tr < pn.Transitions; tr++ {
+		if !pn.IsImmediate(tr) {
+			if pn.Priority(tr) != 0 || pn.Weight(tr) != 1 {
+				t.Errorf("Expected timed transition %d to have priority 0 and weight 1, got %d and %v", tr, pn.Priority(tr), pn.Weight(tr))
+			}
+			continue
+		}
+		numImmediate++
+		if w := pn.Weight(tr); w < 1 || w > 3 {
+			t.Errorf("Expected weight of transition %d in [1, 3], got %v", tr, w)
+		}
+		if p := pn.Priority(tr); p < 1 || p > 2 {
+			t.Errorf("Expected priority of transition %d in [1, 2], got %d", tr, p)
+		}
+	}
+	if numImmediate != pn.Transitions-1 {
+		t.Errorf("Expected exactly one transition to stay timed, got %d immediate of %d", numImmediate, pn.Transitions)
+	}
+
+	clone := pn.Clone()
+	clone.SetImmediate(0, 5, 5)
+	if pn.Weight(0) == 5 {
+		t.Errorf("Expected Clone to copy the immediate transition attributes")
+	}
+}
+
+func TestAssignArcWeights(t *testing.T) {
+	for _, distribution := range []string{ArcWeightUniform, ArcWeightGeometric} {
+		rng := rand.New(rand.NewSource(5))
+		pn := GenerateRandomPetriNet(rng, 6, 5)
+		pn.Prune(rng)
+		structure := append([]int(nil), pn.Matrix...)
+
+		if err := pn.AssignArcWeights(rng, 4, distribution); err != nil {
+			t.Fatalf("Error assigning %s arc weights: %v", distribution, err)
+		}
+		for i := 0; i < pn.Places; i++ {
+			for j := 0; j < 2*pn.Transitions; j++ {
+				before, after := structure[i*pn.stride+j], pn.At(i, j)
+				if (before == 0) != (after == 0) {
+					t.Errorf("%s: expected arc (%d, %d) to keep its existence, got %d -> %d", distribution, i, j, before, after)
+				}
+				if after < 0 || after > 4 {
+					t.Errorf("%s: expected weight of arc (%d, %d) in [1, 4], got %d", distribution, i, j, after)
+				}
+			}
+		}
+	}
+
+	pn := NewPetriNet(2, 2)
+	if err := pn.AssignArcWeights(rand.New(rand.NewSource(1)), 3, "poisson"); err == nil {
+		t.Errorf("Expected an error for an unknown distribution")
+	}
+}
+
+func TestPruneCountsWeightedArcs(t *testing.T) {
+	// Three places feed T0 and are fed by it, every arc with weight 3. The input column holds three
+	// arcs, so one is deleted; no place holds three arcs, so nothing else changes.
+	pn := NewPetriNet(3, 1)
+	pn.Matrix = []int{
+		3, 3, 1,
+		3, 3, 0,
+		3, 3, 0,
+	}
+	pn.deleteExcessEdges(rand.New(rand.NewSource(1)))
+
+	if arcs := pn.columnArcs(0); arcs != 2 {
+		t.Errorf("Expected 2 input arcs left on T0, got %d", arcs)
+	}
+	for i := 0; i < pn.Places; i++ {
+		for j := 0; j < 2*pn.Transitions; j++ {
+			if w := pn.At(i, j); w != 0 && w != 3 {
+				t.Errorf("Expected arc (%d, %d) to keep weight 3, got %d", i, j, w)
+			}
+		}
+	}
+
+	// Every arc is a bridge here, so deleting any of them would disconnect the net and each must be
+	// restored with its original weight.
+	pn = NewPetriNet(1, 3)
+	pn.Matrix = []int{2, 2, 2, 2, 2, 2, 1}
+	pn.deleteExcessEdges(rand.New(rand.NewSource(1)))
+	if !slices.Equal(pn.Matrix, []int{2, 2, 2, 2, 2, 2, 1}) {
+		t.Errorf("Expected the weighted arcs to be restored, got %v", pn.Matrix)
+	}
+}
+
+func TestInhibitorArcs(t *testing.T) {
+	pn := NewPetriNet(3, 2)
+	if pn.HasInhibitorArcs() || pn.Inhibitor(1, 1) != 0 {
+		t.Fatalf("Expected a new net to have no inhibitor arcs")
+	}
+	pn.SetInhibitor(1, 1, 0)
+	if pn.Inhibitors != nil {
+		t.Errorf("Expected removing a missing inhibitor arc not to allocate the matrix")
+	}
+
+	pn.SetInhibitor(2, 1, 3)
+	if !pn.HasInhibitorArcs() || pn.Inhibitor(2, 1) != 3 || pn.Inhibitor(1, 1) != 0 {
+		t.Errorf("Expected a single inhibitor arc from P3 to T2 with threshold 3, got %v", pn.Inhibitors)
+	}
+	clone := pn.Clone()
+	clone.SetInhibitor(2, 1, 0)
+	if pn.Inhibitor(2, 1) != 3 {
+		t.Errorf("Expected Clone to copy the inhibitor arcs")
+	}
+
+	// Every place feeds T1, so random inhibitor arcs may only target T2.
+	pn = NewPetriNet(3, 2)
+	for p := 0; p < pn.Places; p++ {
+		pn.Set(p, 0, 1)
+	}
+	pn.AddInhibitorArcsRandomly(rand.New(rand.NewSource(1)), 1, 2)
+	for p := 0; p < pn.Places; p++ {
+		if pn.Inhibitor(p, 0) != 0 {
+			t.Errorf("Expected no inhibitor arc on an input place, got one from P%d", p+1)
+		}
+		if k := pn.Inhibitor(p, 1); k < 1 || k > 2 {
+			t.Errorf("Expected an inhibitor arc from P%d to T2 with threshold in [1, 2], got %d", p+1, k)
+		}
+	}
+}
+
+func TestDelays(t *testing.T) {
+	pn := NewPetriNet(2, 3)
+	pn.SetDelay(0, Delay{Kind: DelayExponential})
+	if pn.Delays != nil || pn.HasNonExponentialDelays() || !pn.Delay(0).IsExponential() {
+		t.Fatalf("Expected exponential delays not to allocate the delays")
+	}
+
+	pn.SetImmediate(1, 1, 1)
+	pn.SetDelay(1, Delay{Kind: DelayDeterministic})
+	if pn.HasNonExponentialDelays() {
+		t.Errorf("Expected the delay of an immediate transition to be ignored")
+	}
+	pn.SetDelay(2, Delay{Kind: DelayErlang, Stages: 3})
+	if !pn.HasNonExponentialDelays() || pn.Delay(2).Stages != 3 {
+		t.Errorf("Expected an Erlang delay on T3, got %v", pn.Delays)
+	}
+	clone := pn.Clone()
+	clone.SetDelay(2, Delay{})
+	if pn.Delay(2).Kind != DelayErlang {
+		t.Errorf("Expected Clone to copy the delays")
+	}
+
+	// Only the timed transitions T1 and T3 get a delay.
+	pn = NewPetriNet(2, 3)
+	pn.SetImmediate(1, 1, 1)
+	pn.AssignDelays(rand.New(rand.NewSource(1)), 1, []Delay{{Kind: DelayUniform, Spread: 0.5}})
+	if pn.Delay(0).Kind != DelayUniform || pn.Delay(1).Kind != "" || pn.Delay(2).Kind != DelayUniform {
+		t.Errorf("Expected uniform delays on the timed transitions, got %v", pn.Delays)
+	}
+
+	for _, d := range []Delay{
+		{Kind: "weibull"},
+		{Kind: DelayErlang},
+		{Kind: DelayHyperexponential, SCV: 0.5},
+		{Kind: DelayUniform, Spread: 1.5},
+		{Kind: DelayLognormal},
+	} {
+		if err := d.Validate(); err == nil {
+			t.Errorf("Expected an error for delay %+v", d)
+		}
+	}
+}
@@ -0,0 +1,540 @@
+// Package pnml reads and writes Place/Transition nets in the Petri Net Markup Language (ISO/IEC
+// 15909-2), the interchange format of PIPE, TINA, GreatSPN and the Model Checking Contest.
+package pnml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Namespace is the XML namespace of PNML 2009 documents.
+	Namespace = "http://www.pnml.org/version-2009/grammar/pnml"
+	// PTNetType is the net type URI of Place/Transition nets.
+	PTNetType = "http://www.pnml.org/version-2009/grammar/ptnet"
+	// Tool is the tool name of the toolspecific elements carrying stochastic annotations.
+	Tool = "spn-benchmark-ds"
+	// ToolVersion is the version of the toolspecific elements written by Write.
+	ToolVersion = "1.0"
+	// DefaultRate is the firing rate of transitions without a rate annotation.
+	DefaultRate = 1.0
+)
+
+// ErrNoNet is returned when a PNML document does not contain a net.
+var ErrNoNet = errors.New("pnml document contains no net")
+
+// Model is a Petri net together with the PNML identifiers and stochastic annotations of its
+// nodes. Places and transitions are indexed in document order.
+type Model struct {
+	// Name is the name of the net.
+	Name string
+	// Net is the Petri net, with arc inscriptions as matrix weights.
+	Net *petrinet.PetriNet
+	// Rates holds the firing rate of each transition. Transitions without an annotation get
+	// DefaultRate.
+	Rates []float64
+	// PlaceIDs holds the PNML id of each place.
+	PlaceIDs []string
+	// TransitionIDs holds the PNML id of each transition.
+	TransitionIDs []string
+}
+
+// NewModel wraps a Petri net into a model with generated ids "p0", "p1", … and "t0", "t1", ….
+// A nil rates slice gives every transition DefaultRate.
+func NewModel(name string, pn *petrinet.PetriNet, rates []float64) *Model {
+	m := &Model{
+		Name:          name,
+		Net:           pn,
+		Rates:         rates,
+		PlaceIDs:      make([]string, pn.Places),
+		TransitionIDs: make([]string, pn.Transitions),
+	}
+	if m.Rates == nil {
+		m.Rates = make([]float64, pn.Transitions)
+		for t := range m.Rates {
+			m.Rates[t] = DefaultRate
+		}
+	}
+	for p := range m.PlaceIDs {
+		m.PlaceIDs[p] = fmt.Sprintf("p%d", p)
+	}
+	for t := range m.TransitionIDs {
+		m.TransitionIDs[t] = fmt.Sprintf("t%d", t)
+	}
+	return m
+}
+
+// document is the root element of a PNML file.
+type document struct {
+	XMLName xml.Name `xml:"pnml"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Nets    []xmlNet `xml:"net"`
+}
+
+type xmlNet struct {
+	ID    string    `xml:"id,attr"`
+	Type  string    `xml:"type,attr"`
+	Name  *xmlText  `xml:"name"`
+	Pages []xmlPage `xml:"page"`
+	xmlNodes
+}
+
+type xmlPage struct {
+	ID    string    `xml:"id,attr"`
+	Pages []xmlPage `xml:"page"`
+	xmlNodes
+}
+
+// xmlNodes holds the nodes and arcs of a page. PNML puts them in pages, but PIPE and some older
+// tools put them directly in the net.
+type xmlNodes struct {
+	Places         []xmlPlace      `xml:"place"`
+	Transitions    []xmlTransition `xml:"transition"`
+	RefPlaces      []xmlRef        `xml:"referencePlace"`
+	RefTransitions []xmlRef        `xml:"referenceTransition"`
+	Arcs           []xmlArc        `xml:"arc"`
+}
+
+type xmlText struct {
+	Text string `xml:"text"`
+}
+
+// xmlLabel is a numeric label. PNML stores it in a text element; PIPE stores it in a value
+// element, optionally prefixed by a token class as in "Default,2".
+type xmlLabel struct {
+	Text  string `xml:"text,omitempty"`
+	Value string `xml:"value,omitempty"`
+}
+
+// content returns the numeric content of the label.
+func (l *xmlLabel) content() string {
+	if strings.TrimSpace(l.Text) != "" || l.Value == "" {
+		return l.Text
+	}
+	value := l.Value
+	if i := strings.LastIndexByte(value, ','); i >= 0 {
+		value = value[i+1:]
+	}
+	return value
+}
+
+// xmlValue is PIPE's encoding of an attribute value.
+type xmlValue struct {
+	Value string `xml:"value"`
+}
+
+type xmlPlace struct {
+	ID             string    `xml:"id,attr"`
+	Name           *xmlText  `xml:"name"`
+	InitialMarking *xmlLabel `xml:"initialMarking"`
+}
+
+type xmlTransition struct {
+	ID           string            `xml:"id,attr"`
+	Name         *xmlText          `xml:"name"`
+	ToolSpecific []xmlToolSpecific `xml:"toolspecific"`
+	// Rate, Timed and Priority are PIPE's stochastic annotations.
+	Rate     *xmlValue `xml:"rate"`
+	Timed    *xmlValue `xml:"timed"`
+	Priority *xmlValue `xml:"priority"`
+}
+
+// xmlToolSpecific carries the stochastic annotations of a transition.
+type xmlToolSpecific struct {
+	Tool      string `xml:"tool,attr"`
+	Version   string `xml:"version,attr"`
+	Rate      string `xml:"rate,omitempty"`
+	Immediate bool   `xml:"immediate,omitempty"`
+	Weight    string `xml:"weight,omitempty"`
+	Priority  int    `xml:"priority,omitempty"`
+	// Delay, Stages, SCV and Spread describe the delay distribution of a timed transition that is
+	// not exponential.
+	Delay  string `xml:"delay,omitempty"`
+	Stages int    `xml:"stages,omitempty"`
+	SCV    string `xml:"scv,omitempty"`
+	Spread string `xml:"spread,omitempty"`
+}
+
+type xmlRef struct {
+	ID  string `xml:"id,attr"`
+	Ref string `xml:"ref,attr"`
+}
+
+type xmlArc struct {
+	ID          string      `xml:"id,attr"`
+	Source      string      `xml:"source,attr"`
+	Target      string      `xml:"target,attr"`
+	Inscription *xmlLabel   `xml:"inscription"`
+	Type        *xmlArcType `xml:"type"`
+}
+
+// xmlArcType is PIPE's encoding of the arc kind, used here for inhibitor arcs.
+type xmlArcType struct {
+	Value string `xml:"value,attr"`
+}
+
+// ReadFile reads the first net of a PNML file.
+func ReadFile(path string) (*Model, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening pnml file: %w", err)
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+// Read reads the first net of a PNML document. Nodes of all pages are merged and reference nodes
+// are resolved to the nodes they refer to. Parallel arcs are merged by adding their inscriptions.
+func Read(r io.Reader) (*Model, error) {
+	var doc document
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("error decoding pnml: %w", err)
+	}
+	if len(doc.Nets) == 0 {
+		return nil, ErrNoNet
+	}
+	net := doc.Nets[0]
+	if net.Type != "" && net.Type != PTNetType && !strings.HasSuffix(net.Type, "/ptnet") {
+		return nil, fmt.Errorf("unsupported net type %q", net.Type)
+	}
+
+	var places []xmlPlace
+	var transitions []xmlTransition
+	var arcs []xmlArc
+	refs := make(map[string]string)
+	var collect func(nodes *xmlNodes, pages []xmlPage)
+	collect = func(nodes *xmlNodes, pages []xmlPage) {
+		places = append(places, nodes.Places...)
+		transitions = append(transitions, nodes.Transitions...)
+		arcs = append(arcs, nodes.Arcs...)
+		for _, ref := range nodes.RefPlaces {
+			refs[ref.ID] = ref.Ref
+		}
+		for _, ref := range nodes.RefTransitions {
+			refs[ref.ID] = ref.Ref
+		}
+		for i := range pages {
+			collect(&pages[i].xmlNodes, pages[i].Pages)
+		}
+	}
+	collect(&net.xmlNodes, net.Pages)
+	if len(places) == 0 || len(transitions) == 0 {
+		return nil, fmt.Errorf("net %q has no places or no transitions", net.ID)
+	}
+
+	m := &Model{
+		Net:           petrinet.NewPetriNet(len(places), len(transitions)),
+		Rates:         make([]float64, len(transitions)),
+		PlaceIDs:      make([]string, len(places)),
+		TransitionIDs: make([]string, len(transitions)),
+	}
+	if net.Name != nil {
+		m.Name = strings.TrimSpace(net.Name.Text)
+	}
+	pn := m.Net
+
+	placeIndex := make(map[string]int, len(places))
+	for p, place := range places {
+		m.PlaceIDs[p] = place.ID
+		placeIndex[place.ID] = p
+		if place.InitialMarking == nil {
+			continue
+		}
+		tokens, err := parseCount(place.InitialMarking.content())
+		if err != nil {
+			return nil, fmt.Errorf("invalid initial marking of place %q: %w", place.ID, err)
+		}
+		pn.Set(p, 2*pn.Transitions, tokens)
+		pn.InitialMarking[p] = tokens
+	}
+
+	transitionIndex := make(map[string]int, len(transitions))
+	for t, transition := range transitions {
+		m.TransitionIDs[t] = transition.ID
+		transitionIndex[transition.ID] = t
+		if err := readAnnotations(m, t, &transition); err != nil {
+			return nil, fmt.Errorf("invalid annotation of transition %q: %w", transition.ID, err)
+		}
+	}
+
+	resolve := func(id string) string {
+		// Reference nodes may refer to other reference nodes; bound the chain by the number of refs.
+		for i := 0; i <= len(refs); i++ {
+			target, ok := refs[id]
+			if !ok {
+				break
+			}
+			id = target
+		}
+		return id
+	}
+
+	for _, arc := range arcs {
+		weight := 1
+		if arc.Inscription != nil {
+			w, err := parseCount(arc.Inscription.content())
+			if err != nil {
+				return nil, fmt.Errorf("invalid inscription of arc %q: %w", arc.ID, err)
+			}
+			weight = w
+		}
+		kind := "normal"
+		if arc.Type != nil && arc.Type.Value != "" {
+			kind = arc.Type.Value
+		}
+
+		source, target := resolve(arc.Source), resolve(arc.Target)
+		if p, ok := placeIndex[source]; ok {
+			t, ok := transitionIndex[target]
+			if !ok {
+				return nil, fmt.Errorf("arc %q does not connect a place to a transition", arc.ID)
+			}
+			switch kind {
+			case "normal":
+				pn.Set(p, t, pn.At(p, t)+weight)
+			case "inhibitor":
+				pn.SetInhibitor(p, t, weight)
+			default:
+				return nil, fmt.Errorf("unsupported arc type %q of arc %q", kind, arc.ID)
+			}
+			continue
+		}
+		t, ok := transitionIndex[source]
+		p, ok2 := placeIndex[target]
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("arc %q does not connect a place and a transition", arc.ID)
+		}
+		if kind != "normal" {
+			return nil, fmt.Errorf("unsupported arc type %q of arc %q from a transition", kind, arc.ID)
+		}
+		pn.Set(p, pn.Transitions+t, pn.At(p, pn.Transitions+t)+weight)
+	}
+	return m, nil
+}
+
+// readAnnotations reads the rate and delay distribution of timed transitions, and the weight and
+// priority of immediate transitions, from the toolspecific element written by Write or, failing
+// that, from PIPE's annotations.
+func readAnnotations(m *Model, t int, transition *xmlTransition) error {
+	m.Rates[t] = DefaultRate
+	for _, ts := range transition.ToolSpecific {
+		if ts.Tool != Tool {
+			continue
+		}
+		if ts.Immediate {
+			weight := 1.0
+			if ts.Weight != "" {
+				w, err := parsePositive(ts.Weight)
+				if err != nil {
+					return fmt.Errorf("invalid weight: %w", err)
+				}
+				weight = w
+			}
+			m.Net.SetImmediate(t, weight, ts.Priority)
+			return nil
+		}
+		if ts.Rate != "" {
+			rate, err := parsePositive(ts.Rate)
+			if err != nil {
+				return fmt.Errorf("invalid rate: %w", err)
+			}
+			m.Rates[t] = rate
+		}
+		if ts.Delay != "" {
+			delay, err := readDelay(&ts)
+			if err != nil {
+				return fmt.Errorf("invalid delay: %w", err)
+			}
+			m.Net.SetDelay(t, delay)
+		}
+		return nil
+	}
+
+	// In PIPE, the rate of an untimed transition is its weight.
+	var value float64
+	if transition.Rate != nil {
+		v, err := parsePositive(transition.Rate.Value)
+		if err != nil {
+			return fmt.Errorf("invalid rate: %w", err)
+		}
+		value = v
+	}
+	if transition.Timed != nil && strings.TrimSpace(transition.Timed.Value) == "false" {
+		weight, priority := 1.0, 1
+		if value > 0 {
+			weight = value
+		}
+		if transition.Priority != nil {
+			p, err := strconv.Atoi(strings.TrimSpace(transition.Priority.Value))
+			if err != nil {
+				return fmt.Errorf("invalid priority: %w", err)
+			}
+			priority = p
+		}
+		m.Net.SetImmediate(t, weight, priority)
+		return nil
+	}
+	if value > 0 {
+		m.Rates[t] = value
+	}
+	return nil
+}
+
+// readDelay reads the delay distribution of a toolspecific element.
+func readDelay(ts *xmlToolSpecific) (petrinet.Delay, error) {
+	delay := petrinet.Delay{Kind: ts.Delay, Stages: ts.Stages}
+	var err error
+	if ts.SCV != "" {
+		if delay.SCV, err = strconv.ParseFloat(strings.TrimSpace(ts.SCV), 64); err != nil {
+			return delay, err
+		}
+	}
+	if ts.Spread != "" {
+		if delay.Spread, err = strconv.ParseFloat(strings.TrimSpace(ts.Spread), 64); err != nil {
+			return delay, err
+		}
+	}
+	return delay, delay.Validate()
+}
+
+// WriteFile writes a model to a PNML file.
+func WriteFile(path string, m *Model) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating pnml file: %w", err)
+	}
+	if err := Write(file, m); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Write writes a model as a PNML document with a single page. Rates and delay distributions, and
+// the weights and priorities of immediate transitions, are written to toolspecific elements. Inhibitor arcs use
+// PIPE's arc type attribute, since P/T PNML has no inhibitor arcs.
+func Write(w io.Writer, m *Model) error {
+	pn := m.Net
+	page := xmlPage{ID: "page0"}
+
+	for p := 0; p < pn.Places; p++ {
+		place := xmlPlace{ID: m.PlaceIDs[p], Name: &xmlText{Text: m.PlaceIDs[p]}}
+		if tokens := pn.InitialMarking[p]; tokens > 0 {
+			place.InitialMarking = &xmlLabel{Text: strconv.Itoa(tokens)}
+		}
+		page.Places = append(page.Places, place)
+	}
+
+	for t := 0; t < pn.Transitions; t++ {
+		ts := xmlToolSpecific{Tool: Tool, Version: ToolVersion}
+		if pn.IsImmediate(t) {
+			ts.Immediate = true
+			ts.Weight = formatFloat(pn.Weight(t))
+			ts.Priority = pn.Priority(t)
+		} else {
+			rate := DefaultRate
+			if m.Rates != nil {
+				rate = m.Rates[t]
+			}
+			ts.Rate = formatFloat(rate)
+			if delay := pn.Delay(t); !delay.IsExponential() {
+				ts.Delay = delay.Kind
+				switch delay.Kind {
+				case petrinet.DelayErlang:
+					ts.Stages = delay.Stages
+				case petrinet.DelayHyperexponential, petrinet.DelayLognormal:
+					ts.SCV = formatFloat(delay.SCV)
+				case petrinet.DelayUniform:
+					ts.Spread = formatFloat(delay.Spread)
+				}
+			}
+		}
+		page.Transitions = append(page.Transitions, xmlTransition{
+			ID:           m.TransitionIDs[t],
+			Name:         &xmlText{Text: m.TransitionIDs[t]},
+			ToolSpecific: []xmlToolSpecific{ts},
+		})
+	}
+
+	addArc := func(source, target string, weight int, kind *xmlArcType) {
+		arc := xmlArc{
+			ID:     fmt.Sprintf("a%d", len(page.Arcs)),
+			Source: source,
+			Target: target,
+			Type:   kind,
+		}
+		if weight != 1 {
+			arc.Inscription = &xmlLabel{Text: strconv.Itoa(weight)}
+		}
+		page.Arcs = append(page.Arcs, arc)
+	}
+	for p := 0; p < pn.Places; p++ {
+		for t := 0; t < pn.Transitions; t++ {
+			if weight := pn.At(p, t); weight > 0 {
+				addArc(m.PlaceIDs[p], m.TransitionIDs[t], weight, nil)
+			}
+			if weight := pn.At(p, pn.Transitions+t); weight > 0 {
+				addArc(m.TransitionIDs[t], m.PlaceIDs[p], weight, nil)
+			}
+			if threshold := pn.Inhibitor(p, t); threshold > 0 {
+				addArc(m.PlaceIDs[p], m.TransitionIDs[t], threshold, &xmlArcType{Value: "inhibitor"})
+			}
+		}
+	}
+
+	doc := document{
+		Xmlns: Namespace,
+		Nets:  []xmlNet{{ID: "net0", Type: PTNetType, Pages: []xmlPage{page}}},
+	}
+	if m.Name != "" {
+		doc.Nets[0].Name = &xmlText{Text: m.Name}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("error writing pnml: %w", err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("error encoding pnml: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("error writing pnml: %w", err)
+	}
+	return nil
+}
+
+// parseCount parses a non-negative token count or arc inscription.
+func parseCount(text string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("negative value %d", n)
+	}
+	return n, nil
+}
+
+// parsePositive parses a positive real number.
+func parsePositive(text string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil {
+		return 0, err
+	}
+	if !(v > 0) {
+		return 0, fmt.Errorf("non-positive value %g", v)
+	}
+	return v, nil
+}
+
+// formatFloat formats a float with the fewest digits that read back to the same value.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
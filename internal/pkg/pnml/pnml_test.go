@@ -0,0 +1,167 @@
+package pnml
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"slices"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+	"strings"
+	"testing"
+)
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	pn := petrinet.GenerateRandomPetriNet(rng, 6, 4)
+	pn.Prune(rng)
+	if err := pn.AssignArcWeights(rng, 3, petrinet.ArcWeightUniform); err != nil {
+		t.Fatalf("Error assigning arc weights: %v", err)
+	}
+	pn.AddTokensRandomly(rng)
+	pn.SetImmediate(1, 2.5, 3)
+	pn.AddInhibitorArcsRandomly(rng, 0.3, 2)
+	pn.SetDelay(0, petrinet.Delay{Kind: petrinet.DelayErlang, Stages: 3})
+	pn.SetDelay(2, petrinet.Delay{Kind: petrinet.DelayUniform, Spread: 0.4})
+	pn.SetDelay(3, petrinet.Delay{Kind: petrinet.DelayHyperexponential, SCV: 2.5})
+	rates := []float64{0.5, 1, 3, 12.25}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, NewModel("sample", pn, rates)); err != nil {
+		t.Fatalf("Error writing pnml: %v", err)
+	}
+	m, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Error reading pnml: %v", err)
+	}
+
+	if m.Name != "sample" {
+		t.Errorf("Expected name %q, got %q", "sample", m.Name)
+	}
+	if !slices.Equal(m.Net.Matrix, pn.Matrix) {
+		t.Errorf("Expected matrix %v, got %v", pn.Matrix, m.Net.Matrix)
+	}
+	if !slices.Equal(m.Net.InitialMarking, pn.InitialMarking) {
+		t.Errorf("Expected initial marking %v, got %v", pn.InitialMarking, m.Net.InitialMarking)
+	}
+	if !slices.Equal(m.Net.Inhibitors, pn.Inhibitors) {
+		t.Errorf("Expected inhibitor arcs %v, got %v", pn.Inhibitors, m.Net.Inhibitors)
+	}
+	if !m.Net.IsImmediate(1) || m.Net.Weight(1) != 2.5 || m.Net.Priority(1) != 3 {
+		t.Errorf("Expected T1 to be immediate with weight 2.5 and priority 3")
+	}
+	if !slices.Equal(m.Net.Delays, pn.Delays) {
+		t.Errorf("Expected delays %v, got %v", pn.Delays, m.Net.Delays)
+	}
+	for tr, rate := range rates {
+		if tr != 1 && m.Rates[tr] != rate {
+			t.Errorf("Expected rate %v for transition %d, got %v", rate, tr, m.Rates[tr])
+		}
+	}
+	if !slices.Equal(m.PlaceIDs, []string{"p0", "p1", "p2", "p3", "p4", "p5"}) {
+		t.Errorf("Unexpected place ids %v", m.PlaceIDs)
+	}
+}
+
+func TestReadPagesAndReferenceNodes(t *testing.T) {
+	// A Model Checking Contest style net split over two pages, where the second page refers to
+	// the place of the first one.
+	doc := `<?xml version="1.0"?>
+<pnml xmlns="http://www.pnml.org/version-2009/grammar/pnml">
+  <net id="mcc" type="http://www.pnml.org/version-2009/grammar/ptnet">
+    <name><text>Cycle</text></name>
+    <page id="top">
+      <place id="ready"><initialMarking><text>2</text></initialMarking></place>
+      <transition id="start"/>
+      <arc id="a1" source="ready" target="start"><inscription><text>2</text></inscription></arc>
+      <page id="nested">
+        <place id="busy"/>
+        <referencePlace id="ready-ref" ref="ready"/>
+        <transition id="finish"/>
+        <arc id="a2" source="start" target="busy"/>
+        <arc id="a3" source="busy" target="finish"/>
+        <arc id="a4" source="finish" target="ready-ref"><inscription><text>2</text></inscription></arc>
+      </page>
+    </page>
+  </net>
+</pnml>`
+
+	m, err := Read(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("Error reading pnml: %v", err)
+	}
+	if m.Name != "Cycle" || m.Net.Places != 2 || m.Net.Transitions != 2 {
+		t.Fatalf("Expected net Cycle with 2 places and 2 transitions, got %q with %d and %d", m.Name, m.Net.Places, m.Net.Transitions)
+	}
+	expected := []int{
+		2, 0, 0, 2, 2,
+		0, 1, 1, 0, 0,
+	}
+	if !slices.Equal(m.Net.Matrix, expected) {
+		t.Errorf("Expected matrix %v, got %v", expected, m.Net.Matrix)
+	}
+	if !slices.Equal(m.Rates, []float64{DefaultRate, DefaultRate}) {
+		t.Errorf("Expected default rates, got %v", m.Rates)
+	}
+}
+
+func TestReadPIPEAnnotations(t *testing.T) {
+	doc := `<?xml version="1.0"?>
+<pnml>
+  <net id="pipe" type="P/T net">
+    <place id="P0"><initialMarking><value>Default,1</value></initialMarking></place>
+    <place id="P1"/>
+    <transition id="T0"><rate><value>4.5</value></rate><timed><value>true</value></timed></transition>
+    <transition id="T1"><rate><value>2</value></rate><timed><value>false</value></timed><priority><value>2</value></priority></transition>
+    <arc id="a0" source="P0" target="T0"><inscription><value>Default,1</value></inscription></arc>
+    <arc id="a1" source="T0" target="P1"/>
+    <arc id="a2" source="P1" target="T1"/>
+    <arc id="a3" source="T1" target="P0"/>
+    <arc id="a4" source="P1" target="T0"><inscription><value>Default,1</value></inscription><type value="inhibitor"/></arc>
+  </net>
+</pnml>`
+
+	_, err := Read(strings.NewReader(doc))
+	if err == nil {
+		t.Fatalf("Expected an error for an unknown net type")
+	}
+	m, err := Read(strings.NewReader(strings.Replace(doc, ` type="P/T net"`, "", 1)))
+	if err != nil {
+		t.Fatalf("Error reading pnml: %v", err)
+	}
+	if m.Rates[0] != 4.5 {
+		t.Errorf("Expected rate 4.5 for T0, got %v", m.Rates[0])
+	}
+	if !m.Net.IsImmediate(1) || m.Net.Weight(1) != 2 || m.Net.Priority(1) != 2 {
+		t.Errorf("Expected T1 to be immediate with weight 2 and priority 2")
+	}
+	if m.Net.InitialMarking[0] != 1 {
+		t.Errorf("Expected one token in P0, got %d", m.Net.InitialMarking[0])
+	}
+	if m.Net.Inhibitor(1, 0) != 1 {
+		t.Errorf("Expected an inhibitor arc from P1 to T0")
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := map[string]string{
+		"no net":       `<pnml></pnml>`,
+		"no nodes":     `<pnml><net id="n"><page id="p"/></net></pnml>`,
+		"dangling arc": `<pnml><net id="n"><place id="p"/><transition id="t"/><arc id="a" source="p" target="q"/></net></pnml>`,
+		"place to place": `<pnml><net id="n"><place id="p"/><place id="q"/><transition id="t"/>` +
+			`<arc id="a" source="p" target="q"/></net></pnml>`,
+		"bad marking": `<pnml><net id="n"><place id="p"><initialMarking><text>x</text></initialMarking></place>` +
+			`<transition id="t"/></net></pnml>`,
+		"reset arc": `<pnml><net id="n"><place id="p"/><transition id="t"/>` +
+			`<arc id="a" source="p" target="t"><type value="reset"/></arc></net></pnml>`,
+		"bad delay": `<pnml><net id="n"><place id="p"/><transition id="t">` +
+			`<toolspecific tool="spn-benchmark-ds" version="1.0"><delay>erlang</delay></toolspecific></transition></net></pnml>`,
+	}
+	for name, doc := range tests {
+		if _, err := Read(strings.NewReader(doc)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := Read(strings.NewReader(`<pnml></pnml>`)); !errors.Is(err, ErrNoNet) {
+		t.Errorf("Expected ErrNoNet, got %v", err)
+	}
+}
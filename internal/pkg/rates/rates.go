@@ -0,0 +1,159 @@
+// Package rates draws the firing rates (lambda values) of the transitions of stochastic Petri
+// nets from configurable distributions.
+package rates
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Rate distributions accepted by Sampler.Distribution.
+const (
+	// IntUniform draws integers uniformly from [Min, Max]. It is the default, and draws from rng
+	// exactly like the generator always has, so existing seeds reproduce existing datasets.
+	IntUniform = "int_uniform"
+	// Uniform draws reals uniformly from [Min, Max).
+	Uniform = "uniform"
+	// LogUniform draws reals whose logarithm is uniform on [log Min, log Max), so every order of
+	// magnitude between Min and Max is equally likely.
+	LogUniform = "log_uniform"
+	// Gamma draws from the gamma distribution with shape Shape and scale Scale.
+	Gamma = "gamma"
+	// Choice draws uniformly from Values.
+	Choice = "choice"
+)
+
+// Range is an interval of rates.
+type Range struct {
+	// Min is the lower bound of the range.
+	Min float64
+	// Max is the upper bound of the range.
+	Max float64
+}
+
+// Sampler describes the distribution the firing rates are drawn from. The zero value of
+// Distribution selects IntUniform.
+type Sampler struct {
+	// Distribution is the rate distribution.
+	Distribution string
+	// Min is the lower bound of IntUniform, Uniform and LogUniform.
+	Min float64
+	// Max is the upper bound of IntUniform, Uniform and LogUniform.
+	Max float64
+	// Ranges overrides [Min, Max] for the first len(Ranges) transitions, by transition index, for
+	// IntUniform, Uniform and LogUniform.
+	Ranges []Range
+	// Shape is the shape parameter of Gamma.
+	Shape float64
+	// Scale is the scale parameter of Gamma; the mean rate is Shape·Scale.
+	Scale float64
+	// Values holds the rates Choice draws from.
+	Values []float64
+}
+
+// Validate checks the distribution and its parameters.
+func (s Sampler) Validate() error {
+	switch s.Distribution {
+	case "", IntUniform, Uniform, LogUniform:
+		if err := s.validateRange(Range{s.Min, s.Max}); err != nil {
+			return err
+		}
+		for t, r := range s.Ranges {
+			if err := s.validateRange(r); err != nil {
+				return fmt.Errorf("range of transition %d: %w", t, err)
+			}
+		}
+		return nil
+	case Gamma:
+		if s.Shape <= 0 || s.Scale <= 0 {
+			return fmt.Errorf("gamma rates need a positive shape and scale, got %g and %g", s.Shape, s.Scale)
+		}
+	case Choice:
+		if len(s.Values) == 0 {
+			return fmt.Errorf("choice of rates needs at least one value")
+		}
+		for _, v := range s.Values {
+			if v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+				return fmt.Errorf("rates must be finite and not negative, got %g", v)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown rate distribution %q", s.Distribution)
+	}
+	if len(s.Ranges) > 0 {
+		return fmt.Errorf("per-transition ranges are not supported by the %s rate distribution", s.Distribution)
+	}
+	return nil
+}
+
+// validateRange checks a range of the range-based distributions.
+func (s Sampler) validateRange(r Range) error {
+	if r.Min < 0 || r.Min > r.Max || math.IsInf(r.Max, 0) || math.IsNaN(r.Min) || math.IsNaN(r.Max) {
+		return fmt.Errorf("rate range [%g, %g] must be finite, not negative and not empty", r.Min, r.Max)
+	}
+	switch s.Distribution {
+	case "", IntUniform:
+		if r.Min != math.Trunc(r.Min) || r.Max != math.Trunc(r.Max) {
+			return fmt.Errorf("integer rate range [%g, %g] must have integer bounds", r.Min, r.Max)
+		}
+	case LogUniform:
+		if r.Min <= 0 {
+			return fmt.Errorf("log-uniform rate range [%g, %g] must be positive", r.Min, r.Max)
+		}
+	}
+	return nil
+}
+
+// Sample draws the rates of a net with the given number of transitions. The sampler must be valid.
+func (s Sampler) Sample(rng *rand.Rand, transitions int) []float64 {
+	lambdaValues := make([]float64, transitions)
+	for t := range lambdaValues {
+		lambdaValues[t] = s.sample(rng, t)
+	}
+	return lambdaValues
+}
+
+// sample draws the rate of transition t.
+func (s Sampler) sample(rng *rand.Rand, t int) float64 {
+	r := Range{s.Min, s.Max}
+	if t < len(s.Ranges) {
+		r = s.Ranges[t]
+	}
+	switch s.Distribution {
+	case Uniform:
+		return r.Min + (r.Max-r.Min)*rng.Float64()
+	case LogUniform:
+		return math.Exp(math.Log(r.Min) + (math.Log(r.Max)-math.Log(r.Min))*rng.Float64())
+	case Gamma:
+		return s.Scale * sampleGamma(rng, s.Shape)
+	case Choice:
+		return s.Values[rng.Intn(len(s.Values))]
+	default:
+		lo, hi := int(r.Min), int(r.Max)
+		return float64(lo + rng.Intn(hi-lo+1))
+	}
+}
+
+// sampleGamma draws from the gamma distribution with the given shape and unit scale with the
+// method of Marsaglia and Tsang. Shapes below one are boosted by one and corrected with a uniform
+// power.
+func sampleGamma(rng *rand.Rand, shape float64) float64 {
+	if shape < 1 {
+		return sampleGamma(rng, shape+1) * math.Pow(rng.Float64(), 1/shape)
+	}
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rng.Float64()
+		if u < 1-0.0331*x*x*x*x || math.Log(u) < x*x/2+d*(1-v+math.Log(v)) {
+			return d * v
+		}
+	}
+}
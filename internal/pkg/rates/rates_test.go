@@ -0,0 +1,106 @@
+package rates
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestIntUniformMatchesLegacyStream(t *testing.T) {
+	// The default distribution must draw exactly like min + rng.Intn(max-min+1).
+	got := Sampler{Min: 1, Max: 10}.Sample(rand.New(rand.NewSource(42)), 50)
+	rng := rand.New(rand.NewSource(42))
+	for i, v := range got {
+		if expected := float64(1 + rng.Intn(10)); v != expected {
+			t.Fatalf("Expected rate %d to be %g, but got %g", i, expected, v)
+		}
+	}
+}
+
+func TestSampleDistributions(t *testing.T) {
+	const n = 100000
+	cases := []struct {
+		name     string
+		sampler  Sampler
+		min, max float64
+		mean     float64
+	}{
+		{"uniform", Sampler{Distribution: Uniform, Min: 0.5, Max: 2.5}, 0.5, 2.5, 1.5},
+		// E[X] = (Max-Min)/ln(Max/Min) for a log-uniform X.
+		{"log_uniform", Sampler{Distribution: LogUniform, Min: 0.01, Max: 100}, 0.01, 100, 99.99 / math.Log(1e4)},
+		{"gamma", Sampler{Distribution: Gamma, Shape: 2, Scale: 1.5}, 0, math.Inf(1), 3},
+		{"gamma_small_shape", Sampler{Distribution: Gamma, Shape: 0.5, Scale: 2}, 0, math.Inf(1), 1},
+		{"choice", Sampler{Distribution: Choice, Values: []float64{1, 10, 100}}, 1, 100, 37},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			values := c.sampler.Sample(rand.New(rand.NewSource(1)), n)
+			sum := 0.0
+			for _, v := range values {
+				if v < c.min || v > c.max {
+					t.Fatalf("Expected rates in [%g, %g], but got %g", c.min, c.max, v)
+				}
+				sum += v
+			}
+			if mean := sum / n; math.Abs(mean-c.mean) > 0.02*c.mean {
+				t.Errorf("Expected mean %g, but got %g", c.mean, mean)
+			}
+		})
+	}
+
+	// A log-uniform rate is as likely to fall in [0.01, 0.1) as in [10, 100).
+	values := Sampler{Distribution: LogUniform, Min: 0.01, Max: 100}.Sample(rand.New(rand.NewSource(2)), n)
+	low, high := 0, 0
+	for _, v := range values {
+		if v < 0.1 {
+			low++
+		} else if v >= 10 {
+			high++
+		}
+	}
+	if math.Abs(float64(low-high)) > 0.02*n {
+		t.Errorf("Expected as many rates below 0.1 as above 10, but got %d and %d", low, high)
+	}
+}
+
+func TestSamplePerTransitionRanges(t *testing.T) {
+	s := Sampler{Distribution: Uniform, Min: 1, Max: 2, Ranges: []Range{{100, 200}}}
+	for i := 0; i < 100; i++ {
+		values := s.Sample(rand.New(rand.NewSource(int64(i))), 2)
+		if values[0] < 100 || values[0] >= 200 || values[1] < 1 || values[1] >= 2 {
+			t.Fatalf("Expected the first rate in [100, 200) and the second in [1, 2), but got %v", values)
+		}
+	}
+}
+
+func TestSamplerValidate(t *testing.T) {
+	valid := []Sampler{
+		{},
+		{Min: 1, Max: 10},
+		{Distribution: Uniform, Min: 0.5, Max: 0.5},
+		{Distribution: LogUniform, Min: 1e-3, Max: 1e3, Ranges: []Range{{1, 2}}},
+		{Distribution: Gamma, Shape: 1, Scale: 1},
+		{Distribution: Choice, Values: []float64{1, 2}},
+	}
+	for _, s := range valid {
+		if err := s.Validate(); err != nil {
+			t.Errorf("Expected %+v to be valid, but got %v", s, err)
+		}
+	}
+	invalid := []Sampler{
+		{Distribution: "normal"},
+		{Min: 10, Max: 1},
+		{Min: 0.5, Max: 10},
+		{Distribution: LogUniform, Min: 0, Max: 10},
+		{Distribution: Uniform, Min: 1, Max: 2, Ranges: []Range{{-1, 2}}},
+		{Distribution: Gamma, Shape: 0, Scale: 1},
+		{Distribution: Gamma, Shape: 1, Scale: 1, Ranges: []Range{{1, 2}}},
+		{Distribution: Choice},
+		{Distribution: Choice, Values: []float64{-1}},
+	}
+	for _, s := range invalid {
+		if err := s.Validate(); err == nil {
+			t.Errorf("Expected an error for %+v", s)
+		}
+	}
+}
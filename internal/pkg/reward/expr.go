@@ -0,0 +1,386 @@
+package reward
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expr is a compiled expression over a marking.
+//
+// Expressions are built from numbers, place references p0, p1, … (the number of tokens in that
+// place), the arithmetic operators + - * /, the comparisons < <= > >= == !=, the logical
+// operators && || ! and parentheses, and the functions min(a, b, …), max(a, b, …), abs(x) and
+// if(condition, then, else). Comparisons and logical operators yield 1 for true and 0 for false,
+// and any non-zero value is true. For example "p0 * (p1 > 0)" or "if(p2 >= 3, 1, 0)".
+type Expr struct {
+	src      string
+	root     node
+	maxPlace int
+}
+
+// node is a node of the syntax tree of an expression.
+type node interface {
+	eval(marking []int) float64
+}
+
+// Parse compiles an expression.
+func Parse(src string) (*Expr, error) {
+	p := &parser{src: src, maxPlace: -1}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %q", p.tok.text)
+	}
+	return &Expr{src: src, root: root, maxPlace: p.maxPlace}, nil
+}
+
+// Eval evaluates the expression in a marking. The marking must cover every place the expression
+// refers to.
+func (e *Expr) Eval(marking []int) float64 {
+	return e.root.eval(marking)
+}
+
+// MaxPlace returns the largest place index the expression refers to, or -1 if it refers to none.
+func (e *Expr) MaxPlace() int {
+	return e.maxPlace
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.src
+}
+
+type (
+	constant float64
+	place    int
+	unary    struct {
+		op      string
+		operand node
+	}
+	binary struct {
+		op          string
+		left, right node
+	}
+	call struct {
+		name string
+		args []node
+	}
+)
+
+func (c constant) eval([]int) float64 { return float64(c) }
+
+func (p place) eval(marking []int) float64 { return float64(marking[p]) }
+
+func (u *unary) eval(marking []int) float64 {
+	v := u.operand.eval(marking)
+	if u.op == "-" {
+		return -v
+	}
+	return truth(v == 0)
+}
+
+func (b *binary) eval(marking []int) float64 {
+	l := b.left.eval(marking)
+	// The logical operators short-circuit like their Go counterparts.
+	switch b.op {
+	case "&&":
+		return truth(l != 0 && b.right.eval(marking) != 0)
+	case "||":
+		return truth(l != 0 || b.right.eval(marking) != 0)
+	}
+	r := b.right.eval(marking)
+	switch b.op {
+	case "+":
+		return l + r
+	case "-":
+		return l - r
+	case "*":
+		return l * r
+	case "/":
+		return l / r
+	case "<":
+		return truth(l < r)
+	case "<=":
+		return truth(l <= r)
+	case ">":
+		return truth(l > r)
+	case ">=":
+		return truth(l >= r)
+	case "==":
+		return truth(l == r)
+	default:
+		return truth(l != r)
+	}
+}
+
+func (c *call) eval(marking []int) float64 {
+	switch c.name {
+	case "if":
+		if c.args[0].eval(marking) != 0 {
+			return c.args[1].eval(marking)
+		}
+		return c.args[2].eval(marking)
+	case "abs":
+		return math.Abs(c.args[0].eval(marking))
+	}
+	v := c.args[0].eval(marking)
+	for _, arg := range c.args[1:] {
+		if c.name == "min" {
+			v = math.Min(v, arg.eval(marking))
+		} else {
+			v = math.Max(v, arg.eval(marking))
+		}
+	}
+	return v
+}
+
+// truth converts a condition to 1 or 0.
+func truth(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// arity holds the number of arguments of each function; -1 means one or more.
+var arity = map[string]int{"min": -1, "max": -1, "abs": 1, "if": 3}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// parser is a recursive-descent parser with one token of lookahead.
+type parser struct {
+	src      string
+	pos      int
+	tok      token
+	maxPlace int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("expression %q at offset %d: %s", p.src, p.tok.pos, fmt.Sprintf(format, args...))
+}
+
+// next reads the next token.
+func (p *parser) next() error {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos == len(p.src) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return nil
+	}
+	c := p.src[p.pos]
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.src) && (isDigit(p.src[p.pos]) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		// Accept an exponent such as 1e-3.
+		if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+			p.pos++
+			if p.pos < len(p.src) && (p.src[p.pos] == '+' || p.src[p.pos] == '-') {
+				p.pos++
+			}
+			for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+				p.pos++
+			}
+		}
+		p.tok = token{kind: tokNumber, text: p.src[start:p.pos], pos: start}
+	case c == '_' || unicode.IsLetter(rune(c)):
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' || isDigit(p.src[p.pos]) || unicode.IsLetter(rune(p.src[p.pos]))) {
+			p.pos++
+		}
+		p.tok = token{kind: tokIdent, text: p.src[start:p.pos], pos: start}
+	default:
+		for _, op := range []string{"<=", ">=", "==", "!=", "&&", "||", "+", "-", "*", "/", "<", ">", "!", "(", ")", ","} {
+			if strings.HasPrefix(p.src[p.pos:], op) {
+				p.pos += len(op)
+				p.tok = token{kind: tokOp, text: op, pos: start}
+				return nil
+			}
+		}
+		p.tok = token{pos: start}
+		return p.errorf("unexpected character %q", c)
+	}
+	return nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// accept consumes the current token if it is the operator op.
+func (p *parser) accept(op string) (bool, error) {
+	if p.tok.kind != tokOp || p.tok.text != op {
+		return false, nil
+	}
+	return true, p.next()
+}
+
+// parseBinary parses a left-associative chain of operands joined by one of ops.
+func (p *parser) parseBinary(ops []string, operand func() (node, error)) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		matched := ""
+		for _, op := range ops {
+			if p.tok.kind == tokOp && p.tok.text == op {
+				matched = op
+				break
+			}
+		}
+		if matched == "" {
+			return left, nil
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: matched, left: left, right: right}
+	}
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseBinary([]string{"||"}, p.parseAnd)
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseBinary([]string{"&&"}, p.parseComparison)
+}
+
+func (p *parser) parseComparison() (node, error) {
+	return p.parseBinary([]string{"<=", ">=", "==", "!=", "<", ">"}, p.parseSum)
+}
+
+func (p *parser) parseSum() (node, error) {
+	return p.parseBinary([]string{"+", "-"}, p.parseProduct)
+}
+
+func (p *parser) parseProduct() (node, error) {
+	return p.parseBinary([]string{"*", "/"}, p.parseUnary)
+}
+
+func (p *parser) parseUnary() (node, error) {
+	for _, op := range []string{"-", "!"} {
+		ok, err := p.accept(op)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			operand, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			return &unary{op: op, operand: operand}, nil
+		}
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokNumber:
+		v, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", tok.text)
+		}
+		return constant(v), p.next()
+	case tokIdent:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if n, ok := arity[tok.text]; ok {
+			return p.parseCall(tok.text, n)
+		}
+		if len(tok.text) > 1 && (tok.text[0] == 'p' || tok.text[0] == 'P') {
+			if i, err := strconv.Atoi(tok.text[1:]); err == nil && i >= 0 {
+				p.maxPlace = max(p.maxPlace, i)
+				return place(i), nil
+			}
+		}
+		p.tok = tok
+		return nil, p.errorf("unknown identifier %q", tok.text)
+	case tokOp:
+		if tok.text == "(" {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if ok, err := p.accept(")"); err != nil || !ok {
+				if err == nil {
+					err = p.errorf("expected \")\"")
+				}
+				return nil, err
+			}
+			return inner, nil
+		}
+		return nil, p.errorf("unexpected %q", tok.text)
+	default:
+		return nil, p.errorf("unexpected end of expression")
+	}
+}
+
+// parseCall parses the parenthesized arguments of a function call.
+func (p *parser) parseCall(name string, n int) (node, error) {
+	if ok, err := p.accept("("); err != nil || !ok {
+		if err == nil {
+			err = p.errorf("expected \"(\" after %s", name)
+		}
+		return nil, err
+	}
+	c := &call{name: name}
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		c.args = append(c.args, arg)
+		ok, err := p.accept(",")
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+	}
+	if ok, err := p.accept(")"); err != nil || !ok {
+		if err == nil {
+			err = p.errorf("expected \")\" after the arguments of %s", name)
+		}
+		return nil, err
+	}
+	if n >= 0 && len(c.args) != n {
+		return nil, fmt.Errorf("expression %q: %s takes %d arguments, got %d", p.src, name, n, len(c.args))
+	}
+	return c, nil
+}
@@ -0,0 +1,114 @@
+package reward
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAndEval(t *testing.T) {
+	marking := []int{3, 0, 2}
+	tests := []struct {
+		src      string
+		expected float64
+	}{
+		{"p0", 3},
+		{"P2 + 1.5", 3.5},
+		{"p0 * (p1 > 0)", 0},
+		{"p0 * (p2 > 0)", 3},
+		{"-p0 + 2 * p2 - 1", 0},
+		{"p0 / p2", 1.5},
+		{"1 + 2 * 3 - 4 / 2", 5},
+		{"p0 >= 3 && p1 == 0", 1},
+		{"p1 != 0 || !p1", 1},
+		{"!(p0 < 3)", 1},
+		{"min(p0, p2, 5)", 2},
+		{"max(p0, p2)", 3},
+		{"abs(p1 - p0)", 3},
+		{"if(p1 > 0, 10, p2)", 2},
+		{"2e-1 * 10", 2},
+		{"p1 && p0 / p1", 0},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.src)
+		if err != nil {
+			t.Errorf("Error parsing %q: %v", tt.src, err)
+			continue
+		}
+		if got := e.Eval(marking); got != tt.expected {
+			t.Errorf("Expected %q to be %g, got %g", tt.src, tt.expected, got)
+		}
+		if e.String() != tt.src {
+			t.Errorf("Expected String to return the source %q, got %q", tt.src, e.String())
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src     string
+		message string
+	}{
+		{"", "unexpected end"},
+		{"p0 +", "unexpected end"},
+		{"(p0", "expected \")\""},
+		{"p0 p1", "unexpected \"p1\""},
+		{"q1", "unknown identifier \"q1\""},
+		{"p", "unknown identifier \"p\""},
+		{"abs(p0, p1)", "abs takes 1 arguments"},
+		{"min p0", "expected \"(\""},
+		{"p0 $ 1", "unexpected character"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.src)
+		if err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("Expected parsing %q to fail with %q, got %v", tt.src, tt.message, err)
+		}
+	}
+}
+
+func TestMaxPlace(t *testing.T) {
+	for src, expected := range map[string]int{"1 + 2": -1, "p3 * p1": 3, "max(p0, P7)": 7} {
+		e, err := Parse(src)
+		if err != nil {
+			t.Fatalf("Error parsing %q: %v", src, err)
+		}
+		if e.MaxPlace() != expected {
+			t.Errorf("Expected the largest place of %q to be %d, got %d", src, expected, e.MaxPlace())
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	s, err := Compile(Reward{Name: "busy", Rate: "p0 > 0", Impulses: map[int]string{1: "p2", 0: "1"}})
+	if err != nil {
+		t.Fatalf("Error compiling reward: %v", err)
+	}
+	if s.Rate == nil || len(s.Impulses) != 2 {
+		t.Fatalf("Expected a rate and two impulses, got %+v", s)
+	}
+	if err := s.Validate(3, 2); err != nil {
+		t.Errorf("Expected the reward to fit a net with 3 places and 2 transitions: %v", err)
+	}
+	if err := s.Validate(2, 2); err == nil {
+		t.Errorf("Expected an error for an impulse on a missing place")
+	}
+	if err := s.Validate(3, 1); err == nil {
+		t.Errorf("Expected an error for an impulse on a missing transition")
+	}
+	if err := s.ValidateImmediate([]bool{false, true}); err == nil {
+		t.Errorf("Expected an error for an impulse on an immediate transition")
+	}
+	if err := s.ValidateImmediate(nil); err != nil {
+		t.Errorf("Expected the reward to fit a net without immediate transitions: %v", err)
+	}
+
+	if _, err := CompileAll([]Reward{{Name: "a", Rate: "1"}, {Name: "a", Rate: "2"}}); err == nil {
+		t.Errorf("Expected an error for duplicate reward names")
+	}
+	if _, err := Compile(Reward{Rate: "1"}); err == nil {
+		t.Errorf("Expected an error for a reward without a name")
+	}
+	if _, err := Compile(Reward{Name: "bad", Impulses: map[int]string{0: "p0 +"}}); err == nil {
+		t.Errorf("Expected an error for an invalid impulse expression")
+	}
+}
@@ -0,0 +1,114 @@
+// Package reward implements reward structures over stochastic Petri nets: rate rewards earned per
+// unit time in each marking and impulse rewards earned on each firing of a transition, both given
+// as expressions of the marking.
+package reward
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Reward is the specification of a reward structure, as read from the configuration.
+type Reward struct {
+	// Name identifies the reward in the output.
+	Name string
+	// Rate is an expression of the marking earned per unit time spent in it. Empty means zero.
+	Rate string
+	// Impulses maps transition indices to an expression of the marking the transition fires in,
+	// earned on each firing.
+	Impulses map[int]string
+}
+
+// Structure is a compiled reward structure.
+type Structure struct {
+	// Name identifies the reward in the output.
+	Name string
+	// Rate is the rate reward, or nil if there is none.
+	Rate *Expr
+	// Impulses maps transition indices to their impulse reward.
+	Impulses map[int]*Expr
+}
+
+// Compile parses the expressions of a reward specification.
+func Compile(r Reward) (*Structure, error) {
+	if r.Name == "" {
+		return nil, fmt.Errorf("reward has no name")
+	}
+	s := &Structure{Name: r.Name, Impulses: make(map[int]*Expr, len(r.Impulses))}
+	if r.Rate != "" {
+		rate, err := Parse(r.Rate)
+		if err != nil {
+			return nil, fmt.Errorf("rate of reward %q: %w", r.Name, err)
+		}
+		s.Rate = rate
+	}
+	for t, src := range r.Impulses {
+		if t < 0 {
+			return nil, fmt.Errorf("impulse of reward %q on negative transition %d", r.Name, t)
+		}
+		impulse, err := Parse(src)
+		if err != nil {
+			return nil, fmt.Errorf("impulse of reward %q on transition %d: %w", r.Name, t, err)
+		}
+		s.Impulses[t] = impulse
+	}
+	return s, nil
+}
+
+// CompileAll compiles a list of reward specifications, whose names must be unique.
+func CompileAll(rewards []Reward) ([]*Structure, error) {
+	structures := make([]*Structure, len(rewards))
+	seen := make(map[string]bool, len(rewards))
+	for i, r := range rewards {
+		if seen[r.Name] {
+			return nil, fmt.Errorf("duplicate reward %q", r.Name)
+		}
+		seen[r.Name] = true
+		s, err := Compile(r)
+		if err != nil {
+			return nil, err
+		}
+		structures[i] = s
+	}
+	return structures, nil
+}
+
+// Validate checks that the expressions of the structure only refer to the given number of places
+// and that its impulses are on the given number of transitions.
+func (s *Structure) Validate(places, transitions int) error {
+	if s.Rate != nil && s.Rate.MaxPlace() >= places {
+		return fmt.Errorf("rate of reward %q refers to place %d of a net with %d places", s.Name, s.Rate.MaxPlace(), places)
+	}
+	for _, t := range s.transitions() {
+		if t >= transitions {
+			return fmt.Errorf("reward %q has an impulse on transition %d of a net with %d transitions", s.Name, t, transitions)
+		}
+		if m := s.Impulses[t].MaxPlace(); m >= places {
+			return fmt.Errorf("impulse of reward %q on transition %d refers to place %d of a net with %d places", s.Name, t, m, places)
+		}
+	}
+	return nil
+}
+
+// ValidateImmediate checks that the structure has no impulse on a transition marked immediate.
+// Tangible reachability graphs do not keep the vanishing markings immediate transitions fire in,
+// so such impulses could never be earned.
+func (s *Structure) ValidateImmediate(immediate []bool) error {
+	for _, t := range s.transitions() {
+		if t < len(immediate) && immediate[t] {
+			return fmt.Errorf("reward %q has an impulse on immediate transition %d", s.Name, t)
+		}
+	}
+	return nil
+}
+
+// transitions returns the transitions with an impulse reward in increasing order, so errors do
+// not depend on map iteration order.
+func (s *Structure) transitions() []int {
+	ts := make([]int, 0, len(s.Impulses))
+	for t := range s.Impulses {
+		ts = append(ts, t)
+	}
+	sort.Ints(ts)
+	return ts
+}
@@ -0,0 +1,542 @@
+// Package simulation estimates the steady-state measures of stochastic Petri nets by
+// discrete-event simulation. It runs directly on the net, so it also labels nets whose
+// reachability graph is too large to build, and it serves as a cross-check of the numeric solvers.
+package simulation
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"spn-benchmark-ds/internal/pkg/generation"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+
+	"gonum.org/v1/gonum/mathext"
+)
+
+// Defaults used for the zero values of Options.
+const (
+	// DefaultTime is the simulated time over which measures are collected.
+	DefaultTime = 10000.0
+	// DefaultBatches is the number of batches of the batch-means method.
+	DefaultBatches = 20
+	// DefaultConfidence is the confidence level of the intervals.
+	DefaultConfidence = 0.95
+)
+
+// maxImmediateFirings is the number of consecutive immediate firings after which the vanishing
+// markings visited are taken to form a cycle.
+const maxImmediateFirings = 100000
+
+// ErrPlaceLimit is returned when a place exceeds the place limit of the options, which usually
+// means the net is unbounded.
+var ErrPlaceLimit = errors.New("place limit exceeded")
+
+// Options configures a simulation run.
+type Options struct {
+	// Time is the simulated time over which measures are collected, after the warm-up period.
+	// Zero selects DefaultTime.
+	Time float64
+	// Warmup is the simulated time discarded before measures are collected, so that the estimates
+	// do not depend on the initial marking.
+	Warmup float64
+	// Batches is the number of equally long batches the collection period is split into for the
+	// batch-means confidence intervals. It must be at least two; zero selects DefaultBatches.
+	Batches int
+	// Confidence is the confidence level of the intervals, in (0, 1). Zero selects
+	// DefaultConfidence.
+	Confidence float64
+	// PlaceLimit stops the simulation with ErrPlaceLimit when a place holds more tokens.
+	// Zero disables the limit.
+	PlaceLimit int
+}
+
+// withDefaults returns the options with zero values replaced by their defaults.
+func (o Options) withDefaults() Options {
+	if o.Time == 0 {
+		o.Time = DefaultTime
+	}
+	if o.Batches == 0 {
+		o.Batches = DefaultBatches
+	}
+	if o.Confidence == 0 {
+		o.Confidence = DefaultConfidence
+	}
+	return o
+}
+
+// Validate checks the options.
+func (o Options) Validate() error {
+	o = o.withDefaults()
+	if o.Time <= 0 || math.IsInf(o.Time, 0) || math.IsNaN(o.Time) {
+		return fmt.Errorf("simulation time must be finite and positive, got %g", o.Time)
+	}
+	if o.Warmup < 0 || math.IsInf(o.Warmup, 0) || math.IsNaN(o.Warmup) {
+		return fmt.Errorf("simulation warm-up must be finite and not negative, got %g", o.Warmup)
+	}
+	if o.Batches < 2 {
+		return fmt.Errorf("simulation needs at least 2 batches, got %d", o.Batches)
+	}
+	if o.Confidence <= 0 || o.Confidence >= 1 {
+		return fmt.Errorf("confidence level must be in (0, 1), got %g", o.Confidence)
+	}
+	if o.PlaceLimit < 0 {
+		return fmt.Errorf("place limit must not be negative, got %d", o.PlaceLimit)
+	}
+	return nil
+}
+
+// Estimate is a point estimate with the half-width of its confidence interval.
+type Estimate struct {
+	// Mean is the mean of the batch means.
+	Mean float64 `json:"mean"`
+	// HalfWidth is the half-width of the confidence interval around Mean.
+	HalfWidth float64 `json:"half_width"`
+}
+
+// Result holds the estimates of a simulation run.
+type Result struct {
+	// AverageMarkings holds the estimated expected number of tokens in each place.
+	AverageMarkings []Estimate `json:"average_markings"`
+	// Throughputs holds the estimated number of firings of each transition per unit time,
+	// immediate transitions included.
+	Throughputs []Estimate `json:"throughputs"`
+	// MarkingDensities holds, for each place, the estimated probability of each token count from
+	// zero to the largest count observed in any place.
+	MarkingDensities [][]Estimate `json:"marking_densities"`
+	// Time is the total simulated time, including the warm-up period.
+	Time float64 `json:"time"`
+	// Firings is the total number of firings, including those of the warm-up period.
+	Firings int `json:"firings"`
+	// Batches is the number of batches of the batch-means method.
+	Batches int `json:"batches"`
+	// Confidence is the confidence level of the intervals.
+	Confidence float64 `json:"confidence"`
+}
+
+// Simulate simulates a stochastic Petri net with the next-reaction method of Gibson and Bruck and
+// estimates its steady-state measures by batch means. Timed transition t fires after a delay with
+// mean 1/lambdaValues[t] drawn from its delay distribution, exponential unless the net says
+// otherwise, under single-server semantics. Transitions race with enabling memory: a transition
+// keeps its firing time while it stays enabled and draws a new one when it is re-enabled. Immediate
+// transitions fire in zero time, chosen among the enabled ones of the highest priority with
+// probability proportional to their weight. Every enabled timed transition keeps a scheduled
+// firing time in an indexed priority queue, and a firing only revisits the transitions whose input
+// or inhibitor places it changes. A net that dies keeps its dead marking until the end of the run.
+func Simulate(pn *petrinet.PetriNet, lambdaValues []float64, opts Options, rng *rand.Rand) (*Result, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if len(lambdaValues) != pn.Transitions {
+		return nil, fmt.Errorf("got %d lambda values for %d transitions", len(lambdaValues), pn.Transitions)
+	}
+	for t, d := range pn.Delays {
+		if err := d.Validate(); err != nil {
+			return nil, fmt.Errorf("transition %d: %w", t, err)
+		}
+	}
+	opts = opts.withDefaults()
+	s := newSimulator(pn, lambdaValues, opts, rng)
+	if err := s.run(); err != nil {
+		return nil, err
+	}
+	return s.result(), nil
+}
+
+// arc is a place with a token count: the tokens consumed by an input arc, the threshold of an
+// inhibitor arc or the change of the marking on firing.
+type arc struct {
+	place  int
+	tokens int
+}
+
+// simulator holds the state of a simulation run.
+type simulator struct {
+	pn           *petrinet.PetriNet
+	lambdaValues []float64
+	opts         Options
+	rng          *rand.Rand
+
+	inputs, inhibitors, changes [][]arc
+	// dependents[t] lists the transitions whose enabling may change when t fires.
+	dependents [][]int
+	immediate  []int
+
+	marking []int
+	now     float64
+	queue   eventQueue
+	firings int
+
+	batchLength float64
+	// tokenTime[b][p] and countTime[b][p][k] integrate the tokens of place p and the time it holds
+	// k tokens over batch b; batchFirings[b][t] counts the firings of t in batch b.
+	tokenTime    [][]float64
+	countTime    [][][]float64
+	batchFirings [][]float64
+}
+
+func newSimulator(pn *petrinet.PetriNet, lambdaValues []float64, opts Options, rng *rand.Rand) *simulator {
+	s := &simulator{
+		pn:           pn,
+		lambdaValues: lambdaValues,
+		opts:         opts,
+		rng:          rng,
+		inputs:       make([][]arc, pn.Transitions),
+		inhibitors:   make([][]arc, pn.Transitions),
+		changes:      make([][]arc, pn.Transitions),
+		dependents:   make([][]int, pn.Transitions),
+		marking:      append([]int(nil), pn.InitialMarking...),
+		queue:        eventQueue{index: make([]int, pn.Transitions)},
+		batchLength:  opts.Time / float64(opts.Batches),
+		tokenTime:    make([][]float64, opts.Batches),
+		countTime:    make([][][]float64, opts.Batches),
+		batchFirings: make([][]float64, opts.Batches),
+	}
+	for b := 0; b < opts.Batches; b++ {
+		s.tokenTime[b] = make([]float64, pn.Places)
+		s.countTime[b] = make([][]float64, pn.Places)
+		s.batchFirings[b] = make([]float64, pn.Transitions)
+	}
+
+	// readers[p] lists the transitions that test place p through an input or inhibitor arc.
+	readers := make([][]int, pn.Places)
+	for t := 0; t < pn.Transitions; t++ {
+		s.queue.index[t] = -1
+		if pn.IsImmediate(t) {
+			s.immediate = append(s.immediate, t)
+		}
+		for p := 0; p < pn.Places; p++ {
+			pre, post := pn.At(p, t), pn.At(p, t+pn.Transitions)
+			threshold := pn.Inhibitor(p, t)
+			if pre > 0 {
+				s.inputs[t] = append(s.inputs[t], arc{p, pre})
+			}
+			if threshold > 0 {
+				s.inhibitors[t] = append(s.inhibitors[t], arc{p, threshold})
+			}
+			if pre > 0 || threshold > 0 {
+				readers[p] = append(readers[p], t)
+			}
+			if post != pre {
+				s.changes[t] = append(s.changes[t], arc{p, post - pre})
+			}
+		}
+	}
+	for t := 0; t < pn.Transitions; t++ {
+		seen := make(map[int]bool)
+		for _, c := range s.changes[t] {
+			for _, u := range readers[c.place] {
+				if !seen[u] {
+					seen[u] = true
+					s.dependents[t] = append(s.dependents[t], u)
+				}
+			}
+		}
+	}
+	return s
+}
+
+// enabled reports whether transition t is enabled in the current marking.
+func (s *simulator) enabled(t int) bool {
+	for _, in := range s.inputs[t] {
+		if s.marking[in.place] < in.tokens {
+			return false
+		}
+	}
+	for _, inh := range s.inhibitors[t] {
+		if s.marking[inh.place] >= inh.tokens {
+			return false
+		}
+	}
+	return true
+}
+
+// delay draws the firing delay of timed transition t.
+func (s *simulator) delay(t int) float64 {
+	if s.lambdaValues[t] <= 0 {
+		return math.Inf(1)
+	}
+	return sampleDelay(s.rng, s.pn.Delay(t), 1/s.lambdaValues[t])
+}
+
+// sampleDelay draws a delay with the given mean from distribution d.
+func sampleDelay(rng *rand.Rand, d petrinet.Delay, mean float64) float64 {
+	switch d.Kind {
+	case petrinet.DelayDeterministic:
+		return mean
+	case petrinet.DelayErlang:
+		sum := 0.0
+		for i := 0; i < d.Stages; i++ {
+			sum += rng.ExpFloat64()
+		}
+		return sum * mean / float64(d.Stages)
+	case petrinet.DelayHyperexponential:
+		// With balanced means p1/μ1 = p2/μ2, the branch probability follows from the SCV.
+		p := (1 + math.Sqrt((d.SCV-1)/(d.SCV+1))) / 2
+		if rng.Float64() < p {
+			return rng.ExpFloat64() * mean / (2 * p)
+		}
+		return rng.ExpFloat64() * mean / (2 * (1 - p))
+	case petrinet.DelayUniform:
+		return mean * (1 + d.Spread*(2*rng.Float64()-1))
+	case petrinet.DelayLognormal:
+		sigma2 := math.Log(1 + d.SCV)
+		return math.Exp(math.Log(mean) - sigma2/2 + math.Sqrt(sigma2)*rng.NormFloat64())
+	default:
+		return rng.ExpFloat64() * mean
+	}
+}
+
+// schedule updates the scheduled firing of timed transition t after the marking changed.
+// A transition that stays enabled keeps its firing time, unless it just fired.
+func (s *simulator) schedule(t int, fired bool) {
+	if s.pn.IsImmediate(t) {
+		return
+	}
+	scheduled := s.queue.index[t] >= 0
+	switch enabled := s.enabled(t); {
+	case enabled && (!scheduled || fired):
+		s.queue.set(t, s.now+s.delay(t))
+	case !enabled && scheduled:
+		s.queue.remove(t)
+	}
+}
+
+// fire fires transition t and reschedules the transitions it affects.
+func (s *simulator) fire(t int) error {
+	for _, c := range s.changes[t] {
+		s.marking[c.place] += c.tokens
+		if s.opts.PlaceLimit > 0 && s.marking[c.place] > s.opts.PlaceLimit {
+			return fmt.Errorf("%w: place %d holds %d tokens at time %g", ErrPlaceLimit, c.place, s.marking[c.place], s.now)
+		}
+	}
+	s.firings++
+	if b := s.batch(s.now); b >= 0 {
+		s.batchFirings[b][t]++
+	}
+	for _, u := range s.dependents[t] {
+		if u != t {
+			s.schedule(u, false)
+		}
+	}
+	s.schedule(t, true)
+	return nil
+}
+
+// fireImmediate fires enabled immediate transitions until the marking is tangible.
+func (s *simulator) fireImmediate() error {
+	var candidates []int
+	for n := 0; ; n++ {
+		priority, total := 0, 0.0
+		candidates = candidates[:0]
+		for _, t := range s.immediate {
+			if p := s.pn.Priority(t); p >= priority && s.enabled(t) {
+				if p > priority {
+					priority, total = p, 0
+					candidates = candidates[:0]
+				}
+				candidates = append(candidates, t)
+				total += s.pn.Weight(t)
+			}
+		}
+		if len(candidates) == 0 {
+			return nil
+		}
+		if n == maxImmediateFirings {
+			return fmt.Errorf("%w: %d immediate firings at time %g", generation.ErrVanishingLoop, n, s.now)
+		}
+		chosen := candidates[len(candidates)-1]
+		r := s.rng.Float64() * total
+		for _, t := range candidates {
+			if r -= s.pn.Weight(t); r < 0 {
+				chosen = t
+				break
+			}
+		}
+		if err := s.fire(chosen); err != nil {
+			return err
+		}
+	}
+}
+
+// batch returns the batch that time t falls in, or -1 if it is outside the collection period.
+func (s *simulator) batch(t float64) int {
+	if t < s.opts.Warmup {
+		return -1
+	}
+	b := int((t - s.opts.Warmup) / s.batchLength)
+	if b >= s.opts.Batches {
+		return -1
+	}
+	return b
+}
+
+// advance integrates the current marking from the current time to t, split at the batch
+// boundaries.
+func (s *simulator) advance(t float64) {
+	for s.now < t {
+		if s.now < s.opts.Warmup {
+			s.now = math.Min(t, s.opts.Warmup)
+			continue
+		}
+		b := int((s.now - s.opts.Warmup) / s.batchLength)
+		end := s.opts.Warmup + float64(b+1)*s.batchLength
+		if end <= s.now {
+			// The current time is a batch boundary that rounded down to the previous batch.
+			b++
+			end = s.opts.Warmup + float64(b+1)*s.batchLength
+		}
+		if b >= s.opts.Batches {
+			s.now = t
+			return
+		}
+		end = math.Min(end, t)
+		dt := end - s.now
+		for p, k := range s.marking {
+			s.tokenTime[b][p] += float64(k) * dt
+			for len(s.countTime[b][p]) <= k {
+				s.countTime[b][p] = append(s.countTime[b][p], 0)
+			}
+			s.countTime[b][p][k] += dt
+		}
+		s.now = end
+	}
+}
+
+// run simulates the net until the end of the collection period.
+func (s *simulator) run() error {
+	end := s.opts.Warmup + s.opts.Time
+	for t := 0; t < s.pn.Transitions; t++ {
+		s.schedule(t, false)
+	}
+	for {
+		if err := s.fireImmediate(); err != nil {
+			return err
+		}
+		if len(s.queue.events) == 0 || s.queue.events[0].time >= end {
+			s.advance(end)
+			return nil
+		}
+		next := s.queue.events[0]
+		s.advance(next.time)
+		if err := s.fire(next.transition); err != nil {
+			return err
+		}
+	}
+}
+
+// result computes the batch-means estimates.
+func (s *simulator) result() *Result {
+	batches := float64(s.opts.Batches)
+	quantile := studentQuantile(1-(1-s.opts.Confidence)/2, batches-1)
+	estimate := func(value func(b int) float64) Estimate {
+		mean, sq := 0.0, 0.0
+		for b := 0; b < s.opts.Batches; b++ {
+			mean += value(b)
+		}
+		mean /= batches
+		for b := 0; b < s.opts.Batches; b++ {
+			d := value(b) - mean
+			sq += d * d
+		}
+		return Estimate{Mean: mean, HalfWidth: quantile * math.Sqrt(sq/(batches-1)/batches)}
+	}
+
+	maxTokens := 0
+	for b := range s.countTime {
+		for _, counts := range s.countTime[b] {
+			maxTokens = max(maxTokens, len(counts)-1)
+		}
+	}
+	result := &Result{
+		AverageMarkings:  make([]Estimate, s.pn.Places),
+		Throughputs:      make([]Estimate, s.pn.Transitions),
+		MarkingDensities: make([][]Estimate, s.pn.Places),
+		Time:             s.now,
+		Firings:          s.firings,
+		Batches:          s.opts.Batches,
+		Confidence:       s.opts.Confidence,
+	}
+	for p := 0; p < s.pn.Places; p++ {
+		result.AverageMarkings[p] = estimate(func(b int) float64 { return s.tokenTime[b][p] / s.batchLength })
+		result.MarkingDensities[p] = make([]Estimate, maxTokens+1)
+		for k := range result.MarkingDensities[p] {
+			result.MarkingDensities[p][k] = estimate(func(b int) float64 {
+				if k < len(s.countTime[b][p]) {
+					return s.countTime[b][p][k] / s.batchLength
+				}
+				return 0
+			})
+		}
+	}
+	for t := 0; t < s.pn.Transitions; t++ {
+		result.Throughputs[t] = estimate(func(b int) float64 { return s.batchFirings[b][t] / s.batchLength })
+	}
+	return result
+}
+
+// studentQuantile returns the p-quantile, for p above one half, of Student's t-distribution with
+// nu degrees of freedom, by inverting its relation to the regularized incomplete beta function:
+// P(|T| > t) = I_x(nu/2, 1/2) with x = nu/(nu+t²).
+func studentQuantile(p, nu float64) float64 {
+	x := mathext.InvRegIncBeta(nu/2, 0.5, 2*(1-p))
+	return math.Sqrt(nu * (1 - x) / x)
+}
+
+// event is the scheduled firing of a timed transition.
+type event struct {
+	transition int
+	time       float64
+}
+
+// eventQueue is a binary min-heap of events ordered by time, indexed by transition so that the
+// firing time of a transition can be changed or removed in logarithmic time.
+type eventQueue struct {
+	events []event
+	// index[t] is the position of the event of transition t in events, or -1 if it has none.
+	index []int
+}
+
+func (q *eventQueue) Len() int { return len(q.events) }
+
+func (q *eventQueue) Less(i, j int) bool {
+	if q.events[i].time != q.events[j].time {
+		return q.events[i].time < q.events[j].time
+	}
+	return q.events[i].transition < q.events[j].transition
+}
+
+func (q *eventQueue) Swap(i, j int) {
+	q.events[i], q.events[j] = q.events[j], q.events[i]
+	q.index[q.events[i].transition] = i
+	q.index[q.events[j].transition] = j
+}
+
+func (q *eventQueue) Push(x interface{}) {
+	e := x.(event)
+	q.index[e.transition] = len(q.events)
+	q.events = append(q.events, e)
+}
+
+func (q *eventQueue) Pop() interface{} {
+	e := q.events[len(q.events)-1]
+	q.events = q.events[:len(q.events)-1]
+	q.index[e.transition] = -1
+	return e
+}
+
+// set schedules transition t at the given time, replacing its current event.
+func (q *eventQueue) set(t int, time float64) {
+	if i := q.index[t]; i >= 0 {
+		q.events[i].time = time
+		heap.Fix(q, i)
+		return
+	}
+	heap.Push(q, event{transition: t, time: time})
+}
+
+// remove cancels the event of transition t.
+func (q *eventQueue) remove(t int) {
+	heap.Remove(q, q.index[t])
+}
@@ -0,0 +1,194 @@
+package simulation
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"spn-benchmark-ds/internal/pkg/analysis"
+	"spn-benchmark-ds/internal/pkg/generation"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+	"spn-benchmark-ds/internal/pkg/testnets"
+	"testing"
+)
+
+// newNet builds a net from arcs given as place, transition and direction (0 = input, 1 = output),
+// with a single token in initialPlace.
+func newNet(places, transitions, initialPlace int, arcs [][3]int) *petrinet.PetriNet {
+	pn := petrinet.NewPetriNet(places, transitions)
+	for _, a := range arcs {
+		pn.Set(a[0], a[1]+a[2]*pn.Transitions, 1)
+	}
+	pn.Set(initialPlace, 2*pn.Transitions, 1)
+	pn.InitialMarking[initialPlace] = 1
+	return pn
+}
+
+func TestStudentQuantile(t *testing.T) {
+	for _, c := range []struct{ p, nu, expected float64 }{
+		{0.975, 1, 12.7062},
+		{0.975, 19, 2.0930},
+		{0.95, 10, 1.8125},
+		{0.995, 30, 2.7500},
+	} {
+		if got := studentQuantile(c.p, c.nu); math.Abs(got-c.expected) > 1e-4 {
+			t.Errorf("Expected the %g-quantile with %g degrees of freedom to be %g, but got %g", c.p, c.nu, c.expected, got)
+		}
+	}
+}
+
+func TestSimulateTwoPlaceCycle(t *testing.T) {
+	// A token moves from P0 to P1 at rate 1 and back at rate 3, so it spends 3/4 of the time in P0.
+	pn := newNet(2, 2, 0, [][3]int{{0, 0, 0}, {1, 0, 1}, {1, 1, 0}, {0, 1, 1}})
+	result, err := Simulate(pn, []float64{1, 3}, Options{Time: 20000, Warmup: 10}, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("Error simulating: %v", err)
+	}
+	expectEstimates(t, "average markings", result.AverageMarkings, []float64{0.75, 0.25})
+	expectEstimates(t, "throughputs", result.Throughputs, []float64{0.75, 0.75})
+	expectEstimates(t, "densities of P0", result.MarkingDensities[0], []float64{0.25, 0.75})
+	if result.Time != 20010 || result.Batches != DefaultBatches || result.Confidence != DefaultConfidence {
+		t.Errorf("Expected the run to cover 20010 time units in %d batches at %g, but got %+v",
+			DefaultBatches, DefaultConfidence, result)
+	}
+	for _, e := range result.AverageMarkings {
+		if e.HalfWidth <= 0 || e.HalfWidth > 0.05 {
+			t.Errorf("Expected a small positive confidence interval, but got %+v", e)
+		}
+	}
+}
+
+func TestSimulateMatchesNumericAnalysis(t *testing.T) {
+	pn := testnets.ChoiceNet(0)
+	lambdaValues := []float64{2, 0, 0, 1, 4}
+	rg, err := generation.GenerateTangibleReachabilityGraph(pn, 10, 100)
+	if err != nil {
+		t.Fatalf("Error generating reachability graph: %v", err)
+	}
+	exact, _, err := analysis.Analyze(rg, lambdaValues, analysis.Options{})
+	if err != nil {
+		t.Fatalf("Error solving: %v", err)
+	}
+
+	result, err := Simulate(pn, lambdaValues, Options{Time: 20000}, rand.New(rand.NewSource(2)))
+	if err != nil {
+		t.Fatalf("Error simulating: %v", err)
+	}
+	expectEstimates(t, "average markings", result.AverageMarkings, exact.AverageMarkings)
+	expectEstimates(t, "throughputs", result.Throughputs, exact.Throughputs)
+}
+
+func TestSampleDelay(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	const mean, n = 0.5, 200000
+	for _, c := range []struct {
+		delay petrinet.Delay
+		scv   float64
+	}{
+		{petrinet.Delay{}, 1},
+		{petrinet.Delay{Kind: petrinet.DelayDeterministic}, 0},
+		{petrinet.Delay{Kind: petrinet.DelayErlang, Stages: 4}, 0.25},
+		{petrinet.Delay{Kind: petrinet.DelayHyperexponential, SCV: 3}, 3},
+		{petrinet.Delay{Kind: petrinet.DelayUniform, Spread: 0.6}, 0.12},
+		{petrinet.Delay{Kind: petrinet.DelayLognormal, SCV: 0.5}, 0.5},
+	} {
+		sum, sq := 0.0, 0.0
+		for i := 0; i < n; i++ {
+			x := sampleDelay(rng, c.delay, mean)
+			sum += x
+			sq += x * x
+		}
+		m := sum / n
+		scv := (sq/n - m*m) / (m * m)
+		if math.Abs(m-mean) > 0.01 || math.Abs(scv-c.scv) > 0.05*math.Max(1, c.scv) {
+			t.Errorf("Expected %+v to have mean %g and SCV %g, but got %g and %g", c.delay, mean, c.scv, m, scv)
+		}
+	}
+}
+
+func TestSimulateNonExponentialDelays(t *testing.T) {
+	// The token stays in P0 for exactly 1 and in P1 for an Erlang time with mean 1/3, so the
+	// averages only depend on the means and match those of the exponential net.
+	pn := newNet(2, 2, 0, [][3]int{{0, 0, 0}, {1, 0, 1}, {1, 1, 0}, {0, 1, 1}})
+	pn.SetDelay(0, petrinet.Delay{Kind: petrinet.DelayDeterministic})
+	pn.SetDelay(1, petrinet.Delay{Kind: petrinet.DelayErlang, Stages: 2})
+	result, err := Simulate(pn, []float64{1, 3}, Options{Time: 20000}, rand.New(rand.NewSource(7)))
+	if err != nil {
+		t.Fatalf("Error simulating: %v", err)
+	}
+	expectEstimates(t, "average markings", result.AverageMarkings, []float64{0.75, 0.25})
+	expectEstimates(t, "throughputs", result.Throughputs, []float64{0.75, 0.75})
+
+	pn.SetDelay(1, petrinet.Delay{Kind: petrinet.DelayErlang})
+	if _, err := Simulate(pn, []float64{1, 3}, Options{}, rand.New(rand.NewSource(7))); err == nil {
+		t.Errorf("Expected an error for an invalid delay")
+	}
+}
+
+func TestSimulateIsReproducible(t *testing.T) {
+	pn := testnets.ChoiceNet(0)
+	lambdaValues := []float64{2, 0, 0, 1, 4}
+	first, err := Simulate(pn, lambdaValues, Options{Time: 100}, rand.New(rand.NewSource(3)))
+	if err != nil {
+		t.Fatalf("Error simulating: %v", err)
+	}
+	second, err := Simulate(pn, lambdaValues, Options{Time: 100}, rand.New(rand.NewSource(3)))
+	if err != nil {
+		t.Fatalf("Error simulating: %v", err)
+	}
+	if first.Firings != second.Firings || first.AverageMarkings[0] != second.AverageMarkings[0] {
+		t.Errorf("Expected identical runs for the same seed, but got %+v and %+v", first, second)
+	}
+}
+
+func TestSimulateDeadNet(t *testing.T) {
+	// T0 moves the token from P0 to P1, where nothing can fire.
+	pn := newNet(2, 1, 0, [][3]int{{0, 0, 0}, {1, 0, 1}})
+	result, err := Simulate(pn, []float64{5}, Options{Time: 1000, Warmup: 100}, rand.New(rand.NewSource(4)))
+	if err != nil {
+		t.Fatalf("Error simulating: %v", err)
+	}
+	if result.Firings != 1 {
+		t.Errorf("Expected a single firing, but got %d", result.Firings)
+	}
+	expectEstimates(t, "average markings", result.AverageMarkings, []float64{0, 1})
+}
+
+func TestSimulateErrors(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+
+	// T0 puts a token back into P0 and adds one to P1 on every firing.
+	producer := newNet(2, 1, 0, [][3]int{{0, 0, 0}, {0, 0, 1}, {1, 0, 1}})
+	if _, err := Simulate(producer, []float64{1}, Options{PlaceLimit: 10}, rng); !errors.Is(err, ErrPlaceLimit) {
+		t.Errorf("Expected ErrPlaceLimit, but got %v", err)
+	}
+
+	// The immediate transitions t0 and t1 pass the token back and forth forever.
+	loop := newNet(2, 2, 0, [][3]int{{0, 0, 0}, {1, 0, 1}, {1, 1, 0}, {0, 1, 1}})
+	loop.SetImmediate(0, 1, 1)
+	loop.SetImmediate(1, 1, 1)
+	if _, err := Simulate(loop, []float64{0, 0}, Options{}, rng); !errors.Is(err, generation.ErrVanishingLoop) {
+		t.Errorf("Expected ErrVanishingLoop, but got %v", err)
+	}
+
+	if _, err := Simulate(producer, []float64{1, 2}, Options{}, rng); err == nil {
+		t.Errorf("Expected an error for a lambda value per transition mismatch")
+	}
+	for _, opts := range []Options{{Time: -1}, {Warmup: -1}, {Batches: 1}, {Confidence: 1}, {PlaceLimit: -1}} {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("Expected an error for options %+v", opts)
+		}
+	}
+}
+
+// expectEstimates checks that each estimate is within 0.02 of the expected value.
+func expectEstimates(t *testing.T, name string, got []Estimate, expected []float64) {
+	t.Helper()
+	if len(got) < len(expected) {
+		t.Fatalf("Expected %d %s, but got %d", len(expected), name, len(got))
+	}
+	for i, e := range expected {
+		if math.Abs(got[i].Mean-e) > 0.02 {
+			t.Errorf("Expected %s[%d] to be %f, but got %f ± %f", name, i, e, got[i].Mean, got[i].HalfWidth)
+		}
+	}
+}
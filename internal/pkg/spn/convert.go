@@ -0,0 +1,19 @@
+package spn
+
+// ToInt32Slice converts a slice of ints to the int32s of the protobuf format.
+func ToInt32Slice(slice []int) []int32 {
+	var result []int32
+	for _, v := range slice {
+		result = append(result, int32(v))
+	}
+	return result
+}
+
+// ToIntSlice converts a slice of int32s of the protobuf format to ints.
+func ToIntSlice(slice []int32) []int {
+	var result []int
+	for _, v := range slice {
+		result = append(result, int(v))
+	}
+	return result
+}
@@ -22,12 +22,18 @@ const (
 )
 
 type PetriNet struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Matrix        []int32                `protobuf:"varint,1,rep,packed,name=matrix,proto3" json:"matrix,omitempty"`
-	Places        int32                  `protobuf:"varint,2,opt,name=places,proto3" json:"places,omitempty"`
-	Transitions   int32                  `protobuf:"varint,3,opt,name=transitions,proto3" json:"transitions,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Matrix         []int32                `protobuf:"varint,1,rep,packed,name=matrix,proto3" json:"matrix,omitempty"`
+	Places         int32                  `protobuf:"varint,2,opt,name=places,proto3" json:"places,omitempty"`
+	Transitions    int32                  `protobuf:"varint,3,opt,name=transitions,proto3" json:"transitions,omitempty"`
+	Immediate      []bool                 `protobuf:"varint,4,rep,packed,name=immediate,proto3" json:"immediate,omitempty"`
+	Weights        []float64              `protobuf:"fixed64,5,rep,packed,name=weights,proto3" json:"weights,omitempty"`
+	Priorities     []int32                `protobuf:"varint,6,rep,packed,name=priorities,proto3" json:"priorities,omitempty"`
+	Inhibitors     []int32                `protobuf:"varint,7,rep,packed,name=inhibitors,proto3" json:"inhibitors,omitempty"`
+	Delays         []*Delay               `protobuf:"bytes,8,rep,name=delays,proto3" json:"delays,omitempty"`
+	InitialMarking []int32                `protobuf:"varint,9,rep,packed,name=initial_marking,json=initialMarking,proto3" json:"initial_marking,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *PetriNet) Reset() {
@@ -81,18 +87,132 @@ func (x *PetriNet) GetTransitions() int32 {
 	return 0
 }
 
+func (x *PetriNet) GetImmediate() []bool {
+	if x != nil {
+		return x.Immediate
+	}
+	return nil
+}
+
+func (x *PetriNet) GetWeights() []float64 {
+	if x != nil {
+		return x.Weights
+	}
+	return nil
+}
+
+func (x *PetriNet) GetPriorities() []int32 {
+	if x != nil {
+		return x.Priorities
+	}
+	return nil
+}
+
+func (x *PetriNet) GetInhibitors() []int32 {
+	if x != nil {
+		return x.Inhibitors
+	}
+	return nil
+}
+
+func (x *PetriNet) GetDelays() []*Delay {
+	if x != nil {
+		return x.Delays
+	}
+	return nil
+}
+
+func (x *PetriNet) GetInitialMarking() []int32 {
+	if x != nil {
+		return x.InitialMarking
+	}
+	return nil
+}
+
+type Delay struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Stages        int32                  `protobuf:"varint,2,opt,name=stages,proto3" json:"stages,omitempty"`
+	Scv           float64                `protobuf:"fixed64,3,opt,name=scv,proto3" json:"scv,omitempty"`
+	Spread        float64                `protobuf:"fixed64,4,opt,name=spread,proto3" json:"spread,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Delay) Reset() {
+	*x = Delay{}
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Delay) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Delay) ProtoMessage() {}
+
+func (x *Delay) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Delay.ProtoReflect.Descriptor instead.
+func (*Delay) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Delay) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Delay) GetStages() int32 {
+	if x != nil {
+		return x.Stages
+	}
+	return 0
+}
+
+func (x *Delay) GetScv() float64 {
+	if x != nil {
+		return x.Scv
+	}
+	return 0
+}
+
+func (x *Delay) GetSpread() float64 {
+	if x != nil {
+		return x.Spread
+	}
+	return 0
+}
+
 type ReachabilityGraph struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Vertices       []*Vertex              `protobuf:"bytes,1,rep,name=vertices,proto3" json:"vertices,omitempty"`
-	Edges          []*Edge                `protobuf:"bytes,2,rep,name=edges,proto3" json:"edges,omitempty"`
-	ArcTransitions []int32                `protobuf:"varint,3,rep,packed,name=arc_transitions,json=arcTransitions,proto3" json:"arc_transitions,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Vertices            []*Vertex              `protobuf:"bytes,1,rep,name=vertices,proto3" json:"vertices,omitempty"`
+	Edges               []*Edge                `protobuf:"bytes,2,rep,name=edges,proto3" json:"edges,omitempty"`
+	ArcTransitions      []int32                `protobuf:"varint,3,rep,packed,name=arc_transitions,json=arcTransitions,proto3" json:"arc_transitions,omitempty"`
+	ArcProbabilities    []float64              `protobuf:"fixed64,4,rep,packed,name=arc_probabilities,json=arcProbabilities,proto3" json:"arc_probabilities,omitempty"`
+	InitialDistribution []float64              `protobuf:"fixed64,5,rep,packed,name=initial_distribution,json=initialDistribution,proto3" json:"initial_distribution,omitempty"`
+	IsBounded           bool                   `protobuf:"varint,6,opt,name=is_bounded,json=isBounded,proto3" json:"is_bounded,omitempty"`
+	Vanishing           []bool                 `protobuf:"varint,7,rep,packed,name=vanishing,proto3" json:"vanishing,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
 func (x *ReachabilityGraph) Reset() {
 	*x = ReachabilityGraph{}
-	mi := &file_internal_pkg_spn_spn_proto_msgTypes[1]
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -104,7 +224,7 @@ func (x *ReachabilityGraph) String() string {
 func (*ReachabilityGraph) ProtoMessage() {}
 
 func (x *ReachabilityGraph) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_spn_spn_proto_msgTypes[1]
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -117,7 +237,7 @@ func (x *ReachabilityGraph) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReachabilityGraph.ProtoReflect.Descriptor instead.
 func (*ReachabilityGraph) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{1}
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *ReachabilityGraph) GetVertices() []*Vertex {
@@ -141,6 +261,34 @@ func (x *ReachabilityGraph) GetArcTransitions() []int32 {
 	return nil
 }
 
+func (x *ReachabilityGraph) GetArcProbabilities() []float64 {
+	if x != nil {
+		return x.ArcProbabilities
+	}
+	return nil
+}
+
+func (x *ReachabilityGraph) GetInitialDistribution() []float64 {
+	if x != nil {
+		return x.InitialDistribution
+	}
+	return nil
+}
+
+func (x *ReachabilityGraph) GetIsBounded() bool {
+	if x != nil {
+		return x.IsBounded
+	}
+	return false
+}
+
+func (x *ReachabilityGraph) GetVanishing() []bool {
+	if x != nil {
+		return x.Vanishing
+	}
+	return nil
+}
+
 type Vertex struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Marking       []int32                `protobuf:"varint,1,rep,packed,name=marking,proto3" json:"marking,omitempty"`
@@ -150,7 +298,7 @@ type Vertex struct {
 
 func (x *Vertex) Reset() {
 	*x = Vertex{}
-	mi := &file_internal_pkg_spn_spn_proto_msgTypes[2]
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -162,7 +310,7 @@ func (x *Vertex) String() string {
 func (*Vertex) ProtoMessage() {}
 
 func (x *Vertex) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_spn_spn_proto_msgTypes[2]
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -175,7 +323,7 @@ func (x *Vertex) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Vertex.ProtoReflect.Descriptor instead.
 func (*Vertex) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{2}
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *Vertex) GetMarking() []int32 {
@@ -195,7 +343,7 @@ type Edge struct {
 
 func (x *Edge) Reset() {
 	*x = Edge{}
-	mi := &file_internal_pkg_spn_spn_proto_msgTypes[3]
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -207,7 +355,7 @@ func (x *Edge) String() string {
 func (*Edge) ProtoMessage() {}
 
 func (x *Edge) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_spn_spn_proto_msgTypes[3]
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -220,7 +368,7 @@ func (x *Edge) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Edge.ProtoReflect.Descriptor instead.
 func (*Edge) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{3}
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *Edge) GetSrc() int32 {
@@ -245,13 +393,24 @@ type SPNData struct {
 	SteadyStateProbs  []float64              `protobuf:"fixed64,4,rep,packed,name=steady_state_probs,json=steadyStateProbs,proto3" json:"steady_state_probs,omitempty"`
 	AverageMarkings   []float64              `protobuf:"fixed64,5,rep,packed,name=average_markings,json=averageMarkings,proto3" json:"average_markings,omitempty"`
 	MarkingDensities  []*MarkingDensity      `protobuf:"bytes,6,rep,name=marking_densities,json=markingDensities,proto3" json:"marking_densities,omitempty"`
+	Invariants        *Invariants            `protobuf:"bytes,7,opt,name=invariants,proto3" json:"invariants,omitempty"`
+	SiphonsAndTraps   *SiphonsAndTraps       `protobuf:"bytes,8,opt,name=siphons_and_traps,json=siphonsAndTraps,proto3" json:"siphons_and_traps,omitempty"`
+	Transient         *Transient             `protobuf:"bytes,9,opt,name=transient,proto3" json:"transient,omitempty"`
+	Throughputs       []float64              `protobuf:"fixed64,10,rep,packed,name=throughputs,proto3" json:"throughputs,omitempty"`
+	TokenFlowRates    []float64              `protobuf:"fixed64,11,rep,packed,name=token_flow_rates,json=tokenFlowRates,proto3" json:"token_flow_rates,omitempty"`
+	SojournTimes      []float64              `protobuf:"fixed64,12,rep,packed,name=sojourn_times,json=sojournTimes,proto3" json:"sojourn_times,omitempty"`
+	ResponseTimes     []*ResponseTime        `protobuf:"bytes,13,rep,name=response_times,json=responseTimes,proto3" json:"response_times,omitempty"`
+	Rewards           []*RewardValue         `protobuf:"bytes,14,rep,name=rewards,proto3" json:"rewards,omitempty"`
+	ChainStructure    *ChainStructure        `protobuf:"bytes,15,opt,name=chain_structure,json=chainStructure,proto3" json:"chain_structure,omitempty"`
+	SolverStats       *SolverStats           `protobuf:"bytes,16,opt,name=solver_stats,json=solverStats,proto3" json:"solver_stats,omitempty"`
+	Simulation        *Simulation            `protobuf:"bytes,17,opt,name=simulation,proto3" json:"simulation,omitempty"`
 	unknownFields     protoimpl.UnknownFields
 	sizeCache         protoimpl.SizeCache
 }
 
 func (x *SPNData) Reset() {
 	*x = SPNData{}
-	mi := &file_internal_pkg_spn_spn_proto_msgTypes[4]
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -263,7 +422,7 @@ func (x *SPNData) String() string {
 func (*SPNData) ProtoMessage() {}
 
 func (x *SPNData) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_spn_spn_proto_msgTypes[4]
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -276,7 +435,7 @@ func (x *SPNData) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SPNData.ProtoReflect.Descriptor instead.
 func (*SPNData) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{4}
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *SPNData) GetPetriNet() *PetriNet {
@@ -321,6 +480,83 @@ func (x *SPNData) GetMarkingDensities() []*MarkingDensity {
 	return nil
 }
 
+func (x *SPNData) GetInvariants() *Invariants {
+	if x != nil {
+		return x.Invariants
+	}
+	return nil
+}
+
+func (x *SPNData) GetSiphonsAndTraps() *SiphonsAndTraps {
+	if x != nil {
+		return x.SiphonsAndTraps
+	}
+	return nil
+}
+
+func (x *SPNData) GetTransient() *Transient {
+	if x != nil {
+		return x.Transient
+	}
+	return nil
+}
+
+func (x *SPNData) GetThroughputs() []float64 {
+	if x != nil {
+		return x.Throughputs
+	}
+	return nil
+}
+
+func (x *SPNData) GetTokenFlowRates() []float64 {
+	if x != nil {
+		return x.TokenFlowRates
+	}
+	return nil
+}
+
+func (x *SPNData) GetSojournTimes() []float64 {
+	if x != nil {
+		return x.SojournTimes
+	}
+	return nil
+}
+
+func (x *SPNData) GetResponseTimes() []*ResponseTime {
+	if x != nil {
+		return x.ResponseTimes
+	}
+	return nil
+}
+
+func (x *SPNData) GetRewards() []*RewardValue {
+	if x != nil {
+		return x.Rewards
+	}
+	return nil
+}
+
+func (x *SPNData) GetChainStructure() *ChainStructure {
+	if x != nil {
+		return x.ChainStructure
+	}
+	return nil
+}
+
+func (x *SPNData) GetSolverStats() *SolverStats {
+	if x != nil {
+		return x.SolverStats
+	}
+	return nil
+}
+
+func (x *SPNData) GetSimulation() *Simulation {
+	if x != nil {
+		return x.Simulation
+	}
+	return nil
+}
+
 type MarkingDensity struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Densities     []float64              `protobuf:"fixed64,1,rep,packed,name=densities,proto3" json:"densities,omitempty"`
@@ -330,7 +566,7 @@ type MarkingDensity struct {
 
 func (x *MarkingDensity) Reset() {
 	*x = MarkingDensity{}
-	mi := &file_internal_pkg_spn_spn_proto_msgTypes[5]
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -342,7 +578,7 @@ func (x *MarkingDensity) String() string {
 func (*MarkingDensity) ProtoMessage() {}
 
 func (x *MarkingDensity) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_spn_spn_proto_msgTypes[5]
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -355,7 +591,7 @@ func (x *MarkingDensity) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MarkingDensity.ProtoReflect.Descriptor instead.
 func (*MarkingDensity) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{5}
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *MarkingDensity) GetDensities() []float64 {
@@ -365,33 +601,981 @@ func (x *MarkingDensity) GetDensities() []float64 {
 	return nil
 }
 
-var File_internal_pkg_spn_spn_proto protoreflect.FileDescriptor
+type Invariants struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PSemiflows    []*Semiflow            `protobuf:"bytes,1,rep,name=p_semiflows,json=pSemiflows,proto3" json:"p_semiflows,omitempty"`
+	TSemiflows    []*Semiflow            `protobuf:"bytes,2,rep,name=t_semiflows,json=tSemiflows,proto3" json:"t_semiflows,omitempty"`
+	Conservative  bool                   `protobuf:"varint,3,opt,name=conservative,proto3" json:"conservative,omitempty"`
+	Consistent    bool                   `protobuf:"varint,4,opt,name=consistent,proto3" json:"consistent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_internal_pkg_spn_spn_proto_rawDesc = "" +
-	"\n" +
-	"\x1ainternal/pkg/spn/spn.proto\x12\x03spn\"\\\n" +
-	"\bPetriNet\x12\x16\n" +
-	"\x06matrix\x18\x01 \x03(\x05R\x06matrix\x12\x16\n" +
-	"\x06places\x18\x02 \x01(\x05R\x06places\x12 \n" +
-	"\vtransitions\x18\x03 \x01(\x05R\vtransitions\"\x86\x01\n" +
-	"\x11ReachabilityGraph\x12'\n" +
-	"\bvertices\x18\x01 \x03(\v2\v.spn.VertexR\bvertices\x12\x1f\n" +
-	"\x05edges\x18\x02 \x03(\v2\t.spn.EdgeR\x05edges\x12'\n" +
-	"\x0farc_transitions\x18\x03 \x03(\x05R\x0earcTransitions\"\"\n" +
-	"\x06Vertex\x12\x18\n" +
-	"\amarking\x18\x01 \x03(\x05R\amarking\",\n" +
-	"\x04Edge\x12\x10\n" +
-	"\x03src\x18\x01 \x01(\x05R\x03src\x12\x12\n" +
-	"\x04dest\x18\x02 \x01(\x05R\x04dest\"\xbc\x02\n" +
-	"\aSPNData\x12*\n" +
-	"\tpetri_net\x18\x01 \x01(\v2\r.spn.PetriNetR\bpetriNet\x12E\n" +
-	"\x12reachability_graph\x18\x02 \x01(\v2\x16.spn.ReachabilityGraphR\x11reachabilityGraph\x12#\n" +
-	"\rlambda_values\x18\x03 \x03(\x01R\flambdaValues\x12,\n" +
-	"\x12steady_state_probs\x18\x04 \x03(\x01R\x10steadyStateProbs\x12)\n" +
-	"\x10average_markings\x18\x05 \x03(\x01R\x0faverageMarkings\x12@\n" +
-	"\x11marking_densities\x18\x06 \x03(\v2\x13.spn.MarkingDensityR\x10markingDensities\".\n" +
-	"\x0eMarkingDensity\x12\x1c\n" +
-	"\tdensities\x18\x01 \x03(\x01R\tdensitiesB#Z!spn-benchmark-ds/internal/pkg/spnb\x06proto3"
+func (x *Invariants) Reset() {
+	*x = Invariants{}
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Invariants) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Invariants) ProtoMessage() {}
+
+func (x *Invariants) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Invariants.ProtoReflect.Descriptor instead.
+func (*Invariants) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Invariants) GetPSemiflows() []*Semiflow {
+	if x != nil {
+		return x.PSemiflows
+	}
+	return nil
+}
+
+func (x *Invariants) GetTSemiflows() []*Semiflow {
+	if x != nil {
+		return x.TSemiflows
+	}
+	return nil
+}
+
+func (x *Invariants) GetConservative() bool {
+	if x != nil {
+		return x.Conservative
+	}
+	return false
+}
+
+func (x *Invariants) GetConsistent() bool {
+	if x != nil {
+		return x.Consistent
+	}
+	return false
+}
+
+type Semiflow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Weights       []int32                `protobuf:"varint,1,rep,packed,name=weights,proto3" json:"weights,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Semiflow) Reset() {
+	*x = Semiflow{}
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Semiflow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Semiflow) ProtoMessage() {}
+
+func (x *Semiflow) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Semiflow.ProtoReflect.Descriptor instead.
+func (*Semiflow) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Semiflow) GetWeights() []int32 {
+	if x != nil {
+		return x.Weights
+	}
+	return nil
+}
+
+type SiphonsAndTraps struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	MinimalSiphons   []*PlaceSet            `protobuf:"bytes,1,rep,name=minimal_siphons,json=minimalSiphons,proto3" json:"minimal_siphons,omitempty"`
+	MinimalTraps     []*PlaceSet            `protobuf:"bytes,2,rep,name=minimal_traps,json=minimalTraps,proto3" json:"minimal_traps,omitempty"`
+	Ordinary         bool                   `protobuf:"varint,3,opt,name=ordinary,proto3" json:"ordinary,omitempty"`
+	FreeChoice       bool                   `protobuf:"varint,4,opt,name=free_choice,json=freeChoice,proto3" json:"free_choice,omitempty"`
+	CommonerProperty bool                   `protobuf:"varint,5,opt,name=commoner_property,json=commonerProperty,proto3" json:"commoner_property,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SiphonsAndTraps) Reset() {
+	*x = SiphonsAndTraps{}
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SiphonsAndTraps) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SiphonsAndTraps) ProtoMessage() {}
+
+func (x *SiphonsAndTraps) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SiphonsAndTraps.ProtoReflect.Descriptor instead.
+func (*SiphonsAndTraps) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SiphonsAndTraps) GetMinimalSiphons() []*PlaceSet {
+	if x != nil {
+		return x.MinimalSiphons
+	}
+	return nil
+}
+
+func (x *SiphonsAndTraps) GetMinimalTraps() []*PlaceSet {
+	if x != nil {
+		return x.MinimalTraps
+	}
+	return nil
+}
+
+func (x *SiphonsAndTraps) GetOrdinary() bool {
+	if x != nil {
+		return x.Ordinary
+	}
+	return false
+}
+
+func (x *SiphonsAndTraps) GetFreeChoice() bool {
+	if x != nil {
+		return x.FreeChoice
+	}
+	return false
+}
+
+func (x *SiphonsAndTraps) GetCommonerProperty() bool {
+	if x != nil {
+		return x.CommonerProperty
+	}
+	return false
+}
+
+type PlaceSet struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Places        []int32                `protobuf:"varint,1,rep,packed,name=places,proto3" json:"places,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlaceSet) Reset() {
+	*x = PlaceSet{}
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaceSet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaceSet) ProtoMessage() {}
+
+func (x *PlaceSet) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaceSet.ProtoReflect.Descriptor instead.
+func (*PlaceSet) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PlaceSet) GetPlaces() []int32 {
+	if x != nil {
+		return x.Places
+	}
+	return nil
+}
+
+type Transient struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Points        []*TransientPoint      `protobuf:"bytes,1,rep,name=points,proto3" json:"points,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Transient) Reset() {
+	*x = Transient{}
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transient) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transient) ProtoMessage() {}
+
+func (x *Transient) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transient.ProtoReflect.Descriptor instead.
+func (*Transient) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Transient) GetPoints() []*TransientPoint {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+type TransientPoint struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Time            float64                `protobuf:"fixed64,1,opt,name=time,proto3" json:"time,omitempty"`
+	Probs           []float64              `protobuf:"fixed64,2,rep,packed,name=probs,proto3" json:"probs,omitempty"`
+	AverageMarkings []float64              `protobuf:"fixed64,3,rep,packed,name=average_markings,json=averageMarkings,proto3" json:"average_markings,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TransientPoint) Reset() {
+	*x = TransientPoint{}
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransientPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransientPoint) ProtoMessage() {}
+
+func (x *TransientPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransientPoint.ProtoReflect.Descriptor instead.
+func (*TransientPoint) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TransientPoint) GetTime() float64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *TransientPoint) GetProbs() []float64 {
+	if x != nil {
+		return x.Probs
+	}
+	return nil
+}
+
+func (x *TransientPoint) GetAverageMarkings() []float64 {
+	if x != nil {
+		return x.AverageMarkings
+	}
+	return nil
+}
+
+type ResponseTime struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Place         int32                  `protobuf:"varint,1,opt,name=place,proto3" json:"place,omitempty"`
+	Transition    int32                  `protobuf:"varint,2,opt,name=transition,proto3" json:"transition,omitempty"`
+	ResponseTime  float64                `protobuf:"fixed64,3,opt,name=response_time,json=responseTime,proto3" json:"response_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResponseTime) Reset() {
+	*x = ResponseTime{}
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResponseTime) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResponseTime) ProtoMessage() {}
+
+func (x *ResponseTime) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResponseTime.ProtoReflect.Descriptor instead.
+func (*ResponseTime) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ResponseTime) GetPlace() int32 {
+	if x != nil {
+		return x.Place
+	}
+	return 0
+}
+
+func (x *ResponseTime) GetTransition() int32 {
+	if x != nil {
+		return x.Transition
+	}
+	return 0
+}
+
+func (x *ResponseTime) GetResponseTime() float64 {
+	if x != nil {
+		return x.ResponseTime
+	}
+	return 0
+}
+
+type RewardValue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	SteadyState   float64                `protobuf:"fixed64,2,opt,name=steady_state,json=steadyState,proto3" json:"steady_state,omitempty"`
+	Transient     []float64              `protobuf:"fixed64,3,rep,packed,name=transient,proto3" json:"transient,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RewardValue) Reset() {
+	*x = RewardValue{}
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RewardValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RewardValue) ProtoMessage() {}
+
+func (x *RewardValue) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RewardValue.ProtoReflect.Descriptor instead.
+func (*RewardValue) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RewardValue) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RewardValue) GetSteadyState() float64 {
+	if x != nil {
+		return x.SteadyState
+	}
+	return 0
+}
+
+func (x *RewardValue) GetTransient() []float64 {
+	if x != nil {
+		return x.Transient
+	}
+	return nil
+}
+
+type ChainStructure struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	NumComponents           int32                  `protobuf:"varint,1,opt,name=num_components,json=numComponents,proto3" json:"num_components,omitempty"`
+	BottomComponents        []*MarkingSet          `protobuf:"bytes,2,rep,name=bottom_components,json=bottomComponents,proto3" json:"bottom_components,omitempty"`
+	DeadMarkings            []int32                `protobuf:"varint,3,rep,packed,name=dead_markings,json=deadMarkings,proto3" json:"dead_markings,omitempty"`
+	AbsorptionProbabilities []float64              `protobuf:"fixed64,4,rep,packed,name=absorption_probabilities,json=absorptionProbabilities,proto3" json:"absorption_probabilities,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *ChainStructure) Reset() {
+	*x = ChainStructure{}
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChainStructure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChainStructure) ProtoMessage() {}
+
+func (x *ChainStructure) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChainStructure.ProtoReflect.Descriptor instead.
+func (*ChainStructure) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ChainStructure) GetNumComponents() int32 {
+	if x != nil {
+		return x.NumComponents
+	}
+	return 0
+}
+
+func (x *ChainStructure) GetBottomComponents() []*MarkingSet {
+	if x != nil {
+		return x.BottomComponents
+	}
+	return nil
+}
+
+func (x *ChainStructure) GetDeadMarkings() []int32 {
+	if x != nil {
+		return x.DeadMarkings
+	}
+	return nil
+}
+
+func (x *ChainStructure) GetAbsorptionProbabilities() []float64 {
+	if x != nil {
+		return x.AbsorptionProbabilities
+	}
+	return nil
+}
+
+type MarkingSet struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Markings      []int32                `protobuf:"varint,1,rep,packed,name=markings,proto3" json:"markings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MarkingSet) Reset() {
+	*x = MarkingSet{}
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarkingSet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkingSet) ProtoMessage() {}
+
+func (x *MarkingSet) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkingSet.ProtoReflect.Descriptor instead.
+func (*MarkingSet) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *MarkingSet) GetMarkings() []int32 {
+	if x != nil {
+		return x.Markings
+	}
+	return nil
+}
+
+type SolverStats struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Method          string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	Iterations      int32                  `protobuf:"varint,2,opt,name=iterations,proto3" json:"iterations,omitempty"`
+	Residual        float64                `protobuf:"fixed64,3,opt,name=residual,proto3" json:"residual,omitempty"`
+	Converged       bool                   `protobuf:"varint,4,opt,name=converged,proto3" json:"converged,omitempty"`
+	ClampedMass     float64                `protobuf:"fixed64,5,opt,name=clamped_mass,json=clampedMass,proto3" json:"clamped_mass,omitempty"`
+	ConditionNumber float64                `protobuf:"fixed64,6,opt,name=condition_number,json=conditionNumber,proto3" json:"condition_number,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SolverStats) Reset() {
+	*x = SolverStats{}
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SolverStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SolverStats) ProtoMessage() {}
+
+func (x *SolverStats) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SolverStats.ProtoReflect.Descriptor instead.
+func (*SolverStats) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SolverStats) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *SolverStats) GetIterations() int32 {
+	if x != nil {
+		return x.Iterations
+	}
+	return 0
+}
+
+func (x *SolverStats) GetResidual() float64 {
+	if x != nil {
+		return x.Residual
+	}
+	return 0
+}
+
+func (x *SolverStats) GetConverged() bool {
+	if x != nil {
+		return x.Converged
+	}
+	return false
+}
+
+func (x *SolverStats) GetClampedMass() float64 {
+	if x != nil {
+		return x.ClampedMass
+	}
+	return 0
+}
+
+func (x *SolverStats) GetConditionNumber() float64 {
+	if x != nil {
+		return x.ConditionNumber
+	}
+	return 0
+}
+
+type Simulation struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	AverageMarkings  []*Estimate            `protobuf:"bytes,1,rep,name=average_markings,json=averageMarkings,proto3" json:"average_markings,omitempty"`
+	Throughputs      []*Estimate            `protobuf:"bytes,2,rep,name=throughputs,proto3" json:"throughputs,omitempty"`
+	MarkingDensities []*EstimateList        `protobuf:"bytes,3,rep,name=marking_densities,json=markingDensities,proto3" json:"marking_densities,omitempty"`
+	Time             float64                `protobuf:"fixed64,4,opt,name=time,proto3" json:"time,omitempty"`
+	Firings          int64                  `protobuf:"varint,5,opt,name=firings,proto3" json:"firings,omitempty"`
+	Batches          int32                  `protobuf:"varint,6,opt,name=batches,proto3" json:"batches,omitempty"`
+	Confidence       float64                `protobuf:"fixed64,7,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Simulation) Reset() {
+	*x = Simulation{}
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Simulation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Simulation) ProtoMessage() {}
+
+func (x *Simulation) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Simulation.ProtoReflect.Descriptor instead.
+func (*Simulation) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Simulation) GetAverageMarkings() []*Estimate {
+	if x != nil {
+		return x.AverageMarkings
+	}
+	return nil
+}
+
+func (x *Simulation) GetThroughputs() []*Estimate {
+	if x != nil {
+		return x.Throughputs
+	}
+	return nil
+}
+
+func (x *Simulation) GetMarkingDensities() []*EstimateList {
+	if x != nil {
+		return x.MarkingDensities
+	}
+	return nil
+}
+
+func (x *Simulation) GetTime() float64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *Simulation) GetFirings() int64 {
+	if x != nil {
+		return x.Firings
+	}
+	return 0
+}
+
+func (x *Simulation) GetBatches() int32 {
+	if x != nil {
+		return x.Batches
+	}
+	return 0
+}
+
+func (x *Simulation) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+type Estimate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mean          float64                `protobuf:"fixed64,1,opt,name=mean,proto3" json:"mean,omitempty"`
+	HalfWidth     float64                `protobuf:"fixed64,2,opt,name=half_width,json=halfWidth,proto3" json:"half_width,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Estimate) Reset() {
+	*x = Estimate{}
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Estimate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Estimate) ProtoMessage() {}
+
+func (x *Estimate) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Estimate.ProtoReflect.Descriptor instead.
+func (*Estimate) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Estimate) GetMean() float64 {
+	if x != nil {
+		return x.Mean
+	}
+	return 0
+}
+
+func (x *Estimate) GetHalfWidth() float64 {
+	if x != nil {
+		return x.HalfWidth
+	}
+	return 0
+}
+
+type EstimateList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Estimates     []*Estimate            `protobuf:"bytes,1,rep,name=estimates,proto3" json:"estimates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EstimateList) Reset() {
+	*x = EstimateList{}
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EstimateList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EstimateList) ProtoMessage() {}
+
+func (x *EstimateList) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_spn_spn_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EstimateList.ProtoReflect.Descriptor instead.
+func (*EstimateList) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_spn_spn_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *EstimateList) GetEstimates() []*Estimate {
+	if x != nil {
+		return x.Estimates
+	}
+	return nil
+}
+
+var File_internal_pkg_spn_spn_proto protoreflect.FileDescriptor
+
+const file_internal_pkg_spn_spn_proto_rawDesc = "" +
+	"\n" +
+	"\x1ainternal/pkg/spn/spn.proto\x12\x03spn\"\xa1\x02\n" +
+	"\bPetriNet\x12\x16\n" +
+	"\x06matrix\x18\x01 \x03(\x05R\x06matrix\x12\x16\n" +
+	"\x06places\x18\x02 \x01(\x05R\x06places\x12 \n" +
+	"\vtransitions\x18\x03 \x01(\x05R\vtransitions\x12\x1c\n" +
+	"\timmediate\x18\x04 \x03(\bR\timmediate\x12\x18\n" +
+	"\aweights\x18\x05 \x03(\x01R\aweights\x12\x1e\n" +
+	"\n" +
+	"priorities\x18\x06 \x03(\x05R\n" +
+	"priorities\x12\x1e\n" +
+	"\n" +
+	"inhibitors\x18\a \x03(\x05R\n" +
+	"inhibitors\x12\"\n" +
+	"\x06delays\x18\b \x03(\v2\n" +
+	".spn.DelayR\x06delays\x12'\n" +
+	"\x0finitial_marking\x18\t \x03(\x05R\x0einitialMarking\"]\n" +
+	"\x05Delay\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x16\n" +
+	"\x06stages\x18\x02 \x01(\x05R\x06stages\x12\x10\n" +
+	"\x03scv\x18\x03 \x01(\x01R\x03scv\x12\x16\n" +
+	"\x06spread\x18\x04 \x01(\x01R\x06spread\"\xa3\x02\n" +
+	"\x11ReachabilityGraph\x12'\n" +
+	"\bvertices\x18\x01 \x03(\v2\v.spn.VertexR\bvertices\x12\x1f\n" +
+	"\x05edges\x18\x02 \x03(\v2\t.spn.EdgeR\x05edges\x12'\n" +
+	"\x0farc_transitions\x18\x03 \x03(\x05R\x0earcTransitions\x12+\n" +
+	"\x11arc_probabilities\x18\x04 \x03(\x01R\x10arcProbabilities\x121\n" +
+	"\x14initial_distribution\x18\x05 \x03(\x01R\x13initialDistribution\x12\x1d\n" +
+	"\n" +
+	"is_bounded\x18\x06 \x01(\bR\tisBounded\x12\x1c\n" +
+	"\tvanishing\x18\a \x03(\bR\tvanishing\"\"\n" +
+	"\x06Vertex\x12\x18\n" +
+	"\amarking\x18\x01 \x03(\x05R\amarking\",\n" +
+	"\x04Edge\x12\x10\n" +
+	"\x03src\x18\x01 \x01(\x05R\x03src\x12\x12\n" +
+	"\x04dest\x18\x02 \x01(\x05R\x04dest\"\xd8\x06\n" +
+	"\aSPNData\x12*\n" +
+	"\tpetri_net\x18\x01 \x01(\v2\r.spn.PetriNetR\bpetriNet\x12E\n" +
+	"\x12reachability_graph\x18\x02 \x01(\v2\x16.spn.ReachabilityGraphR\x11reachabilityGraph\x12#\n" +
+	"\rlambda_values\x18\x03 \x03(\x01R\flambdaValues\x12,\n" +
+	"\x12steady_state_probs\x18\x04 \x03(\x01R\x10steadyStateProbs\x12)\n" +
+	"\x10average_markings\x18\x05 \x03(\x01R\x0faverageMarkings\x12@\n" +
+	"\x11marking_densities\x18\x06 \x03(\v2\x13.spn.MarkingDensityR\x10markingDensities\x12/\n" +
+	"\n" +
+	"invariants\x18\a \x01(\v2\x0f.spn.InvariantsR\n" +
+	"invariants\x12@\n" +
+	"\x11siphons_and_traps\x18\b \x01(\v2\x14.spn.SiphonsAndTrapsR\x0fsiphonsAndTraps\x12,\n" +
+	"\ttransient\x18\t \x01(\v2\x0e.spn.TransientR\ttransient\x12 \n" +
+	"\vthroughputs\x18\n" +
+	" \x03(\x01R\vthroughputs\x12(\n" +
+	"\x10token_flow_rates\x18\v \x03(\x01R\x0etokenFlowRates\x12#\n" +
+	"\rsojourn_times\x18\f \x03(\x01R\fsojournTimes\x128\n" +
+	"\x0eresponse_times\x18\r \x03(\v2\x11.spn.ResponseTimeR\rresponseTimes\x12*\n" +
+	"\arewards\x18\x0e \x03(\v2\x10.spn.RewardValueR\arewards\x12<\n" +
+	"\x0fchain_structure\x18\x0f \x01(\v2\x13.spn.ChainStructureR\x0echainStructure\x123\n" +
+	"\fsolver_stats\x18\x10 \x01(\v2\x10.spn.SolverStatsR\vsolverStats\x12/\n" +
+	"\n" +
+	"simulation\x18\x11 \x01(\v2\x0f.spn.SimulationR\n" +
+	"simulation\".\n" +
+	"\x0eMarkingDensity\x12\x1c\n" +
+	"\tdensities\x18\x01 \x03(\x01R\tdensities\"\xb0\x01\n" +
+	"\n" +
+	"Invariants\x12.\n" +
+	"\vp_semiflows\x18\x01 \x03(\v2\r.spn.SemiflowR\n" +
+	"pSemiflows\x12.\n" +
+	"\vt_semiflows\x18\x02 \x03(\v2\r.spn.SemiflowR\n" +
+	"tSemiflows\x12\"\n" +
+	"\fconservative\x18\x03 \x01(\bR\fconservative\x12\x1e\n" +
+	"\n" +
+	"consistent\x18\x04 \x01(\bR\n" +
+	"consistent\"$\n" +
+	"\bSemiflow\x12\x18\n" +
+	"\aweights\x18\x01 \x03(\x05R\aweights\"\xe7\x01\n" +
+	"\x0fSiphonsAndTraps\x126\n" +
+	"\x0fminimal_siphons\x18\x01 \x03(\v2\r.spn.PlaceSetR\x0eminimalSiphons\x122\n" +
+	"\rminimal_traps\x18\x02 \x03(\v2\r.spn.PlaceSetR\fminimalTraps\x12\x1a\n" +
+	"\bordinary\x18\x03 \x01(\bR\bordinary\x12\x1f\n" +
+	"\vfree_choice\x18\x04 \x01(\bR\n" +
+	"freeChoice\x12+\n" +
+	"\x11commoner_property\x18\x05 \x01(\bR\x10commonerProperty\"\"\n" +
+	"\bPlaceSet\x12\x16\n" +
+	"\x06places\x18\x01 \x03(\x05R\x06places\"8\n" +
+	"\tTransient\x12+\n" +
+	"\x06points\x18\x01 \x03(\v2\x13.spn.TransientPointR\x06points\"e\n" +
+	"\x0eTransientPoint\x12\x12\n" +
+	"\x04time\x18\x01 \x01(\x01R\x04time\x12\x14\n" +
+	"\x05probs\x18\x02 \x03(\x01R\x05probs\x12)\n" +
+	"\x10average_markings\x18\x03 \x03(\x01R\x0faverageMarkings\"i\n" +
+	"\fResponseTime\x12\x14\n" +
+	"\x05place\x18\x01 \x01(\x05R\x05place\x12\x1e\n" +
+	"\n" +
+	"transition\x18\x02 \x01(\x05R\n" +
+	"transition\x12#\n" +
+	"\rresponse_time\x18\x03 \x01(\x01R\fresponseTime\"b\n" +
+	"\vRewardValue\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12!\n" +
+	"\fsteady_state\x18\x02 \x01(\x01R\vsteadyState\x12\x1c\n" +
+	"\ttransient\x18\x03 \x03(\x01R\ttransient\"\xd5\x01\n" +
+	"\x0eChainStructure\x12%\n" +
+	"\x0enum_components\x18\x01 \x01(\x05R\rnumComponents\x12<\n" +
+	"\x11bottom_components\x18\x02 \x03(\v2\x0f.spn.MarkingSetR\x10bottomComponents\x12#\n" +
+	"\rdead_markings\x18\x03 \x03(\x05R\fdeadMarkings\x129\n" +
+	"\x18absorption_probabilities\x18\x04 \x03(\x01R\x17absorptionProbabilities\"(\n" +
+	"\n" +
+	"MarkingSet\x12\x1a\n" +
+	"\bmarkings\x18\x01 \x03(\x05R\bmarkings\"\xcd\x01\n" +
+	"\vSolverStats\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x1e\n" +
+	"\n" +
+	"iterations\x18\x02 \x01(\x05R\n" +
+	"iterations\x12\x1a\n" +
+	"\bresidual\x18\x03 \x01(\x01R\bresidual\x12\x1c\n" +
+	"\tconverged\x18\x04 \x01(\bR\tconverged\x12!\n" +
+	"\fclamped_mass\x18\x05 \x01(\x01R\vclampedMass\x12)\n" +
+	"\x10condition_number\x18\x06 \x01(\x01R\x0fconditionNumber\"\x9f\x02\n" +
+	"\n" +
+	"Simulation\x128\n" +
+	"\x10average_markings\x18\x01 \x03(\v2\r.spn.EstimateR\x0faverageMarkings\x12/\n" +
+	"\vthroughputs\x18\x02 \x03(\v2\r.spn.EstimateR\vthroughputs\x12>\n" +
+	"\x11marking_densities\x18\x03 \x03(\v2\x11.spn.EstimateListR\x10markingDensities\x12\x12\n" +
+	"\x04time\x18\x04 \x01(\x01R\x04time\x12\x18\n" +
+	"\afirings\x18\x05 \x01(\x03R\afirings\x12\x18\n" +
+	"\abatches\x18\x06 \x01(\x05R\abatches\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\a \x01(\x01R\n" +
+	"confidence\"=\n" +
+	"\bEstimate\x12\x12\n" +
+	"\x04mean\x18\x01 \x01(\x01R\x04mean\x12\x1d\n" +
+	"\n" +
+	"half_width\x18\x02 \x01(\x01R\thalfWidth\";\n" +
+	"\fEstimateList\x12+\n" +
+	"\testimates\x18\x01 \x03(\v2\r.spn.EstimateR\testimatesB#Z!spn-benchmark-ds/internal/pkg/spnb\x06proto3"
 
 var (
 	file_internal_pkg_spn_spn_proto_rawDescOnce sync.Once
@@ -405,26 +1589,60 @@ func file_internal_pkg_spn_spn_proto_rawDescGZIP() []byte {
 	return file_internal_pkg_spn_spn_proto_rawDescData
 }
 
-var file_internal_pkg_spn_spn_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_internal_pkg_spn_spn_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
 var file_internal_pkg_spn_spn_proto_goTypes = []any{
 	(*PetriNet)(nil),          // 0: spn.PetriNet
-	(*ReachabilityGraph)(nil), // 1: spn.ReachabilityGraph
-	(*Vertex)(nil),            // 2: spn.Vertex
-	(*Edge)(nil),              // 3: spn.Edge
-	(*SPNData)(nil),           // 4: spn.SPNData
-	(*MarkingDensity)(nil),    // 5: spn.MarkingDensity
+	(*Delay)(nil),             // 1: spn.Delay
+	(*ReachabilityGraph)(nil), // 2: spn.ReachabilityGraph
+	(*Vertex)(nil),            // 3: spn.Vertex
+	(*Edge)(nil),              // 4: spn.Edge
+	(*SPNData)(nil),           // 5: spn.SPNData
+	(*MarkingDensity)(nil),    // 6: spn.MarkingDensity
+	(*Invariants)(nil),        // 7: spn.Invariants
+	(*Semiflow)(nil),          // 8: spn.Semiflow
+	(*SiphonsAndTraps)(nil),   // 9: spn.SiphonsAndTraps
+	(*PlaceSet)(nil),          // 10: spn.PlaceSet
+	(*Transient)(nil),         // 11: spn.Transient
+	(*TransientPoint)(nil),    // 12: spn.TransientPoint
+	(*ResponseTime)(nil),      // 13: spn.ResponseTime
+	(*RewardValue)(nil),       // 14: spn.RewardValue
+	(*ChainStructure)(nil),    // 15: spn.ChainStructure
+	(*MarkingSet)(nil),        // 16: spn.MarkingSet
+	(*SolverStats)(nil),       // 17: spn.SolverStats
+	(*Simulation)(nil),        // 18: spn.Simulation
+	(*Estimate)(nil),          // 19: spn.Estimate
+	(*EstimateList)(nil),      // 20: spn.EstimateList
 }
 var file_internal_pkg_spn_spn_proto_depIdxs = []int32{
-	2, // 0: spn.ReachabilityGraph.vertices:type_name -> spn.Vertex
-	3, // 1: spn.ReachabilityGraph.edges:type_name -> spn.Edge
-	0, // 2: spn.SPNData.petri_net:type_name -> spn.PetriNet
-	1, // 3: spn.SPNData.reachability_graph:type_name -> spn.ReachabilityGraph
-	5, // 4: spn.SPNData.marking_densities:type_name -> spn.MarkingDensity
-	5, // [5:5] is the sub-list for method output_type
-	5, // [5:5] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	1,  // 0: spn.PetriNet.delays:type_name -> spn.Delay
+	3,  // 1: spn.ReachabilityGraph.vertices:type_name -> spn.Vertex
+	4,  // 2: spn.ReachabilityGraph.edges:type_name -> spn.Edge
+	0,  // 3: spn.SPNData.petri_net:type_name -> spn.PetriNet
+	2,  // 4: spn.SPNData.reachability_graph:type_name -> spn.ReachabilityGraph
+	6,  // 5: spn.SPNData.marking_densities:type_name -> spn.MarkingDensity
+	7,  // 6: spn.SPNData.invariants:type_name -> spn.Invariants
+	9,  // 7: spn.SPNData.siphons_and_traps:type_name -> spn.SiphonsAndTraps
+	11, // 8: spn.SPNData.transient:type_name -> spn.Transient
+	13, // 9: spn.SPNData.response_times:type_name -> spn.ResponseTime
+	14, // 10: spn.SPNData.rewards:type_name -> spn.RewardValue
+	15, // 11: spn.SPNData.chain_structure:type_name -> spn.ChainStructure
+	17, // 12: spn.SPNData.solver_stats:type_name -> spn.SolverStats
+	18, // 13: spn.SPNData.simulation:type_name -> spn.Simulation
+	8,  // 14: spn.Invariants.p_semiflows:type_name -> spn.Semiflow
+	8,  // 15: spn.Invariants.t_semiflows:type_name -> spn.Semiflow
+	10, // 16: spn.SiphonsAndTraps.minimal_siphons:type_name -> spn.PlaceSet
+	10, // 17: spn.SiphonsAndTraps.minimal_traps:type_name -> spn.PlaceSet
+	12, // 18: spn.Transient.points:type_name -> spn.TransientPoint
+	16, // 19: spn.ChainStructure.bottom_components:type_name -> spn.MarkingSet
+	19, // 20: spn.Simulation.average_markings:type_name -> spn.Estimate
+	19, // 21: spn.Simulation.throughputs:type_name -> spn.Estimate
+	20, // 22: spn.Simulation.marking_densities:type_name -> spn.EstimateList
+	19, // 23: spn.EstimateList.estimates:type_name -> spn.Estimate
+	24, // [24:24] is the sub-list for method output_type
+	24, // [24:24] is the sub-list for method input_type
+	24, // [24:24] is the sub-list for extension type_name
+	24, // [24:24] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
 }
 
 func init() { file_internal_pkg_spn_spn_proto_init() }
@@ -438,7 +1656,7 @@ func file_internal_pkg_spn_spn_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_pkg_spn_spn_proto_rawDesc), len(file_internal_pkg_spn_spn_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
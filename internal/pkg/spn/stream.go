@@ -0,0 +1,187 @@
+package spn
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// A protobuf dataset starts with a fixed-size header: the magic bytes, the schema version and the
+// number of samples, both little-endian. The samples follow as SPNData messages, each prefixed
+// with its size as a varint, the framing of protodelim and of Java's writeDelimitedTo.
+const (
+	// SchemaVersion is the version of the dataset format written by Writer.
+	SchemaVersion uint32 = 1
+	// UnknownCount is the sample count of a dataset written to a stream that cannot seek back to
+	// its header. Readers then read samples until the end of the stream.
+	UnknownCount uint64 = math.MaxUint64
+	// headerSize is the size of the header in bytes.
+	headerSize = 16
+)
+
+// magic identifies a protobuf dataset.
+var magic = [4]byte{'S', 'P', 'N', 'D'}
+
+// ErrNotDataset is returned when a stream does not start with the header of a protobuf dataset.
+var ErrNotDataset = errors.New("not a protobuf SPN dataset")
+
+// Header is the header of a protobuf dataset.
+type Header struct {
+	// Version is the schema version the dataset was written with.
+	Version uint32
+	// Count is the number of samples, or UnknownCount.
+	Count uint64
+}
+
+// Encode returns the header in its binary form.
+func (h Header) Encode() []byte {
+	b := make([]byte, headerSize)
+	copy(b, magic[:])
+	binary.LittleEndian.PutUint32(b[4:], h.Version)
+	binary.LittleEndian.PutUint64(b[8:], h.Count)
+	return b
+}
+
+// AppendRecord appends a sample, prefixed with its size, to b.
+func AppendRecord(b []byte, data *SPNData) ([]byte, error) {
+	b = protowire.AppendVarint(b, uint64(proto.Size(data)))
+	return proto.MarshalOptions{}.MarshalAppend(b, data)
+}
+
+// Writer writes samples to a protobuf dataset.
+type Writer struct {
+	w      io.Writer
+	buf    *bufio.Writer
+	record []byte
+	start  int64
+	seeker io.WriteSeeker
+	count  uint64
+}
+
+// NewWriter writes the header of a dataset to w and returns a writer for its samples. If w can
+// seek, Close records the number of samples in the header; otherwise it is left as UnknownCount.
+func NewWriter(w io.Writer) (*Writer, error) {
+	writer := &Writer{w: w, buf: bufio.NewWriter(w)}
+	if seeker, ok := w.(io.WriteSeeker); ok {
+		// Pipes and terminals are files too, but fail to seek.
+		if start, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			writer.seeker, writer.start = seeker, start
+		}
+	}
+	if _, err := writer.buf.Write(Header{Version: SchemaVersion, Count: UnknownCount}.Encode()); err != nil {
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+	return writer, nil
+}
+
+// Write appends a sample to the dataset.
+func (w *Writer) Write(data *SPNData) error {
+	record, err := AppendRecord(w.record[:0], data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sample %d: %w", w.count, err)
+	}
+	w.record = record
+	if _, err := w.buf.Write(record); err != nil {
+		return fmt.Errorf("failed to write sample %d: %w", w.count, err)
+	}
+	w.count++
+	return nil
+}
+
+// Count returns the number of samples written so far.
+func (w *Writer) Count() uint64 {
+	return w.count
+}
+
+// Close flushes the samples and, if the underlying writer can seek, records their number in the
+// header. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if err := w.buf.Flush(); err != nil {
+		return fmt.Errorf("failed to flush samples: %w", err)
+	}
+	if w.seeker == nil {
+		return nil
+	}
+	end, err := w.seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to seek: %w", err)
+	}
+	if _, err := w.seeker.Seek(w.start, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek to header: %w", err)
+	}
+	if _, err := w.seeker.Write(Header{Version: SchemaVersion, Count: w.count}.Encode()); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	if _, err := w.seeker.Seek(end, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek: %w", err)
+	}
+	return nil
+}
+
+// Reader reads the samples of a protobuf dataset in order.
+type Reader struct {
+	r      *bufio.Reader
+	header Header
+	read   uint64
+}
+
+// NewReader reads the header of a dataset from r and returns a reader for its samples. It returns
+// ErrNotDataset if r does not start with a dataset header and an error for schema versions newer
+// than SchemaVersion.
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{r: bufio.NewReader(r)}
+	b := make([]byte, headerSize)
+	if _, err := io.ReadFull(reader.r, b); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrNotDataset
+		}
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	if !bytes.Equal(b[:4], magic[:]) {
+		return nil, ErrNotDataset
+	}
+	reader.header = Header{
+		Version: binary.LittleEndian.Uint32(b[4:]),
+		Count:   binary.LittleEndian.Uint64(b[8:]),
+	}
+	if reader.header.Version == 0 || reader.header.Version > SchemaVersion {
+		return nil, fmt.Errorf("unsupported schema version %d", reader.header.Version)
+	}
+	return reader, nil
+}
+
+// Header returns the header of the dataset.
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// Next returns the next sample, or io.EOF after the last one. A dataset that ends before the
+// number of samples in its header, or in the middle of a sample, is reported as
+// io.ErrUnexpectedEOF.
+func (r *Reader) Next() (*SPNData, error) {
+	if r.header.Count != UnknownCount && r.read == r.header.Count {
+		return nil, io.EOF
+	}
+	data := &SPNData{}
+	// Samples can hold large reachability graphs, so their size is not limited.
+	err := protodelim.UnmarshalOptions{MaxSize: -1}.UnmarshalFrom(r.r, data)
+	if err == io.EOF {
+		if r.header.Count != UnknownCount {
+			return nil, fmt.Errorf("dataset ends after %d of %d samples: %w", r.read, r.header.Count, io.ErrUnexpectedEOF)
+		}
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sample %d: %w", r.read, err)
+	}
+	r.read++
+	return data, nil
+}
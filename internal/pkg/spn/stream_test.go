@@ -0,0 +1,149 @@
+package spn
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// testSamples returns samples of different sizes, one of them beyond the 4 MiB default limit of
+// protodelim.
+func testSamples() []*SPNData {
+	large := make([]float64, 600000)
+	for i := range large {
+		large[i] = float64(i)
+	}
+	return []*SPNData{
+		{PetriNet: &PetriNet{Places: 2, Transitions: 1, Matrix: []int32{1, 0, 0, 1, 1, 0}}, LambdaValues: []float64{3}},
+		{},
+		{SteadyStateProbs: large},
+	}
+}
+
+// readAll reads every sample of a dataset.
+func readAll(t *testing.T, r io.Reader) (Header, []*SPNData) {
+	t.Helper()
+	reader, err := NewReader(r)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	var samples []*SPNData
+	for {
+		data, err := reader.Next()
+		if err == io.EOF {
+			return reader.Header(), samples
+		}
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		samples = append(samples, data)
+	}
+}
+
+func TestWriterAndReaderRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.pb")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	writer, err := NewWriter(file)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	for _, data := range testSamples() {
+		if err := writer.Write(data); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	file.Close()
+
+	file, err = os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	defer file.Close()
+	header, samples := readAll(t, file)
+	if header.Version != SchemaVersion || header.Count != 3 {
+		t.Errorf("expected a version %d header with 3 samples, got %+v", SchemaVersion, header)
+	}
+	expectSamples(t, samples, testSamples())
+}
+
+func TestWriterWithoutSeeking(t *testing.T) {
+	// A bytes.Buffer cannot seek back to the header, so the count stays unknown and the reader
+	// reads up to the end of the stream.
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	for _, data := range testSamples() {
+		if err := writer.Write(data); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if writer.Count() != 3 {
+		t.Errorf("expected 3 samples written, got %d", writer.Count())
+	}
+	header, samples := readAll(t, bytes.NewReader(buf.Bytes()))
+	if header.Count != UnknownCount {
+		t.Errorf("expected an unknown count, got %d", header.Count)
+	}
+	expectSamples(t, samples, testSamples())
+}
+
+func TestReaderErrors(t *testing.T) {
+	if _, err := NewReader(bytes.NewReader([]byte(`{"petri_net":{}}`))); !errors.Is(err, ErrNotDataset) {
+		t.Errorf("expected ErrNotDataset for JSON, got %v", err)
+	}
+	if _, err := NewReader(bytes.NewReader(nil)); !errors.Is(err, ErrNotDataset) {
+		t.Errorf("expected ErrNotDataset for an empty stream, got %v", err)
+	}
+	if _, err := NewReader(bytes.NewReader(Header{Version: SchemaVersion + 1}.Encode())); err == nil {
+		t.Errorf("expected an error for a newer schema version")
+	}
+
+	// The header promises two samples, but the stream holds one and a half.
+	data, err := proto.Marshal(testSamples()[0])
+	if err != nil {
+		t.Fatalf("failed to marshal sample: %v", err)
+	}
+	stream := append(Header{Version: SchemaVersion, Count: 2}.Encode(), byte(len(data)))
+	stream = append(stream, data...)
+	for _, truncated := range [][]byte{stream, append(stream, byte(len(data)), data[0])} {
+		reader, err := NewReader(bytes.NewReader(truncated))
+		if err != nil {
+			t.Fatalf("NewReader failed: %v", err)
+		}
+		if _, err := reader.Next(); err != nil {
+			t.Fatalf("expected the first sample, got %v", err)
+		}
+		if _, err := reader.Next(); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("expected io.ErrUnexpectedEOF for a truncated dataset, got %v", err)
+		}
+	}
+}
+
+// expectSamples checks that the samples read equal the samples written.
+func expectSamples(t *testing.T, got, expected []*SPNData) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d samples, got %d", len(expected), len(got))
+	}
+	for i := range expected {
+		if !proto.Equal(got[i], expected[i]) {
+			t.Errorf("sample %d differs from the sample written", i)
+		}
+	}
+}
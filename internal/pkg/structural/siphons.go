@@ -0,0 +1,260 @@
+package structural
+
+import (
+	"errors"
+	"slices"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+)
+
+// DefaultSetLimit is the bound on the subproblems explored by the siphon and trap enumeration used
+// when none is given.
+const DefaultSetLimit = 100000
+
+// ErrTooManySets is returned when the siphon or trap enumeration explores more subproblems than
+// allowed. The number of minimal siphons can grow exponentially with the size of the net.
+var ErrTooManySets = errors.New("too many subproblems in the siphon and trap enumeration")
+
+// SiphonsAndTraps holds the minimal siphons and traps of a Petri net and the deadlock properties
+// derived from them.
+type SiphonsAndTraps struct {
+	// MinimalSiphons holds the minimal siphons as sorted place indices. A siphon S satisfies
+	// •S ⊆ S•: once it is empty it stays empty, and every transition it feeds stays dead.
+	MinimalSiphons [][]int `json:"minimal_siphons"`
+	// MinimalTraps holds the minimal traps as sorted place indices. A trap Q satisfies Q• ⊆ •Q:
+	// once it is marked it stays marked.
+	MinimalTraps [][]int `json:"minimal_traps"`
+	// Ordinary is true when every arc has weight one.
+	Ordinary bool `json:"ordinary"`
+	// FreeChoice is true when every place with several output transitions is the only input place
+	// of each of them, so conflicts are never influenced by other places.
+	FreeChoice bool `json:"free_choice"`
+	// CommonerProperty is true when every siphon contains a trap marked at the initial marking.
+	// It implies deadlock freedom for ordinary nets without inhibitor arcs and, by Commoner's
+	// theorem, is equivalent to liveness for ordinary free-choice nets.
+	CommonerProperty bool `json:"commoner_property"`
+}
+
+// AnalyzeSiphonsAndTraps enumerates the minimal siphons and traps of a net and checks the Commoner
+// property. limit bounds the subproblems explored by each enumeration; zero or less selects
+// DefaultSetLimit.
+func AnalyzeSiphonsAndTraps(pn *petrinet.PetriNet, limit int) (*SiphonsAndTraps, error) {
+	siphons, err := MinimalSiphons(pn, limit)
+	if err != nil {
+		return nil, err
+	}
+	traps, err := MinimalTraps(pn, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &SiphonsAndTraps{
+		MinimalSiphons:   siphons,
+		MinimalTraps:     traps,
+		Ordinary:         IsOrdinary(pn),
+		FreeChoice:       IsFreeChoice(pn),
+		CommonerProperty: HasCommonerProperty(pn, siphons),
+	}, nil
+}
+
+// MinimalSiphons returns the minimal non-empty siphons of a net in lexicographic order.
+func MinimalSiphons(pn *petrinet.PetriNet, limit int) ([][]int, error) {
+	return minimalSets(pn, limit, maxSiphon)
+}
+
+// MinimalTraps returns the minimal non-empty traps of a net in lexicographic order.
+func MinimalTraps(pn *petrinet.PetriNet, limit int) ([][]int, error) {
+	return minimalSets(pn, limit, maxTrap)
+}
+
+// HasCommonerProperty reports whether each of the given siphons contains a trap marked at the
+// initial marking. Every siphon contains a minimal one, so checking the minimal siphons suffices.
+func HasCommonerProperty(pn *petrinet.PetriNet, siphons [][]int) bool {
+	for _, siphon := range siphons {
+		in := make([]bool, pn.Places)
+		for _, p := range siphon {
+			in[p] = true
+		}
+		// The maximal trap inside the siphon contains every trap inside it, so the siphon contains
+		// a marked trap exactly when the maximal one is marked.
+		marked := false
+		for p, ok := range maxTrap(pn, in) {
+			if ok && pn.InitialMarking[p] > 0 {
+				marked = true
+				break
+			}
+		}
+		if !marked {
+			return false
+		}
+	}
+	return true
+}
+
+// IsOrdinary reports whether every arc of the net has weight one.
+func IsOrdinary(pn *petrinet.PetriNet) bool {
+	for p := 0; p < pn.Places; p++ {
+		for j := 0; j < 2*pn.Transitions; j++ {
+			if w := pn.At(p, j); w != 0 && w != 1 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IsFreeChoice reports whether the net is free-choice: for every arc from p to t, either t is the
+// only output transition of p or p is the only input place of t.
+func IsFreeChoice(pn *petrinet.PetriNet) bool {
+	outputs := make([]int, pn.Places)
+	inputs := make([]int, pn.Transitions)
+	for p := 0; p < pn.Places; p++ {
+		for t := 0; t < pn.Transitions; t++ {
+			if pn.At(p, t) != 0 {
+				outputs[p]++
+				inputs[t]++
+			}
+		}
+	}
+	for p := 0; p < pn.Places; p++ {
+		for t := 0; t < pn.Transitions; t++ {
+			if pn.At(p, t) != 0 && outputs[p] > 1 && inputs[t] > 1 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// maxSiphon returns the largest siphon contained in the places marked in set, which is the union
+// of all siphons contained in it. Places fed by a transition that takes no input from the set are
+// removed until none is left.
+func maxSiphon(pn *petrinet.PetriNet, set []bool) []bool {
+	return maxSubset(pn, set, pn.Transitions, 0)
+}
+
+// maxTrap returns the largest trap contained in the places marked in set, which is the union of
+// all traps contained in it. Places feeding a transition that puts no token in the set are
+// removed until none is left.
+func maxTrap(pn *petrinet.PetriNet, set []bool) []bool {
+	return maxSubset(pn, set, 0, pn.Transitions)
+}
+
+// maxSubset removes from set every place p with an arc in matrix column in+t such that no place of
+// set has an arc in column out+t, until no place is removed.
+func maxSubset(pn *petrinet.PetriNet, set []bool, in, out int) []bool {
+	result := slices.Clone(set)
+	for changed := true; changed; {
+		changed = false
+		for p := 0; p < pn.Places; p++ {
+			if !result[p] {
+				continue
+			}
+			for t := 0; t < pn.Transitions; t++ {
+				if pn.At(p, in+t) == 0 {
+					continue
+				}
+				connected := false
+				for q := 0; q < pn.Places; q++ {
+					if result[q] && pn.At(q, out+t) != 0 {
+						connected = true
+						break
+					}
+				}
+				if !connected {
+					result[p] = false
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return result
+}
+
+// minimalSets enumerates the minimal non-empty fixed points of closure, which is maxSiphon or
+// maxTrap. A minimal set M is found inside the largest closed set; every other minimal set misses
+// some place of M, so the search continues on the place sets without each place of M in turn.
+func minimalSets(pn *petrinet.PetriNet, limit int, closure func(*petrinet.PetriNet, []bool) []bool) ([][]int, error) {
+	if limit <= 0 {
+		limit = DefaultSetLimit
+	}
+	var result [][]int
+	found := make(map[string]bool)
+	visited := make(map[string]bool)
+
+	all := make([]bool, pn.Places)
+	for p := range all {
+		all[p] = true
+	}
+	stack := [][]bool{all}
+	for len(stack) > 0 {
+		set := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		key := setKey(set)
+		if visited[key] {
+			continue
+		}
+		visited[key] = true
+		if len(visited) > limit {
+			return nil, ErrTooManySets
+		}
+
+		closed := closure(pn, set)
+		if isEmpty(closed) {
+			continue
+		}
+		// Shrink to a minimal set: a closed set is minimal when removing any of its places leaves
+		// no non-empty closed subset.
+		for p := 0; p < pn.Places; p++ {
+			if !closed[p] {
+				continue
+			}
+			closed[p] = false
+			if smaller := closure(pn, closed); !isEmpty(smaller) {
+				closed = smaller
+			} else {
+				closed[p] = true
+			}
+		}
+
+		if key := setKey(closed); !found[key] {
+			found[key] = true
+			var places []int
+			for p, ok := range closed {
+				if ok {
+					places = append(places, p)
+				}
+			}
+			result = append(result, places)
+		}
+		for p, ok := range closed {
+			if ok {
+				next := slices.Clone(set)
+				next[p] = false
+				stack = append(stack, next)
+			}
+		}
+	}
+	slices.SortFunc(result, slices.Compare[[]int])
+	return result, nil
+}
+
+// setKey returns a map key for a place set.
+func setKey(set []bool) string {
+	b := make([]byte, len(set))
+	for i, ok := range set {
+		if ok {
+			b[i] = 1
+		}
+	}
+	return string(b)
+}
+
+// isEmpty reports whether a place set is empty.
+func isEmpty(set []bool) bool {
+	for _, ok := range set {
+		if ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package structural
+
+import (
+	"math/rand"
+	"slices"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+	"testing"
+)
+
+func TestAnalyzeSiphonsAndTraps(t *testing.T) {
+	st, err := AnalyzeSiphonsAndTraps(newMutexNet(), 0)
+	if err != nil {
+		t.Fatalf("Error analyzing siphons and traps: %v", err)
+	}
+	expected := [][]int{{0, 1}, {1, 3, 4}, {2, 3}}
+	if !slices.EqualFunc(st.MinimalSiphons, expected, slices.Equal[[]int]) {
+		t.Errorf("Expected minimal siphons %v, got %v", expected, st.MinimalSiphons)
+	}
+	if !slices.EqualFunc(st.MinimalTraps, expected, slices.Equal[[]int]) {
+		t.Errorf("Expected minimal traps %v, got %v", expected, st.MinimalTraps)
+	}
+	if !st.Ordinary || st.FreeChoice || !st.CommonerProperty {
+		t.Errorf("Expected an ordinary, non free-choice net with the Commoner property, got %+v", st)
+	}
+}
+
+func TestCommonerPropertyFails(t *testing.T) {
+	// T1 moves the only token from P1 to P2, where T2 loops forever. {P1} is a siphon without any
+	// trap, so T1 is not live. {P2} is fed by T1 from outside, so it is a trap but not a siphon.
+	pn := petrinet.NewPetriNet(2, 2)
+	pn.Matrix = []int{
+		1, 0, 0, 0, 1,
+		0, 1, 1, 1, 0,
+	}
+	pn.InitialMarking = []int{1, 0}
+
+	st, err := AnalyzeSiphonsAndTraps(pn, 0)
+	if err != nil {
+		t.Fatalf("Error analyzing siphons and traps: %v", err)
+	}
+	if !slices.EqualFunc(st.MinimalSiphons, [][]int{{0}}, slices.Equal[[]int]) {
+		t.Errorf("Expected minimal siphons [[0]], got %v", st.MinimalSiphons)
+	}
+	if !slices.EqualFunc(st.MinimalTraps, [][]int{{1}}, slices.Equal[[]int]) {
+		t.Errorf("Expected minimal traps [[1]], got %v", st.MinimalTraps)
+	}
+	if !st.FreeChoice || st.CommonerProperty {
+		t.Errorf("Expected a free-choice net without the Commoner property, got %+v", st)
+	}
+}
+
+// bruteForceMinimal returns the minimal non-empty place sets satisfying isClosed, in
+// lexicographic order, by checking every subset.
+func bruteForceMinimal(n int, isClosed func(set []bool) bool) [][]int {
+	var closed [][]bool
+	for mask := 1; mask < 1<<n; mask++ {
+		set := make([]bool, n)
+		for p := range set {
+			set[p] = mask&(1<<p) != 0
+		}
+		if isClosed(set) {
+			closed = append(closed, set)
+		}
+	}
+	var result [][]int
+	for _, s := range closed {
+		minimal := true
+		for _, o := range closed {
+			if !slices.Equal(s, o) && contains(s, o) {
+				minimal = false
+				break
+			}
+		}
+		if minimal {
+			var places []int
+			for p, ok := range s {
+				if ok {
+					places = append(places, p)
+				}
+			}
+			result = append(result, places)
+		}
+	}
+	slices.SortFunc(result, slices.Compare[[]int])
+	return result
+}
+
+// contains reports whether place set a contains place set b.
+func contains(a, b []bool) bool {
+	for p := range b {
+		if b[p] && !a[p] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMinimalSiphonsAndTrapsMatchBruteForce(t *testing.T) {
+	for seed := int64(1); seed <= 30; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		pn := petrinet.GenerateRandomPetriNet(rng, 7, 5)
+		pn.Prune(rng)
+
+		// A siphon S satisfies •S ⊆ S•, a trap Q satisfies Q• ⊆ •Q.
+		isClosed := func(set []bool, in, out int) bool {
+			for t := 0; t < pn.Transitions; t++ {
+				touches, reaches := false, false
+				for p := range set {
+					if set[p] && pn.At(p, in+t) != 0 {
+						touches = true
+					}
+					if set[p] && pn.At(p, out+t) != 0 {
+						reaches = true
+					}
+				}
+				if touches && !reaches {
+					return false
+				}
+			}
+			return true
+		}
+
+		siphons, err := MinimalSiphons(pn, 0)
+		if err != nil {
+			t.Fatalf("seed %d: error enumerating siphons: %v", seed, err)
+		}
+		expected := bruteForceMinimal(pn.Places, func(set []bool) bool { return isClosed(set, pn.Transitions, 0) })
+		if !slices.EqualFunc(siphons, expected, slices.Equal[[]int]) {
+			t.Errorf("seed %d: expected minimal siphons %v, got %v", seed, expected, siphons)
+		}
+
+		traps, err := MinimalTraps(pn, 0)
+		if err != nil {
+			t.Fatalf("seed %d: error enumerating traps: %v", seed, err)
+		}
+		expected = bruteForceMinimal(pn.Places, func(set []bool) bool { return isClosed(set, 0, pn.Transitions) })
+		if !slices.EqualFunc(traps, expected, slices.Equal[[]int]) {
+			t.Errorf("seed %d: expected minimal traps %v, got %v", seed, expected, traps)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+// Package structural implements the structural analysis of Petri nets, which derives properties
+// from the incidence matrix alone, without exploring the state space.
+package structural
+
+import (
+	"errors"
+	"slices"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+)
+
+// DefaultMaxRows is the bound on intermediate Farkas rows used when none is given.
+const DefaultMaxRows = 10000
+
+// ErrTooManyRows is returned when the Farkas algorithm needs more intermediate rows than allowed.
+// The number of minimal semiflows can grow exponentially with the size of the net.
+var ErrTooManyRows = errors.New("too many intermediate rows in the Farkas algorithm")
+
+// Invariants holds the minimal semiflows of a Petri net.
+type Invariants struct {
+	// PSemiflows holds the minimal P-semiflows: non-negative place weightings y with yᵀC = 0, so
+	// the weighted token count y·M is the same in every reachable marking M.
+	PSemiflows [][]int `json:"p_semiflows"`
+	// TSemiflows holds the minimal T-semiflows: non-negative firing counts x with Cx = 0, so any
+	// firing sequence with these counts returns the net to the marking it started from.
+	TSemiflows [][]int `json:"t_semiflows"`
+	// Conservative is true when every place is covered by a P-semiflow, so there is a strictly
+	// positive weighting of the places that is invariant. Conservative nets are bounded.
+	Conservative bool `json:"conservative"`
+	// Consistent is true when every transition is covered by a T-semiflow, so there is a strictly
+	// positive firing count vector x with Cx = 0. It does not imply that a sequence with these
+	// counts can fire from the initial marking.
+	Consistent bool `json:"consistent"`
+}
+
+// IncidenceMatrix returns the places × transitions incidence matrix C = Post − Pre of a net,
+// as one row per place. Inhibitor arcs do not change markings and are not part of it.
+func IncidenceMatrix(pn *petrinet.PetriNet) [][]int {
+	c := make([][]int, pn.Places)
+	for p := range c {
+		c[p] = make([]int, pn.Transitions)
+		for t := range c[p] {
+			c[p][t] = pn.At(p, pn.Transitions+t) - pn.At(p, t)
+		}
+	}
+	return c
+}
+
+// ComputeInvariants computes the minimal P- and T-semiflows of a net and derives its
+// conservativeness and consistency. maxRows bounds the intermediate rows of each Farkas run;
+// zero or less selects DefaultMaxRows.
+func ComputeInvariants(pn *petrinet.PetriNet, maxRows int) (*Invariants, error) {
+	if maxRows <= 0 {
+		maxRows = DefaultMaxRows
+	}
+	c := IncidenceMatrix(pn)
+	pSemiflows, err := Semiflows(c, maxRows)
+	if err != nil {
+		return nil, err
+	}
+	tSemiflows, err := Semiflows(transpose(c, pn.Transitions), maxRows)
+	if err != nil {
+		return nil, err
+	}
+	return &Invariants{
+		PSemiflows:   pSemiflows,
+		TSemiflows:   tSemiflows,
+		Conservative: covers(pSemiflows, pn.Places),
+		Consistent:   covers(tSemiflows, pn.Transitions),
+	}, nil
+}
+
+// row is a row of the Farkas tableau: the remaining columns of the matrix, the combination of
+// the original rows that produced it, and the support of that combination as a bit set.
+type row struct {
+	a       []int
+	y       []int
+	support []uint64
+}
+
+// Semiflows returns the minimal-support non-negative integer vectors y with yᵀA = 0, where A has
+// one row per element, using the Farkas algorithm with the Martínez–Silva minimality check.
+// Each semiflow is scaled so its entries have no common divisor. Passing the incidence matrix
+// yields the P-semiflows; passing its transpose yields the T-semiflows.
+func Semiflows(a [][]int, maxRows int) ([][]int, error) {
+	n := len(a)
+	if n == 0 {
+		return nil, nil
+	}
+	m := len(a[0])
+	words := (n + 63) / 64
+
+	rows := make([]*row, n)
+	for i := range a {
+		r := &row{a: append([]int(nil), a[i]...), y: make([]int, n), support: make([]uint64, words)}
+		r.y[i] = 1
+		r.support[i/64] |= 1 << (i % 64)
+		rows[i] = r
+	}
+
+	eliminated := make([]bool, m)
+	for step := 0; step < m; step++ {
+		// Eliminate the column that creates the fewest combinations first.
+		col, best := -1, 0
+		for j := 0; j < m; j++ {
+			if eliminated[j] {
+				continue
+			}
+			pos, neg := 0, 0
+			for _, r := range rows {
+				if r.a[j] > 0 {
+					pos++
+				} else if r.a[j] < 0 {
+					neg++
+				}
+			}
+			if cost := pos*neg - pos - neg; col < 0 || cost < best {
+				col, best = j, cost
+			}
+		}
+		eliminated[col] = true
+
+		var next, pos, neg []*row
+		for _, r := range rows {
+			switch {
+			case r.a[col] > 0:
+				pos = append(pos, r)
+			case r.a[col] < 0:
+				neg = append(neg, r)
+			default:
+				next = append(next, r)
+			}
+		}
+		kept := len(next)
+		for _, p := range pos {
+			for _, q := range neg {
+				support := make([]uint64, words)
+				for w := range support {
+					support[w] = p.support[w] | q.support[w]
+				}
+				// A combination whose support strictly contains that of a kept row is not minimal.
+				if containsSupportOf(support, next[:kept]) {
+					continue
+				}
+				next = append(next, combine(p, q, col, support))
+				if len(next) > maxRows {
+					return nil, ErrTooManyRows
+				}
+			}
+		}
+		rows = minimal(next)
+	}
+
+	semiflows := make([][]int, len(rows))
+	for i, r := range rows {
+		semiflows[i] = r.y
+	}
+	return semiflows, nil
+}
+
+// combine returns the positive combination of p and q that cancels column col, divided by the
+// greatest common divisor of its entries.
+func combine(p, q *row, col int, support []uint64) *row {
+	fp, fq := -q.a[col], p.a[col]
+	r := &row{a: make([]int, len(p.a)), y: make([]int, len(p.y)), support: support}
+	g := 0
+	for j := range r.a {
+		r.a[j] = fp*p.a[j] + fq*q.a[j]
+		g = gcd(g, r.a[j])
+	}
+	for i := range r.y {
+		r.y[i] = fp*p.y[i] + fq*q.y[i]
+		g = gcd(g, r.y[i])
+	}
+	if g > 1 {
+		for j := range r.a {
+			r.a[j] /= g
+		}
+		for i := range r.y {
+			r.y[i] /= g
+		}
+	}
+	return r
+}
+
+// minimal removes the rows whose support strictly contains the support of another row, and the
+// duplicates of earlier rows.
+func minimal(rows []*row) []*row {
+	result := make([]*row, 0, len(rows))
+	for i, r := range rows {
+		dominated := false
+		for j, s := range rows {
+			if i == j || !subset(s.support, r.support) {
+				continue
+			}
+			if !subset(r.support, s.support) || (j < i && slices.Equal(r.y, s.y)) {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// containsSupportOf reports whether support strictly contains the support of one of rows.
+func containsSupportOf(support []uint64, rows []*row) bool {
+	for _, r := range rows {
+		if subset(r.support, support) && !subset(support, r.support) {
+			return true
+		}
+	}
+	return false
+}
+
+// subset reports whether bit set a is a subset of bit set b.
+func subset(a, b []uint64) bool {
+	for w := range a {
+		if a[w]&^b[w] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// covers reports whether the supports of the semiflows cover all n elements.
+func covers(semiflows [][]int, n int) bool {
+	covered := make([]bool, n)
+	for _, s := range semiflows {
+		for i, v := range s {
+			if v != 0 {
+				covered[i] = true
+			}
+		}
+	}
+	for _, c := range covered {
+		if !c {
+			return false
+		}
+	}
+	return true
+}
+
+// transpose returns the transpose of a matrix with cols columns.
+func transpose(a [][]int, cols int) [][]int {
+	t := make([][]int, cols)
+	for j := range t {
+		t[j] = make([]int, len(a))
+		for i := range a {
+			t[j][i] = a[i][j]
+		}
+	}
+	return t
+}
+
+// gcd returns the greatest common divisor of |a| and |b|.
+func gcd(a, b int) int {
+	if a < 0 {
+		a = -a
+	}
+	if b < 0 {
+		b = -b
+	}
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
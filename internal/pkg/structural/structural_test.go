@@ -0,0 +1,178 @@
+package structural
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"spn-benchmark-ds/internal/pkg/generation"
+	"spn-benchmark-ds/internal/pkg/petrinet"
+	"testing"
+)
+
+// newMutexNet returns two processes competing for a mutex. Places: idle1, cs1, idle2, cs2, mutex.
+// Transitions: enter1, exit1, enter2, exit2.
+func newMutexNet() *petrinet.PetriNet {
+	pn := petrinet.NewPetriNet(5, 4)
+	pn.Matrix = []int{
+		1, 0, 0, 0, 0, 1, 0, 0, 1,
+		0, 1, 0, 0, 1, 0, 0, 0, 0,
+		0, 0, 1, 0, 0, 0, 0, 1, 1,
+		0, 0, 0, 1, 0, 0, 1, 0, 0,
+		1, 0, 1, 0, 0, 1, 0, 1, 1,
+	}
+	pn.InitialMarking = []int{1, 0, 1, 0, 1}
+	return pn
+}
+
+// sortedSemiflows returns the semiflows in lexicographic order, so tests do not depend on the
+// order the Farkas algorithm produces them in.
+func sortedSemiflows(semiflows [][]int) [][]int {
+	sorted := slices.Clone(semiflows)
+	slices.SortFunc(sorted, slices.Compare[[]int])
+	return sorted
+}
+
+// supportContains reports whether the support of a contains the support of b.
+func supportContains(a, b []int) bool {
+	for i := range b {
+		if b[i] != 0 && a[i] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestComputeInvariants(t *testing.T) {
+	inv, err := ComputeInvariants(newMutexNet(), 0)
+	if err != nil {
+		t.Fatalf("Error computing invariants: %v", err)
+	}
+
+	expectedP := [][]int{
+		{0, 0, 1, 1, 0},
+		{0, 1, 0, 1, 1},
+		{1, 1, 0, 0, 0},
+	}
+	if got := sortedSemiflows(inv.PSemiflows); !slices.EqualFunc(got, expectedP, slices.Equal[[]int]) {
+		t.Errorf("Expected P-semiflows %v, got %v", expectedP, got)
+	}
+	expectedT := [][]int{
+		{0, 0, 1, 1},
+		{1, 1, 0, 0},
+	}
+	if got := sortedSemiflows(inv.TSemiflows); !slices.EqualFunc(got, expectedT, slices.Equal[[]int]) {
+		t.Errorf("Expected T-semiflows %v, got %v", expectedT, got)
+	}
+	if !inv.Conservative || !inv.Consistent {
+		t.Errorf("Expected the mutex net to be conservative and consistent")
+	}
+}
+
+func TestComputeInvariantsWeightedAndUnbounded(t *testing.T) {
+	// T1 consumes two tokens from P1 and produces one in P2; T2 reverses it.
+	weighted := petrinet.NewPetriNet(2, 2)
+	weighted.Matrix = []int{
+		2, 0, 0, 2, 4,
+		0, 1, 1, 0, 0,
+	}
+	inv, err := ComputeInvariants(weighted, 0)
+	if err != nil {
+		t.Fatalf("Error computing invariants: %v", err)
+	}
+	if !slices.EqualFunc(inv.PSemiflows, [][]int{{1, 2}}, slices.Equal[[]int]) {
+		t.Errorf("Expected the P-semiflow [1 2], got %v", inv.PSemiflows)
+	}
+	if !slices.EqualFunc(inv.TSemiflows, [][]int{{1, 1}}, slices.Equal[[]int]) {
+		t.Errorf("Expected the T-semiflow [1 1], got %v", inv.TSemiflows)
+	}
+
+	// T1 keeps its token in P1 and adds one to P2 on every firing.
+	producer := petrinet.NewPetriNet(2, 1)
+	producer.Matrix = []int{
+		1, 1, 1,
+		0, 1, 0,
+	}
+	inv, err = ComputeInvariants(producer, 0)
+	if err != nil {
+		t.Fatalf("Error computing invariants: %v", err)
+	}
+	if !slices.EqualFunc(inv.PSemiflows, [][]int{{1, 0}}, slices.Equal[[]int]) {
+		t.Errorf("Expected the P-semiflow [1 0], got %v", inv.PSemiflows)
+	}
+	if len(inv.TSemiflows) != 0 || inv.Conservative || inv.Consistent {
+		t.Errorf("Expected an unbounded net that is neither conservative nor consistent, got %+v", inv)
+	}
+}
+
+func TestPSemiflowsHoldOnReachableMarkings(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		pn := petrinet.GenerateRandomPetriNet(rng, 6, 5)
+		pn.Prune(rng)
+		pn.AddTokensRandomly(rng)
+
+		inv, err := ComputeInvariants(pn, 0)
+		if err != nil {
+			t.Fatalf("seed %d: error computing invariants: %v", seed, err)
+		}
+		c := IncidenceMatrix(pn)
+		for _, y := range inv.PSemiflows {
+			for tr := 0; tr < pn.Transitions; tr++ {
+				sum := 0
+				for p := range y {
+					sum += y[p] * c[p][tr]
+				}
+				if sum != 0 {
+					t.Errorf("seed %d: %v is not a P-semiflow", seed, y)
+				}
+			}
+		}
+		for _, x := range inv.TSemiflows {
+			for p := range c {
+				sum := 0
+				for tr := range x {
+					sum += c[p][tr] * x[tr]
+				}
+				if sum != 0 {
+					t.Errorf("seed %d: %v is not a T-semiflow", seed, x)
+				}
+			}
+		}
+
+		for _, flows := range [][][]int{inv.PSemiflows, inv.TSemiflows} {
+			for i, a := range flows {
+				for j, b := range flows {
+					if i != j && supportContains(a, b) {
+						t.Errorf("seed %d: semiflow %v is not minimal, its support contains that of %v", seed, a, b)
+					}
+				}
+			}
+		}
+
+		rg, err := generation.GenerateReachabilityGraph(pn, 10, 500)
+		if err != nil {
+			t.Fatalf("seed %d: error generating reachability graph: %v", seed, err)
+		}
+		for _, y := range inv.PSemiflows {
+			weighted := func(marking []int) int {
+				sum := 0
+				for p, tokens := range marking {
+					sum += y[p] * tokens
+				}
+				return sum
+			}
+			initial := weighted(pn.InitialMarking)
+			for v := 0; v < rg.NumVertices; v++ {
+				if got := weighted(rg.Vertex(v)); got != initial {
+					t.Errorf("seed %d: P-semiflow %v gives %d in marking %v and %d initially", seed, y, got, rg.Vertex(v), initial)
+				}
+			}
+		}
+	}
+}
+
+func TestSemiflowsRowLimit(t *testing.T) {
+	if _, err := ComputeInvariants(newMutexNet(), 1); !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("Expected ErrTooManyRows, got %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+// Package testnets builds small Petri nets shared by the tests of several packages.
+package testnets
+
+import "spn-benchmark-ds/internal/pkg/petrinet"
+
+// ChoiceNet builds a GSPN in which the timed transition T0 moves the token from P0 into P1,
+// where the immediate transitions t1 (weight 1) and t2 (weight 3) compete to move it into P2 or
+// P3. The timed transitions T3 and T4 return it to P0. The token starts in initialPlace.
+func ChoiceNet(initialPlace int) *petrinet.PetriNet {
+	pn := petrinet.NewPetriNet(4, 5)
+	arcs := [][3]int{ // place, transition, direction (0 = input, 1 = output)
+		{0, 0, 0}, {1, 0, 1},
+		{1, 1, 0}, {2, 1, 1},
+		{1, 2, 0}, {3, 2, 1},
+		{2, 3, 0}, {0, 3, 1},
+		{3, 4, 0}, {0, 4, 1},
+	}
+	for _, a := range arcs {
+		pn.Set(a[0], a[1]+a[2]*pn.Transitions, 1)
+	}
+	pn.Set(initialPlace, 2*pn.Transitions, 1)
+	pn.InitialMarking[initialPlace] = 1
+	pn.SetImmediate(1, 1, 1)
+	pn.SetImmediate(2, 3, 1)
+	return pn
+}
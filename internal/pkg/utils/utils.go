@@ -2,31 +2,97 @@ package utils
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
 	"path/filepath"
 )
 
-// LoadJSONLFile loads data from a JSONL file.
-func LoadJSONLFile(path string) ([][]byte, error) {
+// JSONLReader reads the records of a JSONL stream one line at a time. Unlike bufio.Scanner it
+// has no limit on the length of a line, so records holding large reachability graphs are read
+// whole. Blank lines are skipped and a trailing carriage return is removed.
+type JSONLReader struct {
+	r    *bufio.Reader
+	buf  []byte
+	line int
+}
+
+// NewJSONLReader returns a reader of the JSONL records of r.
+func NewJSONLReader(r io.Reader) *JSONLReader {
+	return &JSONLReader{r: bufio.NewReaderSize(r, 64*1024)}
+}
+
+// Next returns the next record, or io.EOF after the last one. The record is only valid until the
+// next call to Next.
+func (r *JSONLReader) Next() ([]byte, error) {
+	for {
+		r.buf = r.buf[:0]
+		for {
+			chunk, err := r.r.ReadSlice('\n')
+			r.buf = append(r.buf, chunk...)
+			if err == bufio.ErrBufferFull {
+				continue
+			}
+			if err == io.EOF && len(r.buf) > 0 {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+		r.line++
+		record := bytes.TrimRight(r.buf, "\r\n")
+		if len(bytes.TrimSpace(record)) > 0 {
+			return record, nil
+		}
+	}
+}
+
+// Line returns the line number of the record last returned by Next, starting at one.
+func (r *JSONLReader) Line() int {
+	return r.line
+}
+
+// ForEachJSONLRecord calls fn on every record of a JSONL file, in order, without loading the file
+// into memory. The record passed to fn is only valid during the call. It stops at the first error
+// fn returns, annotated with the line number.
+func ForEachJSONLRecord(path string, fn func(record []byte) error) error {
 	file, err := os.Open(filepath.Clean(path))
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+		return fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
-	var data [][]byte
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		data = append(data, scanner.Bytes())
+	reader := NewJSONLReader(file)
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read line %d: %w", reader.Line()+1, err)
+		}
+		if err := fn(record); err != nil {
+			return fmt.Errorf("line %d: %w", reader.Line(), err)
+		}
 	}
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("scanner error: %w", err)
+// LoadJSONLFile loads all records of a JSONL file into memory.
+// Use ForEachJSONLRecord to process large files record by record.
+func LoadJSONLFile(path string) ([][]byte, error) {
+	var data [][]byte
+	err := ForEachJSONLRecord(path, func(record []byte) error {
+		data = append(data, append([]byte(nil), record...))
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
 	return data, nil
 }
 
@@ -54,13 +120,14 @@ func LoadJSONFile(path string) ([]byte, error) {
 }
 
 // SampleJSONFilesFromDirectory samples JSON files from a directory.
-func SampleJSONFilesFromDirectory(n int, dir string) ([][]byte, error) {
+// The directory listing is sorted by name, so the same rng state always selects the same files.
+func SampleJSONFilesFromDirectory(rng *rand.Rand, n int, dir string) ([][]byte, error) {
 	files, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read directory: %w", err)
 	}
 
-	rand.Shuffle(len(files), func(i, j int) {
+	rng.Shuffle(len(files), func(i, j int) {
 		files[i], files[j] = files[j], files[i]
 	})
 
@@ -75,3 +142,29 @@ func SampleJSONFilesFromDirectory(n int, dir string) ([][]byte, error) {
 
 	return sampledFiles, nil
 }
+
+// DeriveSeed mixes a base seed with a sequence of keys into a new seed.
+// Each key is folded in with a SplitMix64 finalizer, so nearby keys (e.g. consecutive
+// sample indices) yield statistically independent seeds.
+func DeriveSeed(seed int64, keys ...int64) int64 {
+	h := splitMix64(uint64(seed))
+	for _, k := range keys {
+		h = splitMix64(h ^ splitMix64(uint64(k)))
+	}
+	return int64(h)
+}
+
+// NewRand returns a random source whose stream is fully determined by seed and keys.
+// It is used to give every sample its own stream, so a sample can be regenerated in
+// isolation and results do not depend on the order in which samples are processed.
+func NewRand(seed int64, keys ...int64) *rand.Rand {
+	return rand.New(rand.NewSource(DeriveSeed(seed, keys...)))
+}
+
+// splitMix64 is the SplitMix64 output function.
+func splitMix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
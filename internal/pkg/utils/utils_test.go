@@ -2,9 +2,13 @@ package utils
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -109,6 +113,68 @@ func TestLoadAndSaveJSONLFile(t *testing.T) {
 	}
 }
 
+func TestJSONLReaderLongLines(t *testing.T) {
+	// A record far beyond the 64 KiB token limit of bufio.Scanner, blank lines, CRLF line endings
+	// and a missing trailing newline.
+	long := `{"data":"` + strings.Repeat("x", 1<<20) + `"}`
+	input := long + "\n\n" + `{"a":1}` + "\r\n  \n" + `{"b":2}`
+	reader := NewJSONLReader(strings.NewReader(input))
+	var records []string
+	var lines []int
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		records = append(records, string(record))
+		lines = append(lines, reader.Line())
+	}
+	if len(records) != 3 || records[0] != long || records[1] != `{"a":1}` || records[2] != `{"b":2}` {
+		t.Fatalf("expected the three records, got %d records", len(records))
+	}
+	if lines[0] != 1 || lines[1] != 3 || lines[2] != 5 {
+		t.Errorf("expected records on lines 1, 3 and 5, got %v", lines)
+	}
+}
+
+func TestForEachJSONLRecord(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "data.jsonl")
+	long := `{"data":"` + strings.Repeat("y", 200000) + `"}`
+	if err := os.WriteFile(filePath, []byte(`{"a":1}`+"\n"+long+"\n"+`{"c":3}`+"\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	// LoadJSONLFile must copy the records out of the reader's buffer.
+	loadedData, err := LoadJSONLFile(filePath)
+	if err != nil {
+		t.Fatalf("LoadJSONLFile failed: %v", err)
+	}
+	if len(loadedData) != 3 || string(loadedData[0]) != `{"a":1}` || string(loadedData[1]) != long || string(loadedData[2]) != `{"c":3}` {
+		t.Fatalf("loaded data is not the same as the original data")
+	}
+
+	// Errors of the callback stop the iteration and carry the line number.
+	count := 0
+	err = ForEachJSONLRecord(filePath, func(record []byte) error {
+		count++
+		if count == 2 {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	if err == nil || count != 2 || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected the iteration to stop at line 2, got %v after %d records", err, count)
+	}
+
+	if err := ForEachJSONLRecord(filepath.Join(tmpDir, "missing.jsonl"), func([]byte) error { return nil }); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
 func TestSampleJSONFilesFromDirectory(t *testing.T) {
 	// Create a temporary directory
 	tmpDir, err := os.MkdirTemp("", "test_sample")
@@ -129,7 +195,7 @@ func TestSampleJSONFilesFromDirectory(t *testing.T) {
 	}
 
 	// Sample 3 files from the directory
-	sampledFiles, err := SampleJSONFilesFromDirectory(3, tmpDir)
+	sampledFiles, err := SampleJSONFilesFromDirectory(rand.New(rand.NewSource(1)), 3, tmpDir)
 	if err != nil {
 		t.Fatalf("SampleJSONFilesFromDirectory failed: %v", err)
 	}
@@ -139,3 +205,23 @@ func TestSampleJSONFilesFromDirectory(t *testing.T) {
 		t.Errorf("expected 3 files, got %d", len(sampledFiles))
 	}
 }
+
+func TestNewRand(t *testing.T) {
+	a := NewRand(7, 3)
+	b := NewRand(7, 3)
+	for i := 0; i < 10; i++ {
+		if x, y := a.Int63(), b.Int63(); x != y {
+			t.Fatalf("expected identical streams for identical keys, got %d and %d at draw %d", x, y, i)
+		}
+	}
+
+	if DeriveSeed(7, 3) == DeriveSeed(7, 4) {
+		t.Errorf("expected different seeds for different keys")
+	}
+	if DeriveSeed(7, 3) == DeriveSeed(8, 3) {
+		t.Errorf("expected different seeds for different base seeds")
+	}
+	if DeriveSeed(7, 1) == DeriveSeed(7, 1, 0) {
+		t.Errorf("expected key sequences of different length to yield different seeds")
+	}
+}